@@ -0,0 +1,195 @@
+package purplecat
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// bundlerParser is the instance of Parser for parsing Gemfile.lock of Bundler.
+type bundlerParser struct {
+	context *Context
+}
+
+// gemSource represents the source section (GEM, GIT, or PATH) in Gemfile.lock.
+type gemSource struct {
+	kind   string
+	remote string
+}
+
+// gemSpec represents the gem in the specs of Gemfile.lock.
+type gemSpec struct {
+	name    string
+	version string
+	// platform is the platform of the gem with the native extensions (e.g., `x86_64-linux`), and empty for the pure ruby gems.
+	platform string
+	source   *gemSource
+	// requires is the names of the dependencies, which are the lines indented under the spec.
+	requires     []string
+	dependencies []*gemSpec
+}
+
+func (spec *gemSpec) Name() string {
+	if spec.version == "" {
+		return spec.name
+	}
+	return spec.name + "@" + spec.version
+}
+
+// gemfileLock represents Gemfile.lock, dependencies is the names in DEPENDENCIES section, that is, the gems in Gemfile.
+type gemfileLock struct {
+	specs        map[string]*gemSpec
+	dependencies []string
+}
+
+// IsTarget returns true if the project located on the given path is Bundler project.
+func (bp *bundlerParser) IsTarget(path *Path, context *Context) bool {
+	if path.Base() == "Gemfile.lock" {
+		return path.Exists(context)
+	}
+	return path.Join("Gemfile.lock").Exists(context)
+}
+
+// Parse parses the given path as Gemfile.lock and returns the instance of Project.
+func (bp *bundlerParser) Parse(path *Path) (*Project, error) {
+	if path.Base() != "Gemfile.lock" {
+		path = path.Join("Gemfile.lock")
+	}
+	if bp.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", bp.context.Depth, 0)
+	}
+	logger.Infof("parseGemfileLock(%s)", path.Path)
+	content, err := readText(path, bp.context)
+	if err != nil {
+		return nil, err
+	}
+	lock := parseGemfileLock(content)
+	dir := path.Dir()
+	root := &gemSpec{name: dir.Base(), source: &gemSource{kind: "PATH", remote: "."}, dependencies: lock.resolve(dir.Base(), lock.dependencies)}
+	for _, spec := range lock.specs {
+		spec.dependencies = lock.resolve(spec.Name(), spec.requires)
+	}
+	tree := newGemTree(dir, bp.context)
+	return tree.constructProject(root, 0)
+}
+
+// parseGemfileLock parses Gemfile.lock. The specs are indented by 4 spaces in the source sections,
+// and their dependencies are indented by 6 spaces under them.
+func parseGemfileLock(content string) *gemfileLock {
+	lock := &gemfileLock{specs: map[string]*gemSpec{}, dependencies: []string{}}
+	section := ""
+	var source *gemSource
+	var spec *gemSpec
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case indent == 0:
+			section, source, spec = trimmed, nil, nil
+			if section == "GEM" || section == "GIT" || section == "PATH" || section == "PLUGIN SOURCE" {
+				source = &gemSource{kind: section}
+			}
+		case source != nil && indent == 2 && strings.HasPrefix(trimmed, "remote:"):
+			source.remote = strings.TrimSpace(strings.TrimPrefix(trimmed, "remote:"))
+		case source != nil && indent == 4:
+			spec = parseGemSpecLine(trimmed)
+			spec.source = source
+			// the gems with the native extensions have the specs for each platform, and the one of ruby platform is preferred.
+			if found, ok := lock.specs[spec.name]; !ok || (found.platform != "" && spec.platform == "") {
+				lock.specs[spec.name] = spec
+			}
+		case source != nil && indent == 6 && spec != nil:
+			spec.requires = append(spec.requires, strings.Fields(trimmed)[0])
+		case section == "DEPENDENCIES" && indent == 2:
+			lock.dependencies = append(lock.dependencies, strings.TrimSuffix(strings.Fields(trimmed)[0], "!"))
+		}
+	}
+	return lock
+}
+
+// parseGemSpecLine parses the spec line (e.g., `nokogiri (1.12.5-x86_64-linux)`) into the name, the version, and the platform.
+func parseGemSpecLine(line string) *gemSpec {
+	spec := &gemSpec{requires: []string{}}
+	items := strings.Fields(line)
+	spec.name = items[0]
+	if len(items) > 1 {
+		version := strings.TrimSuffix(strings.TrimPrefix(items[1], "("), ")")
+		if index := strings.Index(version, "-"); index >= 0 {
+			version, spec.platform = version[:index], version[index+1:]
+		}
+		spec.version = version
+	}
+	return spec
+}
+
+func (lock *gemfileLock) resolve(from string, names []string) []*gemSpec {
+	dependencies := []*gemSpec{}
+	for _, name := range names {
+		if spec, ok := lock.specs[name]; ok {
+			dependencies = append(dependencies, spec)
+		} else {
+			// the default gems (e.g., bundler itself) do not appear in the specs.
+			logger.Debugf("%s: dependency %s not found in Gemfile.lock", from, name)
+		}
+	}
+	return dependencies
+}
+
+type gemTree struct {
+	dir     *Path
+	context *Context
+	gemDirs []string
+}
+
+func newGemTree(dir *Path, context *Context) *gemTree {
+	return &gemTree{dir: dir, context: context, gemDirs: findGemDirs(dir)}
+}
+
+func (tree *gemTree) constructProject(spec *gemSpec, currentDepth int) (*Project, error) {
+	if tree.context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", tree.context.Depth, currentDepth)
+	}
+	logger.Infof("constructGemProject(%s, %d)", spec.Name(), currentDepth)
+	project := tree.context.NewProject(spec.Name(), tree.findLicenses(spec))
+	for _, dependency := range spec.dependencies {
+		project.Deps = append(project.Deps, dependency.Name())
+	}
+	for _, dependency := range spec.dependencies {
+		if _, ok := tree.context.SearchCache(dependency.Name()); ok {
+			continue
+		}
+		tree.constructProject(dependency, currentDepth+1)
+	}
+	return project, nil
+}
+
+// findLicenses finds the licenses of the given gem from the gemspec in the local path (for PATH sources),
+// the installed gemspec, and the RubyGems API in this order.
+func (tree *gemTree) findLicenses(spec *gemSpec) Licenses {
+	if spec.source.kind == "PATH" {
+		dir := tree.dir.Join(spec.source.remote)
+		if licenses, ok := findLicensesInGemspecs(dir, "*.gemspec", tree.context); ok {
+			return licenses
+		}
+		return findLicensesInDir(dir, tree.context)
+	}
+	if licenses, ok := findInstalledGemLicenses(spec, tree.gemDirs, tree.context); ok {
+		return licenses
+	}
+	if spec.source.kind != "GEM" {
+		return Licenses{}
+	}
+	licenses, err := findGemLicensesViaAPI(spec, tree.context)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+		return Licenses{}
+	}
+	return licenses
+}
@@ -0,0 +1,96 @@
+package purplecat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseBundler(t *testing.T) {
+	testdata := []struct {
+		path     string
+		wontName string
+		wontDeps []string
+	}{
+		{"testdata/bundlerproject", "bundlerproject", []string{"bundler4test@0.1.0", "gitgem@0.3.0", "minitest@5.14.4", "nokogiri@1.12.5"}},
+		{"testdata/bundlerproject/Gemfile.lock", "bundlerproject", []string{"bundler4test@0.1.0", "gitgem@0.3.0", "minitest@5.14.4", "nokogiri@1.12.5"}},
+	}
+	for _, td := range testdata {
+		parser := &bundlerParser{context: NewContext(true, "json", 2)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		validateResolvedTree(t, tree, td.wontName, []string{"Apache-2.0"}, td.wontDeps)
+	}
+}
+
+func TestParseBundlerInstalledLicenses(t *testing.T) {
+	parser := &bundlerParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/bundlerproject"))
+	if err != nil {
+		t.Errorf("testdata/bundlerproject: parse failed: %s", err.Error())
+		return
+	}
+	deps := tree.Dependencies()
+	validateResolvedTree(t, deps[0], "bundler4test@0.1.0", []string{"Apache-2.0"}, []string{"rack@2.2.3"})
+	validateResolvedTree(t, deps[1], "gitgem@0.3.0", []string{}, []string{})
+	validateResolvedTree(t, deps[2], "minitest@5.14.4", []string{}, []string{})
+	validateResolvedTree(t, deps[3], "nokogiri@1.12.5", []string{"MIT"}, []string{"mini_portile2@2.6.1", "racc@1.5.2"})
+	validateResolvedTree(t, deps[0].Dependencies()[0], "rack@2.2.3", []string{"MIT"}, []string{})
+	validateResolvedTree(t, deps[3].Dependencies()[1], "racc@1.5.2", []string{"BSD-2-Clause"}, []string{})
+}
+
+func TestParseBundlerViaAPI(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata/rubygems")))
+	defer server.Close()
+	defer setEnv(map[string]string{RubyGemsEnvName: server.URL})()
+
+	parser := &bundlerParser{context: NewContext(false, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/bundlerproject"))
+	if err != nil {
+		t.Errorf("testdata/bundlerproject: parse failed: %s", err.Error())
+		return
+	}
+	deps := tree.Dependencies()
+	validateResolvedTree(t, deps[1], "gitgem@0.3.0", []string{}, []string{})
+	validateResolvedTree(t, deps[2], "minitest@5.14.4", []string{"MIT"}, []string{})
+	validateResolvedTree(t, deps[3].Dependencies()[0], "mini_portile2@2.6.1", []string{"MIT"}, []string{})
+}
+
+func TestParseBundlerOverDepth(t *testing.T) {
+	parser := &bundlerParser{context: NewContext(true, "json", -1)}
+	if _, err := parser.Parse(NewPath("testdata/bundlerproject")); err == nil {
+		t.Errorf("Parse with depth -1 wont error, but got nil")
+	}
+}
+
+func TestParseGemfileLock(t *testing.T) {
+	lock := parseGemfileLock(`GEM
+  remote: https://rubygems.org/
+  specs:
+    nokogiri (1.12.5-x86_64-linux)
+      racc (~> 1.4)
+    nokogiri (1.12.5)
+      mini_portile2 (~> 2.6.1)
+      racc (~> 1.4)
+
+DEPENDENCIES
+  nokogiri!
+`)
+	nokogiri, ok := lock.specs["nokogiri"]
+	if !ok {
+		t.Errorf("nokogiri not found in the specs")
+		return
+	}
+	if nokogiri.Name() != "nokogiri@1.12.5" || nokogiri.platform != "" || nokogiri.source.remote != "https://rubygems.org/" {
+		t.Errorf("nokogiri did not match, wont (nokogiri@1.12.5, ruby, https://rubygems.org/), got (%s, %s, %s)", nokogiri.Name(), nokogiri.platform, nokogiri.source.remote)
+	}
+	if len(nokogiri.requires) != 2 || nokogiri.requires[0] != "mini_portile2" {
+		t.Errorf("requires of nokogiri did not match, wont [mini_portile2 racc], got %v", nokogiri.requires)
+	}
+	if len(lock.dependencies) != 1 || lock.dependencies[0] != "nokogiri" {
+		t.Errorf("dependencies did not match, wont [nokogiri], got %v", lock.dependencies)
+	}
+}
@@ -269,3 +269,63 @@ func (ddb *defaultCacheDB) Dump(writer io.Writer) error {
 	}
 	return nil
 }
+
+// overlayCacheDB is the cache database of the projects resolved in a run (e.g., the dependency graph of a pom), which are found
+// before the projects of the base database. The projects registered in it are not stored into the base database,
+// since their dependencies and attributes are specific to the run.
+type overlayCacheDB struct {
+	base     CacheDB
+	projects map[string]*Project
+}
+
+func newOverlayCacheDB(base CacheDB) *overlayCacheDB {
+	return &overlayCacheDB{base: base, projects: map[string]*Project{}}
+}
+
+func (ocdb *overlayCacheDB) Type() CacheType {
+	return MemoryCache
+}
+
+func (ocdb *overlayCacheDB) Find(projectName string) (*Project, bool) {
+	if project, ok := ocdb.projects[projectName]; ok {
+		return project, true
+	}
+	if ocdb.base == nil {
+		return nil, false
+	}
+	return ocdb.base.Find(projectName)
+}
+
+// findInOverlay finds the project registered in the receiver database, without the base database.
+func (ocdb *overlayCacheDB) findInOverlay(projectName string) (*Project, bool) {
+	project, ok := ocdb.projects[projectName]
+	return project, ok
+}
+
+func (ocdb *overlayCacheDB) Delete(projectName string) (*Project, bool) {
+	project, ok := ocdb.projects[projectName]
+	delete(ocdb.projects, projectName)
+	return project, ok
+}
+
+func (ocdb *overlayCacheDB) Register(project *Project) bool {
+	if project != nil {
+		ocdb.projects[project.Name()] = project
+	}
+	return project != nil
+}
+
+func (ocdb *overlayCacheDB) Store() error {
+	logger.Debug("overlayCacheDB does not support Store")
+	return nil
+}
+
+func (ocdb *overlayCacheDB) Dump(writer io.Writer) error {
+	logger.Debug("overlayCacheDB does not support Dump")
+	return nil
+}
+
+func (ocdb *overlayCacheDB) Clear() error {
+	ocdb.projects = map[string]*Project{}
+	return nil
+}
@@ -0,0 +1,371 @@
+package purplecat
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// cargoParser is the instance of Parser for parsing Cargo.lock (or Cargo.toml) of Cargo.
+type cargoParser struct {
+	context *Context
+}
+
+// cargoPackage represents the crate in the dependency graph.
+type cargoPackage struct {
+	name    string
+	version string
+	// source is the source of the crate in Cargo.lock (e.g., `registry+https://github.com/rust-lang/crates.io-index`),
+	// and empty for the local crates.
+	source string
+	// dir is the location of the local crate (the project itself, the workspace members, and the path dependencies).
+	dir *Path
+	// dependencies is resolved from Cargo.lock; nil shows the dependencies are resolved from the manifests, or the index.
+	dependencies []*cargoPackage
+}
+
+func (pkg *cargoPackage) Name() string {
+	if pkg.version == "" {
+		return pkg.name
+	}
+	return pkg.name + "@" + pkg.version
+}
+
+// cargoManifest represents Cargo.toml.
+type cargoManifest struct {
+	name        string
+	version     string
+	license     string
+	licenseFile string
+	requires    []*cargoRequirement
+	// members is the workspace members declared in `[workspace]`.
+	members []string
+}
+
+// cargoRequirement is the dependency declared in Cargo.toml, or in the index.
+type cargoRequirement struct {
+	// name is the package name of the crate, not the renamed one.
+	name string
+	req  string
+	path string
+}
+
+func isCargoTargetFile(name string) bool {
+	return name == "Cargo.toml" || name == "Cargo.lock"
+}
+
+// IsTarget returns true if the project located on the given path is Cargo project.
+func (cp *cargoParser) IsTarget(path *Path, context *Context) bool {
+	if isCargoTargetFile(path.Base()) {
+		return path.Exists(context)
+	}
+	return path.Join("Cargo.toml").Exists(context) || path.Join("Cargo.lock").Exists(context)
+}
+
+// Parse parses the given path as Cargo.lock (or Cargo.toml) and returns the instance of Project.
+// If Cargo.lock does not exist, the versions of the dependencies are resolved from the local registry and the index.
+func (cp *cargoParser) Parse(path *Path) (*Project, error) {
+	dir := path
+	if isCargoTargetFile(path.Base()) {
+		dir = path.Dir()
+	}
+	if cp.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", cp.context.Depth, 0)
+	}
+	logger.Infof("parseCargoProject(%s)", dir.Path)
+	tree := newCargoTree(cp.context)
+	manifest, err := readCargoManifest(dir.Join("Cargo.toml"), cp.context, true)
+	if err != nil && !dir.Join("Cargo.lock").Exists(cp.context) {
+		return nil, err
+	}
+	root := &cargoPackage{name: dir.Base(), dir: dir}
+	if manifest != nil {
+		tree.registerLocalCrates(dir, manifest)
+		if manifest.name != "" {
+			root.name, root.version = manifest.name, manifest.version
+		} else {
+			// the virtual manifest of the workspace depends on its members.
+			root.dependencies = tree.workspaceMembers(dir, manifest)
+		}
+	}
+	if lockPath := dir.Join("Cargo.lock"); lockPath.Exists(cp.context) {
+		lock, err := readCargoLock(lockPath, cp.context)
+		if err != nil {
+			return nil, err
+		}
+		root = lock.rootPackage(root, manifest != nil && manifest.name != "")
+	}
+	return tree.constructProject(root, 0)
+}
+
+// readCargoManifest reads Cargo.toml. The dev-dependencies are read only for the root crate.
+func readCargoManifest(path *Path, context *Context, root bool) (*cargoManifest, error) {
+	tree, err := loadToml(path, context)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &cargoManifest{requires: []*cargoRequirement{}, members: []string{}}
+	if pkg, ok := tree.Get("package").(*toml.Tree); ok {
+		// the fields inherited from the workspace (e.g., `license.workspace = true`) are tables, and they are ignored.
+		manifest.name, _ = pkg.Get("name").(string)
+		manifest.version, _ = pkg.Get("version").(string)
+		manifest.license, _ = pkg.Get("license").(string)
+		manifest.licenseFile, _ = pkg.Get("license-file").(string)
+	}
+	if workspace, ok := tree.Get("workspace").(*toml.Tree); ok {
+		manifest.members = toStringSlice(workspace.Get("members"))
+	}
+	kinds := []string{"dependencies", "build-dependencies"}
+	if root {
+		kinds = append(kinds, "dev-dependencies")
+	}
+	tables := []*toml.Tree{tree}
+	if targets, ok := tree.Get("target").(*toml.Tree); ok {
+		for _, key := range sortedTomlKeys(targets) {
+			if target, ok := targets.GetPath([]string{key}).(*toml.Tree); ok {
+				tables = append(tables, target)
+			}
+		}
+	}
+	for _, table := range tables {
+		for _, kind := range kinds {
+			manifest.requires = append(manifest.requires, cargoRequirements(table.GetPath([]string{kind}))...)
+		}
+	}
+	return manifest, nil
+}
+
+// cargoRequirements returns the requirements in the dependency table, whose values are the version requirements,
+// or the tables with `version`, `path`, and `package` (the renamed dependencies). The optional dependencies are ignored.
+func cargoRequirements(value interface{}) []*cargoRequirement {
+	requires := []*cargoRequirement{}
+	table, ok := value.(*toml.Tree)
+	if !ok {
+		return requires
+	}
+	for _, name := range sortedTomlKeys(table) {
+		requirement := &cargoRequirement{name: name}
+		switch item := table.GetPath([]string{name}).(type) {
+		case string:
+			requirement.req = item
+		case *toml.Tree:
+			if optional, _ := item.Get("optional").(bool); optional {
+				continue
+			}
+			requirement.req, _ = item.Get("version").(string)
+			requirement.path, _ = item.Get("path").(string)
+			if pkg, ok := item.Get("package").(string); ok {
+				requirement.name = pkg
+			}
+		}
+		requires = append(requires, requirement)
+	}
+	return requires
+}
+
+// cargoVersionRange converts the version requirement of Cargo into the range of npm; the bare versions are caret requirements
+// in Cargo (`1.2` means `^1.2`), and the comparators are separated by comma.
+func cargoVersionRange(req string) string {
+	comparators := []string{}
+	for _, item := range strings.Split(req, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" && item[0] >= '0' && item[0] <= '9' {
+			item = "^" + item
+		}
+		comparators = append(comparators, item)
+	}
+	return strings.Join(comparators, " ")
+}
+
+type cargoTree struct {
+	context *Context
+	// locals is the local crates keyed by the crate names, which are the workspace members, and the path dependencies.
+	locals  map[string]*Path
+	located map[string]*cargoPackage
+	index   map[string][]*cargoIndexEntry
+	// api is the url of the web API of the registry, which is read from the index.
+	api string
+}
+
+func newCargoTree(context *Context) *cargoTree {
+	return &cargoTree{context: context, locals: map[string]*Path{}, located: map[string]*cargoPackage{}, index: map[string][]*cargoIndexEntry{}}
+}
+
+// registerLocalCrates registers the workspace members and the path dependencies of the given manifest recursively.
+func (tree *cargoTree) registerLocalCrates(dir *Path, manifest *cargoManifest) {
+	dirs := []*Path{}
+	for _, member := range manifest.members {
+		matches, _ := filepath.Glob(filepath.Join(dir.Path, member))
+		for _, match := range matches {
+			dirs = append(dirs, NewPath(match))
+		}
+	}
+	for _, requirement := range manifest.requires {
+		if requirement.path != "" {
+			dirs = append(dirs, dir.Join(requirement.path))
+		}
+	}
+	for _, local := range dirs {
+		localManifest, err := readCargoManifest(local.Join("Cargo.toml"), tree.context, false)
+		if err != nil || localManifest.name == "" {
+			continue
+		}
+		if _, ok := tree.locals[localManifest.name]; ok {
+			continue
+		}
+		tree.locals[localManifest.name] = local
+		tree.registerLocalCrates(local, localManifest)
+	}
+}
+
+func (tree *cargoTree) workspaceMembers(dir *Path, manifest *cargoManifest) []*cargoPackage {
+	members := []*cargoPackage{}
+	for _, member := range manifest.members {
+		matches, _ := filepath.Glob(filepath.Join(dir.Path, member))
+		for _, match := range matches {
+			if memberManifest, err := readCargoManifest(NewPath(match).Join("Cargo.toml"), tree.context, false); err == nil && memberManifest.name != "" {
+				members = append(members, tree.packageOf(&cargoPackage{name: memberManifest.name, version: memberManifest.version, dir: NewPath(match)}))
+			}
+		}
+	}
+	return members
+}
+
+// manifestOf returns Cargo.toml of the given crate from the local crates, or the local registry.
+func (tree *cargoTree) manifestOf(pkg *cargoPackage, root bool) (*cargoManifest, *Path, bool) {
+	dir := pkg.dir
+	if dir == nil && pkg.source == "" {
+		dir = tree.locals[pkg.name]
+	}
+	if dir == nil {
+		dir = findCargoRegistrySrc(pkg.name, pkg.version)
+	}
+	if dir == nil {
+		return nil, nil, false
+	}
+	manifest, err := readCargoManifest(dir.Join("Cargo.toml"), tree.context, root)
+	if err != nil {
+		return nil, nil, false
+	}
+	return manifest, dir, true
+}
+
+func (tree *cargoTree) dependencies(pkg *cargoPackage, remote bool, root bool) []*cargoPackage {
+	if pkg.dependencies != nil {
+		return pkg.dependencies
+	}
+	requires := []*cargoRequirement{}
+	var base *Path
+	if manifest, dir, ok := tree.manifestOf(pkg, root); ok {
+		requires, base = manifest.requires, dir
+	} else if entry, ok := tree.findIndexEntry(pkg.name, pkg.version, remote); ok {
+		requires = entry.requirements()
+	}
+	dependencies := []*cargoPackage{}
+	for _, requirement := range requires {
+		if dependency, ok := tree.resolve(requirement, base, remote); ok {
+			dependencies = append(dependencies, dependency)
+		} else {
+			logger.Debugf("%s: dependency %s %s not resolved", pkg.Name(), requirement.name, requirement.req)
+		}
+	}
+	return dependencies
+}
+
+// resolve finds the crate of the given requirement from the local crates, the local registry, and the index in this order.
+// The index is not accessed unless remote is true.
+func (tree *cargoTree) resolve(requirement *cargoRequirement, base *Path, remote bool) (*cargoPackage, bool) {
+	if requirement.path != "" && base != nil {
+		dir := base.Join(requirement.path)
+		manifest, err := readCargoManifest(dir.Join("Cargo.toml"), tree.context, false)
+		if err != nil {
+			return nil, false
+		}
+		return tree.packageOf(&cargoPackage{name: manifest.name, version: manifest.version, dir: dir}), true
+	}
+	versionRange := cargoVersionRange(requirement.req)
+	if version := maxSatisfyingNpmVersion(localCargoRegistryVersions(requirement.name), versionRange); version != "" {
+		return tree.packageOf(&cargoPackage{name: requirement.name, version: version, source: cargoRegistrySource}), true
+	}
+	if !remote {
+		return nil, false
+	}
+	entries, err := tree.readIndex(requirement.name)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+		return nil, false
+	}
+	versions := []string{}
+	for _, entry := range entries {
+		if !entry.Yanked {
+			versions = append(versions, entry.Vers)
+		}
+	}
+	if version := maxSatisfyingNpmVersion(versions, versionRange); version != "" {
+		return tree.packageOf(&cargoPackage{name: requirement.name, version: version, source: cargoRegistrySource}), true
+	}
+	return nil, false
+}
+
+func (tree *cargoTree) packageOf(pkg *cargoPackage) *cargoPackage {
+	if found, ok := tree.located[pkg.Name()]; ok {
+		return found
+	}
+	tree.located[pkg.Name()] = pkg
+	return pkg
+}
+
+// findLicenses finds the licenses of the given crate from Cargo.toml of the local crate (or the local registry),
+// and the registry in this order.
+func (tree *cargoTree) findLicenses(pkg *cargoPackage) Licenses {
+	if manifest, dir, ok := tree.manifestOf(pkg, false); ok {
+		if manifest.license != "" {
+			return parseSpdxExpression(manifest.license)
+		}
+		if manifest.licenseFile != "" {
+			license, _ := readLicense(dir.Join(manifest.licenseFile), tree.context)
+			return Licenses{license}
+		}
+		return findLicensesInDir(dir, tree.context)
+	}
+	if !strings.HasPrefix(pkg.source, "registry+") && !strings.HasPrefix(pkg.source, "sparse+") {
+		return Licenses{}
+	}
+	license, err := tree.findLicenseViaRegistry(pkg.name, pkg.version)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+		return Licenses{}
+	}
+	return parseSpdxExpression(license)
+}
+
+func (tree *cargoTree) constructProject(pkg *cargoPackage, currentDepth int) (*Project, error) {
+	if tree.context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", tree.context.Depth, currentDepth)
+	}
+	logger.Infof("constructCargoProject(%s, %d)", pkg.Name(), currentDepth)
+	project := tree.context.NewProject(pkg.Name(), tree.findLicenses(pkg))
+	// the versions of the dependencies at the depth limit are resolved without accessing the index.
+	dependencies := tree.dependencies(pkg, currentDepth < tree.context.Depth, currentDepth == 0)
+	for _, dependency := range dependencies {
+		project.Deps = append(project.Deps, dependency.Name())
+	}
+	for _, dependency := range dependencies {
+		if _, ok := tree.context.SearchCache(dependency.Name()); ok {
+			continue
+		}
+		tree.constructProject(dependency, currentDepth+1)
+	}
+	return project, nil
+}
+
+func sortedCargoPackages(packages []*cargoPackage) []*cargoPackage {
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name() < packages[j].Name()
+	})
+	return packages
+}
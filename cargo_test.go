@@ -0,0 +1,103 @@
+package purplecat
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseCargo(t *testing.T) {
+	defer setEnv(map[string]string{"CARGO_HOME": "testdata/cargohome"})()
+	testdata := []struct {
+		path        string
+		wontName    string
+		wontSpdxIDs []string
+		wontDeps    []string
+	}{
+		{"testdata/cargoproject", "cargo4test@0.1.0", []string{"MIT"}, []string{"itoa@0.4.8", "log@0.4.14", "mylib@0.2.0", "serde@1.0.130"}},
+		{"testdata/cargoproject/Cargo.lock", "cargo4test@0.1.0", []string{"MIT"}, []string{"itoa@0.4.8", "log@0.4.14", "mylib@0.2.0", "serde@1.0.130"}},
+		{"testdata/cargoproject/Cargo.toml", "cargo4test@0.1.0", []string{"MIT"}, []string{"itoa@0.4.8", "log@0.4.14", "mylib@0.2.0", "serde@1.0.130"}},
+		{"testdata/cargonolockproject", "cargonolock4test@0.1.0", []string{}, []string{"log@0.4.14", "cfg-if@1.0.0"}},
+	}
+	for _, td := range testdata {
+		parser := &cargoParser{context: NewContext(true, "json", 2)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		validateResolvedTree(t, tree, td.wontName, td.wontSpdxIDs, td.wontDeps)
+	}
+}
+
+func TestParseCargoLicenses(t *testing.T) {
+	defer setEnv(map[string]string{"CARGO_HOME": "testdata/cargohome"})()
+	parser := &cargoParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/cargoproject"))
+	if err != nil {
+		t.Errorf("testdata/cargoproject: parse failed: %s", err.Error())
+		return
+	}
+	deps := tree.Dependencies()
+	validateResolvedTree(t, deps[0], "itoa@0.4.8", []string{"MIT"}, []string{})
+	validateResolvedTree(t, deps[1], "log@0.4.14", []string{"MIT", "Apache-2.0"}, []string{"cfg-if@1.0.0"})
+	validateResolvedTree(t, deps[2], "mylib@0.2.0", []string{"ISC"}, []string{"ryu@1.0.5"})
+	validateResolvedTree(t, deps[3], "serde@1.0.130", []string{"MIT", "Apache-2.0"}, []string{})
+	validateResolvedTree(t, deps[1].Dependencies()[0], "cfg-if@1.0.0", []string{"MIT", "Apache-2.0"}, []string{})
+	validateResolvedTree(t, deps[2].Dependencies()[0], "ryu@1.0.5", []string{}, []string{})
+}
+
+func TestParseCargoViaIndex(t *testing.T) {
+	var server *httptest.Server
+	files := http.FileServer(http.Dir("testdata/cargoindex"))
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/config.json" {
+			fmt.Fprintf(w, `{"dl": "%s/api/v1/crates", "api": "%s"}`, server.URL, server.URL)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	defer setEnv(map[string]string{"CARGO_HOME": "testdata/cargohome", CargoIndexEnvName: server.URL})()
+
+	parser := &cargoParser{context: NewContext(false, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/cargonolockproject"))
+	if err != nil {
+		t.Errorf("testdata/cargonolockproject: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree, "cargonolock4test@0.1.0", nil, []string{"log@0.4.14", "ryu@1.0.5", "cfg-if@1.0.0"})
+	validateResolvedTree(t, tree.Dependencies()[1], "ryu@1.0.5", []string{"Apache-2.0", "BSL-1.0"}, []string{})
+}
+
+func TestParseCargoOverDepth(t *testing.T) {
+	parser := &cargoParser{context: NewContext(true, "json", -1)}
+	if _, err := parser.Parse(NewPath("testdata/cargoproject")); err == nil {
+		t.Errorf("Parse with depth -1 wont error, but got nil")
+	}
+}
+
+func TestCargoVersionRange(t *testing.T) {
+	testdata := []struct {
+		req     string
+		version string
+		wont    bool
+	}{
+		{"1.0", "1.0.130", true},
+		{"1.0", "2.0.0", false},
+		{"0.4", "0.4.14", true},
+		{"0.4", "0.5.0", false},
+		{"=1.0.130", "1.0.131", false},
+		{">=1.2, <1.5", "1.4.0", true},
+		{">=1.2, <1.5", "1.5.0", false},
+		{"~1.2", "1.2.9", true},
+		{"1.*", "1.9.0", true},
+		{"*", "0.1.0", true},
+	}
+	for _, td := range testdata {
+		if got := satisfiesNpmRange(td.version, cargoVersionRange(td.req)); got != td.wont {
+			t.Errorf(`"%s" satisfies "%s" did not match, wont %v, got %v`, td.version, td.req, td.wont, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package purplecat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// cargoLockFile represents Cargo.lock.
+type cargoLockFile struct {
+	Packages []*cargoLockPackage `toml:"package"`
+}
+
+type cargoLockPackage struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	Source  string `toml:"source"`
+	// Dependencies are `name`, `name version`, or `name version (source)`, the version and the source are written
+	// only if the names (and the versions) are ambiguous in the lockfile.
+	Dependencies []string `toml:"dependencies"`
+}
+
+// cargoLock is the resolved crate graph in Cargo.lock.
+type cargoLock struct {
+	packages []*cargoPackage
+	byName   map[string][]*cargoPackage
+}
+
+func readCargoLock(path *Path, context *Context) (*cargoLock, error) {
+	logger.Infof("readCargoLock(%s)", path.Path)
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	lockFile := &cargoLockFile{}
+	if err := toml.Unmarshal([]byte(content), lockFile); err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	lock := &cargoLock{packages: []*cargoPackage{}, byName: map[string][]*cargoPackage{}}
+	for _, item := range lockFile.Packages {
+		pkg := &cargoPackage{name: item.Name, version: item.Version, source: item.Source}
+		lock.packages = append(lock.packages, pkg)
+		lock.byName[pkg.name] = append(lock.byName[pkg.name], pkg)
+	}
+	for i, item := range lockFile.Packages {
+		pkg := lock.packages[i]
+		pkg.dependencies = []*cargoPackage{}
+		for _, dependency := range item.Dependencies {
+			if found, ok := lock.find(dependency); ok {
+				pkg.dependencies = append(pkg.dependencies, found)
+			} else {
+				logger.Debugf("%s: dependency %s not found in Cargo.lock", pkg.Name(), dependency)
+			}
+		}
+	}
+	return lock, nil
+}
+
+// find returns the package of the given dependency notation (`name`, `name version`, or `name version (source)`).
+func (lock *cargoLock) find(notation string) (*cargoPackage, bool) {
+	items := strings.Fields(notation)
+	if len(items) == 0 {
+		return nil, false
+	}
+	source := ""
+	if len(items) > 2 {
+		source = strings.TrimSuffix(strings.TrimPrefix(items[2], "("), ")")
+	}
+	for _, pkg := range lock.byName[items[0]] {
+		if len(items) == 1 || (pkg.version == items[1] && (source == "" || pkg.source == source)) {
+			return pkg, true
+		}
+	}
+	return nil, false
+}
+
+// rootPackage returns the package of the given root crate in the lockfile.
+// For the virtual manifest of the workspace, the root depends on the local crates (the workspace members) in the lockfile.
+func (lock *cargoLock) rootPackage(root *cargoPackage, hasPackage bool) *cargoPackage {
+	if hasPackage {
+		for _, pkg := range lock.byName[root.name] {
+			if pkg.source == "" {
+				pkg.dir = root.dir
+				return pkg
+			}
+		}
+		return root
+	}
+	members := []*cargoPackage{}
+	if root.dependencies != nil {
+		for _, member := range root.dependencies {
+			if pkg, ok := lock.find(member.name + " " + member.version); ok {
+				members = append(members, pkg)
+			}
+		}
+	} else {
+		for _, pkg := range lock.packages {
+			if pkg.source == "" {
+				members = append(members, pkg)
+			}
+		}
+	}
+	root.dependencies = sortedCargoPackages(members)
+	return root
+}
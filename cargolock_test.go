@@ -0,0 +1,33 @@
+package purplecat
+
+import "testing"
+
+func TestCargoLockFind(t *testing.T) {
+	lock, err := readCargoLock(NewPath("testdata/cargoproject/Cargo.lock"), NewContext(true, "json", 1))
+	if err != nil {
+		t.Errorf("readCargoLock failed: %s", err.Error())
+		return
+	}
+	testdata := []struct {
+		notation    string
+		successFlag bool
+		wontName    string
+	}{
+		{"log", true, "log@0.4.14"},
+		{"ryu 1.0.5", true, "ryu@1.0.5"},
+		{"ryu 1.0.5 (registry+https://github.com/rust-lang/crates.io-index)", true, "ryu@1.0.5"},
+		{"ryu 1.0.4", false, ""},
+		{"ryu 1.0.5 (git+https://github.com/dtolnay/ryu)", false, ""},
+		{"unknown", false, ""},
+	}
+	for _, td := range testdata {
+		pkg, ok := lock.find(td.notation)
+		if ok != td.successFlag {
+			t.Errorf(`find("%s") wont success %v, got %v`, td.notation, td.successFlag, ok)
+			continue
+		}
+		if ok && pkg.Name() != td.wontName {
+			t.Errorf(`find("%s") did not match, wont %s, got %s`, td.notation, td.wontName, pkg.Name())
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package purplecat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// cargoRegistrySource is the source of the crates from crates.io in Cargo.lock.
+const cargoRegistrySource = "registry+https://github.com/rust-lang/crates.io-index"
+
+// defaultCargoIndexURL is the sparse index of crates.io.
+const defaultCargoIndexURL = "https://index.crates.io"
+
+// CargoIndexEnvName is the environment name for the url of the sparse index compatible with crates.io.
+const CargoIndexEnvName = "PURPLECAT_CARGO_INDEX_URL"
+
+// cargoIndexEntry represents the line of the index file, which is the JSON object of the version of the crate.
+type cargoIndexEntry struct {
+	Name   string                `json:"name"`
+	Vers   string                `json:"vers"`
+	Deps   []*cargoIndexDepEntry `json:"deps"`
+	Yanked bool                  `json:"yanked"`
+}
+
+type cargoIndexDepEntry struct {
+	Name     string `json:"name"`
+	Req      string `json:"req"`
+	Optional bool   `json:"optional"`
+	// Kind is `normal`, `build`, or `dev`; null means normal.
+	Kind string `json:"kind"`
+	// Package is the actual name of the crate if the dependency is renamed.
+	Package string `json:"package"`
+}
+
+// cargoIndexConfig represents config.json in the root of the index.
+type cargoIndexConfig struct {
+	API string `json:"api"`
+}
+
+// cargoCrateVersion represents the response of `$api/api/v1/crates/<name>/<version>`.
+type cargoCrateVersion struct {
+	Version struct {
+		License string `json:"license"`
+	} `json:"version"`
+}
+
+// requirements returns the normal and build dependencies of the entry, except the optional ones.
+func (entry *cargoIndexEntry) requirements() []*cargoRequirement {
+	requires := []*cargoRequirement{}
+	for _, dep := range entry.Deps {
+		if dep.Optional || dep.Kind == "dev" {
+			continue
+		}
+		name := dep.Name
+		if dep.Package != "" {
+			name = dep.Package
+		}
+		requires = append(requires, &cargoRequirement{name: name, req: dep.Req})
+	}
+	return requires
+}
+
+// cargoHome returns $CARGO_HOME, or $HOME/.cargo in the same manner as cargo.
+func cargoHome() string {
+	if dir := os.Getenv("CARGO_HOME"); dir != "" {
+		return dir
+	}
+	home, _ := homedir.Dir()
+	return filepath.Join(home, ".cargo")
+}
+
+// findCargoRegistrySrc returns the extracted crate in $CARGO_HOME/registry/src/<registry>/<name>-<version>.
+func findCargoRegistrySrc(name, version string) *Path {
+	matches, _ := filepath.Glob(filepath.Join(cargoHome(), "registry", "src", "*", name+"-"+version, "Cargo.toml"))
+	if len(matches) == 0 {
+		return nil
+	}
+	return NewPath(filepath.Dir(matches[0]))
+}
+
+// localCargoRegistryVersions returns the versions of the given crate extracted in the local registry.
+func localCargoRegistryVersions(name string) []string {
+	matches, _ := filepath.Glob(filepath.Join(cargoHome(), "registry", "src", "*", name+"-*"))
+	versions := []string{}
+	for _, match := range matches {
+		version := strings.TrimPrefix(filepath.Base(match), name+"-")
+		if isValidGoVersion("v" + version) {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+func cargoIndexURL() string {
+	if value := os.Getenv(CargoIndexEnvName); value != "" {
+		return strings.TrimSuffix(value, "/")
+	}
+	return defaultCargoIndexURL
+}
+
+// cargoIndexPath returns the path of the index file of the given crate, e.g., `se/rd/serde`, `3/l/log`, and `2/cc`.
+func cargoIndexPath(name string) string {
+	name = strings.ToLower(name)
+	switch len(name) {
+	case 1, 2:
+		return fmt.Sprintf("%d/%s", len(name), name)
+	case 3:
+		return fmt.Sprintf("3/%s/%s", name[:1], name)
+	}
+	return fmt.Sprintf("%s/%s/%s", name[:2], name[2:4], name)
+}
+
+// readIndex reads the index file of the given crate, and caches the entries in the tree.
+func (tree *cargoTree) readIndex(name string) ([]*cargoIndexEntry, error) {
+	if entries, ok := tree.index[name]; ok {
+		return entries, nil
+	}
+	if !tree.context.Allow(NetworkAccessFlag) {
+		return nil, fmt.Errorf("%s: network access denied", name)
+	}
+	logger.Infof("readCargoIndex(%s)", name)
+	path := NewPath(fmt.Sprintf("%s/%s", cargoIndexURL(), cargoIndexPath(name)))
+	content, err := readText(path, tree.context)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseCargoIndex(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	tree.index[name] = entries
+	return entries, nil
+}
+
+func parseCargoIndex(content string) ([]*cargoIndexEntry, error) {
+	entries := []*cargoIndexEntry{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry := &cargoIndexEntry{}
+		if err := json.Unmarshal([]byte(line), entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// findIndexEntry returns the entry of the given version of the crate in the index, the index is not accessed unless remote is true.
+func (tree *cargoTree) findIndexEntry(name, version string, remote bool) (*cargoIndexEntry, bool) {
+	if _, ok := tree.index[name]; !ok && !remote {
+		return nil, false
+	}
+	entries, err := tree.readIndex(name)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+		return nil, false
+	}
+	for _, entry := range entries {
+		if entry.Vers == version {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// registryAPI returns the url of the web API of the registry, which is declared in config.json of the index.
+func (tree *cargoTree) registryAPI() (string, error) {
+	if tree.api != "" {
+		return tree.api, nil
+	}
+	configPath := NewPath(cargoIndexURL() + "/config.json")
+	content, err := readText(configPath, tree.context)
+	if err != nil {
+		return "", err
+	}
+	config := &cargoIndexConfig{}
+	if err := json.Unmarshal([]byte(content), config); err != nil || config.API == "" {
+		return "", fmt.Errorf("%s: api not found", configPath.Path)
+	}
+	tree.api = strings.TrimSuffix(config.API, "/")
+	return tree.api, nil
+}
+
+// findLicenseViaRegistry returns the license field of the given version of the crate from the web API of the registry.
+func (tree *cargoTree) findLicenseViaRegistry(name, version string) (string, error) {
+	if !tree.context.Allow(NetworkAccessFlag) {
+		return "", fmt.Errorf("%s@%s: network access denied", name, version)
+	}
+	logger.Infof("findCargoLicenseViaRegistry(%s@%s)", name, version)
+	api, err := tree.registryAPI()
+	if err != nil {
+		return "", err
+	}
+	path := NewPath(fmt.Sprintf("%s/api/v1/crates/%s/%s", api, name, version))
+	content, err := readText(path, tree.context)
+	if err != nil {
+		return "", err
+	}
+	crateVersion := &cargoCrateVersion{}
+	if err := json.Unmarshal([]byte(content), crateVersion); err != nil {
+		return "", fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return crateVersion.Version.License, nil
+}
@@ -0,0 +1,31 @@
+package purplecat
+
+import "testing"
+
+func TestCargoIndexPath(t *testing.T) {
+	testdata := []struct {
+		name string
+		wont string
+	}{
+		{"a", "1/a"},
+		{"cc", "2/cc"},
+		{"log", "3/l/log"},
+		{"serde", "se/rd/serde"},
+		{"Inflector", "in/fl/inflector"},
+	}
+	for _, td := range testdata {
+		if got := cargoIndexPath(td.name); got != td.wont {
+			t.Errorf("cargoIndexPath(%s) did not match, wont %s, got %s", td.name, td.wont, got)
+		}
+	}
+}
+
+func TestLocalCargoRegistryVersions(t *testing.T) {
+	defer setEnv(map[string]string{"CARGO_HOME": "testdata/cargohome"})()
+	if got := maxSatisfyingNpmVersion(localCargoRegistryVersions("log"), cargoVersionRange("0.3")); got != "0.3.9" {
+		t.Errorf("local versions of log satisfying 0.3 did not match, wont 0.3.9, got %s", got)
+	}
+	if versions := localCargoRegistryVersions("cfg"); len(versions) != 0 {
+		t.Errorf("local versions of cfg wont empty, got %v", versions)
+	}
+}
@@ -61,6 +61,9 @@ COMMON_OPTIONS
                                    (default: ~/.config/purplecat/cachedb.json).
     -l, --log-level <LOGLEVEL>     specifies the log level. (default: WARN).
                                    Available values are: DEBUG, INFO, WARN, and FATAL
+    -r, --repositories <REPOs>     specifies the Maven repositories in the form of '[id::]url',
+                                   separated by comma. They are looked up before the repositories
+                                   in settings.xml and the poms, and the central repository.
     -h, --help                     prints this message.
 
 CLI_MODE_OPTIONS
@@ -68,7 +71,16 @@ CLI_MODE_OPTIONS
     -f, --format <FORMAT>          specifies the result format. Default is 'markdown'.
                                    Available values are: CSV, JSON, YAML, XML, and Markdown.
     -o, --output <FILE>            specifies the destination file (default: STDOUT).
+        --merge-modules            reports the multi-module project as one project with
+                                   the third-party dependencies of all modules.
     -N, --offline                  offline mode (no network access).
+    -P, --profiles <PROFILEs>      specifies the Maven profiles to be activated, separated by comma.
+                                   The profiles prefixed with '!' or '-' are deactivated.
+        --scopes <SCOPEs>          specifies the scopes of Maven dependencies to be collected,
+                                   separated by comma, e.g., compile,runtime (default: all scopes).
+                                   Available values are: compile, provided, runtime, test, and system.
+        --site-packages <DIRs>     specifies the site-packages directories of Python, separated by comma.
+                                   They are looked up before $PURPLECAT_SITE_PACKAGES and the virtual environments.
 
 SERVER_MODE_OPTIONS
     -p, --port <PORT>              specifies the port number of REST API server. Default is 8080.
@@ -82,7 +94,18 @@ BUILD_FILE
     build file of the project for extracting dependent libraries and their licenses
 
 purplecat support the projects using the following build tools.
-    * Maven 3 (pom.xml)`, name, purplecat.Version, name)
+    * Maven 3 (pom.xml)
+    * Go Modules (go.mod, go.work)
+    * Gradle (build.gradle, build.gradle.kts)
+    * npm (package-lock.json, npm-shrinkwrap.json, package.json)
+    * Yarn (yarn.lock)
+    * pnpm (pnpm-lock.yaml)
+    * Python (requirements.txt, pyproject.toml, Pipfile.lock, poetry.lock)
+    * Cargo (Cargo.lock, Cargo.toml)
+    * Bundler (Gemfile.lock)
+    * Composer (composer.lock)
+    * NuGet (*.csproj, Directory.Packages.props, packages.lock.json)
+    * Java archives (*.jar, *.war, *.ear)`, name, purplecat.Version, name)
 }
 
 func printError(err error, status int) int {
@@ -102,6 +125,11 @@ func constructFlags(args []string, opts *options) *flag.FlagSet {
 	flags.StringVarP(&opts.common.cachePath, "cachedb-path", "", purplecat.DefaultCacheDBPath(), "specifies the cache database path.")
 	flags.StringVarP(&opts.common.logLevel, "log-level", "l", "WARN", "specifies the log level")
 	flags.IntVarP(&opts.context.Depth, "depth", "d", 1, "specifies the depth for parsing")
+	flags.BoolVarP(&opts.context.MergeModules, "merge-modules", "", false, "reports the multi-module project as one project")
+	flags.StringSliceVarP(&opts.context.Profiles, "profiles", "P", []string{}, "specifies the Maven profiles to be activated")
+	flags.StringSliceVarP(&opts.context.Repositories, "repositories", "r", []string{}, "specifies the Maven repositories")
+	flags.StringSliceVarP(&opts.context.Scopes, "scopes", "", []string{}, "specifies the scopes of Maven dependencies to be collected")
+	flags.StringSliceVarP(&opts.context.SitePackages, "site-packages", "", []string{}, "specifies the site-packages directories of Python")
 	flags.IntVarP(&opts.server.port, "port", "p", 8080, "specifies the port number of REST API server")
 	flags.BoolVarP(&opts.server.runServer, "server", "s", false, "starts REST API server")
 	flags.StringVarP(&opts.cli.dest, "output", "o", "", "specifies the destination file (default: STDOUT)")
@@ -142,6 +170,20 @@ func validateLogLevel(opts *options) error {
 	return generalValidator([]string{"debug", "info", "warn", "fatal"}, opts.common.logLevel, "%s: unknown log level")
 }
 
+func validateScopes(opts *options) error {
+	return validateScopeList(opts.context.Scopes)
+}
+
+// validateScopeList checks the given scopes are the available scopes of the Maven dependencies.
+func validateScopeList(scopes []string) error {
+	for _, scope := range scopes {
+		if err := generalValidator(purplecat.MavenScopes, scope, "%s: unknown scope"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func generalValidator(available []string, value, message string) error {
 	lower := strings.ToLower(value)
 	for _, value := range available {
@@ -158,6 +200,7 @@ func validate(opts *options) error {
 		validateCachePath,
 		validateFormat,
 		validateLogLevel,
+		validateScopes,
 	}
 	for _, validator := range validators {
 		if err := validator(opts); err != nil {
@@ -236,7 +279,7 @@ func performCli(opts *options) int {
 
 func perform(opts *options) int {
 	if opts.server.runServer {
-		return opts.server.StartServer(opts.common, opts.context.Cache)
+		return opts.server.StartServer(opts.common, opts.context.Cache, opts.context.Repositories)
 	}
 	return performCli(opts)
 }
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/tamadalab/purplecat"
@@ -38,6 +40,16 @@ func parseDepth(r *http.Request) int {
 	return 1
 }
 
+func parseList(r *http.Request, key string) []string {
+	list := []string{}
+	for _, item := range strings.Split(r.FormValue(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func respondJSON(w http.ResponseWriter, context *purplecat.Context, project *purplecat.Project) {
 	buffer := bytes.NewBuffer([]byte{})
 	writer, err := context.NewWriter(buffer)
@@ -49,7 +61,9 @@ func respondJSON(w http.ResponseWriter, context *purplecat.Context, project *pur
 	respond(w, 200, buffer.Bytes())
 }
 
+// formPathSupporter serves the request body as the file of the given name, and no other files exist.
 type formPathSupporter struct {
+	name string
 	data *bytes.Buffer
 }
 
@@ -66,7 +80,7 @@ func (fp *formPathSupporter) Join(path *purplecat.Path, append string) string {
 }
 
 func (fp *formPathSupporter) ExistFile(path *purplecat.Path, context *purplecat.Context) bool {
-	return true
+	return path.Path == fp.name
 }
 
 func (fp *formPathSupporter) Open(*purplecat.Path, *purplecat.Context) (io.ReadCloser, error) {
@@ -81,19 +95,39 @@ func (fp *formPathSupporter) Close() error {
 	return nil
 }
 
+// supportedContentTypes is the content types of the request body in POST, the body is parsed as the file given by query param "file".
+var supportedContentTypes = []string{"application/xml", "application/json", "text/plain"}
+
+// postedFileName returns the file name of the request body from query param "file", the default is pom.xml.
+func postedFileName(r *http.Request) (string, error) {
+	name := r.URL.Query().Get("file")
+	if name == "" {
+		return "pom.xml", nil
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("%s: query param \"file\" must be a file name, not a path", name)
+	}
+	return name, nil
+}
+
 func runPurplecatByPost(w http.ResponseWriter, r *http.Request, context *purplecat.Context) (*purplecat.Project, error) {
 	contentType := r.Header.Get("Content-Type")
 	if contentType == "" {
 		return nil, fmt.Errorf("Content-Type was not set in the request header")
-	} else if contentType != "application/xml" {
-		return nil, fmt.Errorf("Supported Content-Type is only \"application/xml\" in the current implementation")
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || !isSupportedContentType(mediaType) {
+		return nil, fmt.Errorf("Supported Content-Type are %s in the current implementation", strings.Join(supportedContentTypes, ", "))
+	}
+	name, err := postedFileName(r)
+	if err != nil {
+		return nil, err
 	}
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil && err != io.EOF {
 		return nil, err
 	}
-	supporter := &formPathSupporter{data: bytes.NewBuffer(data)}
-	path := purplecat.NewPathWithSupporter("pom.xml", supporter)
+	supporter := &formPathSupporter{name: name, data: bytes.NewBuffer(data)}
+	path := purplecat.NewPathWithSupporter(name, supporter)
 	parser, err := context.GenerateParser(path)
 	if err != nil {
 		return nil, err
@@ -101,6 +135,15 @@ func runPurplecatByPost(w http.ResponseWriter, r *http.Request, context *purplec
 	return parser.Parse(path)
 }
 
+func isSupportedContentType(mediaType string) bool {
+	for _, supported := range supportedContentTypes {
+		if mediaType == supported {
+			return true
+		}
+	}
+	return false
+}
+
 func runPurplecatByGet(w http.ResponseWriter, r *http.Request, context *purplecat.Context) (*purplecat.Project, error) {
 	target := r.FormValue("target")
 	if target == "" {
@@ -114,11 +157,21 @@ func runPurplecatByGet(w http.ResponseWriter, r *http.Request, context *purpleca
 	return parser.Parse(path)
 }
 
-func createContext(r *http.Request, cache purplecat.CacheDB) *purplecat.Context {
+// createContext creates the context from the request. The Maven repositories are given by the server options, not by the request,
+// since the repositories may have the credentials in settings.xml of the server.
+// It returns the error if the request has the unknown scopes.
+func createContext(r *http.Request, cache purplecat.CacheDB, repositories []string) (*purplecat.Context, error) {
 	depth := parseDepth(r)
 	context := purplecat.NewContext(false, "json", depth)
+	context.Scopes = parseList(r, "scopes")
+	if err := validateScopeList(context.Scopes); err != nil {
+		return nil, err
+	}
+	context.Profiles = parseList(r, "profiles")
+	context.MergeModules = r.FormValue("merge-modules") == "true"
+	context.Repositories = repositories
 	context.Cache = cache
-	return context
+	return context, nil
 }
 
 func updateHeader(w http.ResponseWriter, r *http.Request) {
@@ -127,14 +180,18 @@ func updateHeader(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
 }
 
-func runPurplecatHandler(cache purplecat.CacheDB, method string, runFunc func(http.ResponseWriter, *http.Request, *purplecat.Context) (*purplecat.Project, error)) func(http.ResponseWriter, *http.Request) {
+func runPurplecatHandler(cache purplecat.CacheDB, repositories []string, method string, runFunc func(http.ResponseWriter, *http.Request, *purplecat.Context) (*purplecat.Project, error)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		logger.Infof("%s /purplecat/licenses", method)
 		if err := r.ParseForm(); err != nil {
 			respondError(w, http.StatusInternalServerError, err)
 			return
 		}
-		context := createContext(r, cache)
+		context, err := createContext(r, cache, repositories)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
 		project, err := runFunc(w, r, context)
 		if err != nil {
 			respondError(w, http.StatusInternalServerError, err)
@@ -146,12 +203,12 @@ func runPurplecatHandler(cache purplecat.CacheDB, method string, runFunc func(ht
 	}
 }
 
-func runPurplecatPostHandler(cache purplecat.CacheDB) func(http.ResponseWriter, *http.Request) {
-	return runPurplecatHandler(cache, "POST", runPurplecatByPost)
+func runPurplecatPostHandler(cache purplecat.CacheDB, repositories []string) func(http.ResponseWriter, *http.Request) {
+	return runPurplecatHandler(cache, repositories, "POST", runPurplecatByPost)
 }
 
-func runPurplecatGetHandler(cache purplecat.CacheDB) func(http.ResponseWriter, *http.Request) {
-	return runPurplecatHandler(cache, "GET", runPurplecatByGet)
+func runPurplecatGetHandler(cache purplecat.CacheDB, repositories []string) func(http.ResponseWriter, *http.Request) {
+	return runPurplecatHandler(cache, repositories, "GET", runPurplecatByGet)
 }
 
 func clearCacheHandler(cache purplecat.CacheDB) func(http.ResponseWriter, *http.Request) {
@@ -208,11 +265,11 @@ func existDir(dir string) bool {
 	return err == nil && stat.IsDir()
 }
 
-func createRestAPI(cache purplecat.CacheDB) *mux.Router {
+func createRestAPI(cache purplecat.CacheDB, repositories []string) *mux.Router {
 	router := mux.NewRouter()
 	subRouter := router.PathPrefix("/purplecat/api/").Subrouter()
-	subRouter.HandleFunc("/licenses", runPurplecatGetHandler(cache)).Methods("GET")
-	subRouter.HandleFunc("/licenses", runPurplecatPostHandler(cache)).Methods("POST")
+	subRouter.HandleFunc("/licenses", runPurplecatGetHandler(cache, repositories)).Methods("GET")
+	subRouter.HandleFunc("/licenses", runPurplecatPostHandler(cache, repositories)).Methods("POST")
 	subRouter.HandleFunc("/licenses", optionsHandler).Methods("OPTIONS")
 	subRouter.HandleFunc("/caches", clearCacheHandler(cache)).Methods("DELETE")
 	subRouter.HandleFunc("/caches", wholeCacheHandler(cache)).Methods("GET")
@@ -229,7 +286,7 @@ func startServer(router *mux.Router, opts *serverOpts) int {
 	return 0
 }
 
-func (server *serverOpts) StartServer(common *commonOpts, cache purplecat.CacheDB) int {
-	router := createRestAPI(cache)
+func (server *serverOpts) StartServer(common *commonOpts, cache purplecat.CacheDB, repositories []string) int {
+	router := createRestAPI(cache, repositories)
 	return startServer(router, server)
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tamadalab/purplecat"
+)
+
+func TestLicensesWithScopes(t *testing.T) {
+	previous, found := os.LookupEnv(purplecat.MavenLocalRepositoryEnvName)
+	os.Setenv(purplecat.MavenLocalRepositoryEnvName, "../../testdata/mavenrepository")
+	defer func() {
+		if found {
+			os.Setenv(purplecat.MavenLocalRepositoryEnvName, previous)
+		} else {
+			os.Unsetenv(purplecat.MavenLocalRepositoryEnvName)
+		}
+	}()
+	cache, _ := purplecat.NewCacheDB(purplecat.MemoryCache)
+	server := httptest.NewServer(createRestAPI(cache, []string{}))
+	defer server.Close()
+	testdata := []struct {
+		giveScopes     string
+		wontStatusCode int
+	}{
+		{"compile,runtime", http.StatusOK},
+		{"", http.StatusOK},
+		{"compile,runtim", http.StatusBadRequest},
+		{"unknown", http.StatusBadRequest},
+	}
+	for _, td := range testdata {
+		response, err := http.Get(server.URL + "/purplecat/api/licenses?depth=0&target=../../testdata/mavenscopeproject&scopes=" + td.giveScopes)
+		if err != nil {
+			t.Errorf("scopes=%s: request failed: %s", td.giveScopes, err.Error())
+			continue
+		}
+		response.Body.Close()
+		if response.StatusCode != td.wontStatusCode {
+			t.Errorf("scopes=%s: status code did not match, wont %d, got %d", td.giveScopes, td.wontStatusCode, response.StatusCode)
+		}
+	}
+}
+
+func TestLicensesByPost(t *testing.T) {
+	cache, _ := purplecat.NewCacheDB(purplecat.MemoryCache)
+	server := httptest.NewServer(createRestAPI(cache, []string{}))
+	defer server.Close()
+	testdata := []struct {
+		giveFile        string
+		giveQuery       string
+		giveContentType string
+		wontStatusCode  int
+		wontContent     string
+	}{
+		{"../../testdata/npmproject/package-lock.json", "?file=package-lock.json", "application/json", http.StatusOK, `"project-name":"npmproject4test@1.0.0"`},
+		{"../../testdata/npmproject/package-lock.json", "?file=package-lock.json", "application/json; charset=utf-8", http.StatusOK, `"project-name":"liba@1.2.0"`},
+		{"../../testdata/goproject/go.mod", "?file=go.mod&depth=0", "text/plain", http.StatusOK, `"project-name":"github.com/tamadalab/goproject4test"`},
+		{"../../testdata/npmproject/package-lock.json", "?file=package-lock.json", "application/x-www-form-urlencoded", http.StatusInternalServerError, "Supported Content-Type"},
+		{"../../testdata/npmproject/package-lock.json", "?file=../package-lock.json", "application/json", http.StatusInternalServerError, "must be a file name"},
+	}
+	for _, td := range testdata {
+		data, _ := ioutil.ReadFile(td.giveFile)
+		response, err := http.Post(server.URL+"/purplecat/api/licenses"+td.giveQuery, td.giveContentType, bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: request failed: %s", td.giveQuery, err.Error())
+			continue
+		}
+		content, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode != td.wontStatusCode {
+			t.Errorf("%s: status code did not match, wont %d, got %d", td.giveQuery, td.wontStatusCode, response.StatusCode)
+		}
+		if !strings.Contains(string(content), td.wontContent) {
+			t.Errorf("%s: response wont contain %s, got %s", td.giveQuery, td.wontContent, string(content))
+		}
+	}
+}
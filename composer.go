@@ -0,0 +1,275 @@
+package purplecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// composerParser is the instance of Parser for parsing composer.lock of Composer.
+type composerParser struct {
+	context *Context
+}
+
+// composerLockFile represents composer.lock, packages-dev is the packages installed only for the development.
+type composerLockFile struct {
+	Packages    []*composerLockPackage `json:"packages"`
+	PackagesDev []*composerLockPackage `json:"packages-dev"`
+}
+
+type composerLockPackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	License []string          `json:"license"`
+	Require map[string]string `json:"require"`
+	// Replace and Provide are the virtual packages satisfied by this package.
+	Replace map[string]string `json:"replace"`
+	Provide map[string]string `json:"provide"`
+}
+
+// composerJSON represents composer.json, the license is the string, or the array of strings.
+type composerJSON struct {
+	Name       string            `json:"name"`
+	Version    string            `json:"version"`
+	License    interface{}       `json:"license"`
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+}
+
+// composerPackage represents the package in the dependency graph of Composer.
+type composerPackage struct {
+	name     string
+	version  string
+	licenses Licenses
+	// dir is the location of the package, which is the project itself, or the installed directory in vendor.
+	dir             *Path
+	dependencies    []*composerPackage
+	devDependencies []*composerPackage
+}
+
+// Name returns `vendor/name@version`, the prefix `v` of the version (e.g., `v5.3.7`) is removed.
+func (pkg *composerPackage) Name() string {
+	if pkg.version == "" {
+		return pkg.name
+	}
+	return pkg.name + "@" + strings.TrimPrefix(pkg.version, "v")
+}
+
+// composerLock is the resolved package graph in composer.lock.
+type composerLock struct {
+	packages map[string]*composerPackage
+	// requires is the names of the packages required by each package.
+	requires map[*composerPackage][]string
+	// dev is the packages in packages-dev.
+	dev []*composerPackage
+}
+
+func isComposerTargetFile(name string) bool {
+	return name == "composer.lock" || name == "composer.json"
+}
+
+// IsTarget returns true if the project located on the given path is Composer project.
+func (cp *composerParser) IsTarget(path *Path, context *Context) bool {
+	if isComposerTargetFile(path.Base()) {
+		return path.Dir().Join("composer.lock").Exists(context)
+	}
+	return path.Join("composer.lock").Exists(context)
+}
+
+// Parse parses the given path as composer.lock and returns the instance of Project.
+// The tree is built without network access, since composer.lock has the licenses of all packages.
+func (cp *composerParser) Parse(path *Path) (*Project, error) {
+	dir := path
+	if isComposerTargetFile(path.Base()) {
+		dir = path.Dir()
+	}
+	if cp.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", cp.context.Depth, 0)
+	}
+	lock, err := readComposerLock(dir, cp.context)
+	if err != nil {
+		return nil, err
+	}
+	root, err := lock.rootPackage(dir, cp.context)
+	if err != nil {
+		return nil, err
+	}
+	return constructComposerProject(root, cp.context, 0)
+}
+
+func readComposerLock(dir *Path, context *Context) (*composerLock, error) {
+	path := dir.Join("composer.lock")
+	logger.Infof("readComposerLock(%s)", path.Path)
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	lockFile := &composerLockFile{}
+	if err := json.Unmarshal([]byte(content), lockFile); err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	lock := &composerLock{packages: map[string]*composerPackage{}, requires: map[*composerPackage][]string{}, dev: []*composerPackage{}}
+	items := append(append([]*composerLockPackage{}, lockFile.Packages...), lockFile.PackagesDev...)
+	for i, item := range items {
+		pkg := &composerPackage{name: item.Name, version: item.Version, licenses: composerLicenses(item.License), dir: dir.Join("vendor/" + item.Name)}
+		lock.packages[strings.ToLower(item.Name)] = pkg
+		lock.requires[pkg] = sortedComposerRequires(item.Require)
+		if i >= len(lockFile.Packages) {
+			lock.dev = append(lock.dev, pkg)
+		}
+	}
+	// the replaced and provided packages do not appear in composer.lock, they are resolved to the replacing packages.
+	for _, item := range items {
+		pkg := lock.packages[strings.ToLower(item.Name)]
+		for _, name := range append(sortedComposerRequires(item.Replace), sortedComposerRequires(item.Provide)...) {
+			if _, ok := lock.packages[name]; !ok {
+				lock.packages[name] = pkg
+			}
+		}
+	}
+	for pkg, requires := range lock.requires {
+		pkg.dependencies = lock.resolve(pkg.Name(), requires)
+	}
+	return lock, nil
+}
+
+// rootPackage returns the package of the project itself from composer.json. If composer.json does not exist,
+// the root depends on the packages which are not required by any other packages.
+func (lock *composerLock) rootPackage(dir *Path, context *Context) (*composerPackage, error) {
+	root := &composerPackage{name: dir.Base(), dir: dir}
+	path := dir.Join("composer.json")
+	if !path.Exists(context) {
+		root.dependencies, root.devDependencies = lock.topLevelPackages()
+		root.licenses = findLicensesInDir(dir, context)
+		return root, nil
+	}
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &composerJSON{}
+	if err := json.Unmarshal([]byte(content), manifest); err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	if manifest.Name != "" {
+		root.name, root.version = manifest.Name, manifest.Version
+	}
+	if license, ok := manifest.License.(string); ok {
+		root.licenses = composerLicenses([]string{license})
+	} else {
+		root.licenses = composerLicenses(toStringSlice(manifest.License))
+	}
+	if len(root.licenses) == 0 {
+		root.licenses = findLicensesInDir(dir, context)
+	}
+	root.dependencies = lock.resolve(root.Name(), sortedComposerRequires(manifest.Require))
+	root.devDependencies = lock.resolve(root.Name(), sortedComposerRequires(manifest.RequireDev))
+	return root, nil
+}
+
+func (lock *composerLock) topLevelPackages() ([]*composerPackage, []*composerPackage) {
+	required := map[*composerPackage]bool{}
+	isDev := map[*composerPackage]bool{}
+	for pkg := range lock.requires {
+		for _, dependency := range pkg.dependencies {
+			required[dependency] = true
+		}
+	}
+	for _, pkg := range lock.dev {
+		isDev[pkg] = true
+	}
+	dependencies, devDependencies := []*composerPackage{}, []*composerPackage{}
+	for pkg := range lock.requires {
+		if required[pkg] {
+			continue
+		}
+		if isDev[pkg] {
+			devDependencies = append(devDependencies, pkg)
+		} else {
+			dependencies = append(dependencies, pkg)
+		}
+	}
+	return sortedComposerPackages(dependencies), sortedComposerPackages(devDependencies)
+}
+
+func (lock *composerLock) resolve(from string, names []string) []*composerPackage {
+	dependencies := []*composerPackage{}
+	for _, name := range names {
+		if isComposerPlatformPackage(name) {
+			continue
+		}
+		if pkg, ok := lock.packages[name]; ok {
+			dependencies = append(dependencies, pkg)
+		} else {
+			logger.Debugf("%s: dependency %s not found in composer.lock", from, name)
+		}
+	}
+	return dependencies
+}
+
+// isComposerPlatformPackage returns true for the platform packages (e.g., `php`, `ext-json`, and `composer-plugin-api`),
+// which are provided by the environment, and have no vendor name.
+func isComposerPlatformPackage(name string) bool {
+	return !strings.Contains(name, "/")
+}
+
+// composerLicenses converts the license field of Composer into the licenses; each item may be the SPDX expression
+// (e.g., `(LGPL-2.1-only or GPL-3.0-or-later)`), and `proprietary` shows the closed-source package.
+func composerLicenses(items []string) Licenses {
+	licenses := Licenses{}
+	for _, item := range items {
+		if strings.EqualFold(item, "proprietary") {
+			licenses = appendLicenseIfAbsent(licenses, &License{Name: "proprietary", SpdxID: "proprietary"})
+			continue
+		}
+		for _, license := range parseSpdxExpression(item) {
+			licenses = appendLicenseIfAbsent(licenses, license)
+		}
+	}
+	return licenses
+}
+
+func sortedComposerRequires(requires map[string]string) []string {
+	names := []string{}
+	for name := range requires {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedComposerPackages(packages []*composerPackage) []*composerPackage {
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name() < packages[j].Name()
+	})
+	return packages
+}
+
+func constructComposerProject(pkg *composerPackage, context *Context, currentDepth int) (*Project, error) {
+	if context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", context.Depth, currentDepth)
+	}
+	logger.Infof("constructComposerProject(%s, %d)", pkg.Name(), currentDepth)
+	licenses := pkg.licenses
+	if len(licenses) == 0 && currentDepth > 0 {
+		// some packages omit the license in composer.json, then the license files in the installed directory are read.
+		licenses = findLicensesInDir(pkg.dir, context)
+	}
+	project := context.NewProject(pkg.Name(), licenses)
+	for _, dependency := range pkg.dependencies {
+		project.Deps = append(project.Deps, dependency.Name())
+	}
+	for _, dependency := range pkg.devDependencies {
+		project.DevDeps = append(project.DevDeps, dependency.Name())
+	}
+	for _, dependency := range append(append([]*composerPackage{}, pkg.dependencies...), pkg.devDependencies...) {
+		if _, ok := context.SearchCache(dependency.Name()); ok {
+			continue
+		}
+		constructComposerProject(dependency, context, currentDepth+1)
+	}
+	return project, nil
+}
@@ -0,0 +1,131 @@
+package purplecat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseComposer(t *testing.T) {
+	testdata := []struct {
+		path        string
+		wontName    string
+		wontDeps    []string
+		wontDevDeps []string
+	}{
+		{"testdata/composerproject", "tamadalab/composer4test", []string{"monolog/monolog@2.3.5", "symfony/polyfill-ctype@1.23.0"}, []string{"phpunit/phpunit@9.5.10"}},
+		{"testdata/composerproject/composer.lock", "tamadalab/composer4test", []string{"monolog/monolog@2.3.5", "symfony/polyfill-ctype@1.23.0"}, []string{"phpunit/phpunit@9.5.10"}},
+	}
+	for _, td := range testdata {
+		parser := &composerParser{context: NewContext(true, "json", 2)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		validateResolvedTree(t, tree, td.wontName, []string{"Apache-2.0"}, td.wontDeps)
+		if strings.Join(tree.DevDeps, ",") != strings.Join(td.wontDevDeps, ",") {
+			t.Errorf("%s: dev dependencies did not match, wont %v, got %v", td.path, td.wontDevDeps, tree.DevDeps)
+		}
+	}
+}
+
+func TestParseComposerLicenses(t *testing.T) {
+	parser := &composerParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/composerproject"))
+	if err != nil {
+		t.Errorf("testdata/composerproject: parse failed: %s", err.Error())
+		return
+	}
+	deps := tree.Dependencies()
+	validateResolvedTree(t, deps[0], "monolog/monolog@2.3.5", []string{"MIT"}, []string{"psr/log@1.1.4"})
+	validateResolvedTree(t, deps[1], "symfony/polyfill-ctype@1.23.0", []string{"MIT"}, []string{})
+	devDeps := tree.DevDependencies()
+	validateResolvedTree(t, devDeps[0], "phpunit/phpunit@9.5.10", []string{"BSD-3-Clause"}, []string{"acme/fixtures@1.0.0", "sebastian/diff@4.0.4"})
+	validateResolvedTree(t, devDeps[0].Dependencies()[0], "acme/fixtures@1.0.0", []string{"ISC"}, []string{})
+	validateResolvedTree(t, devDeps[0].Dependencies()[1], "sebastian/diff@4.0.4", []string{"BSD-3-Clause", "MIT"}, []string{})
+}
+
+func TestComposerTopLevelPackages(t *testing.T) {
+	lock, err := readComposerLock(NewPath("testdata/composerproject"), NewContext(true, "json", 1))
+	if err != nil {
+		t.Errorf("testdata/composerproject: read failed: %s", err.Error())
+		return
+	}
+	dependencies, devDependencies := lock.topLevelPackages()
+	testdata := []struct {
+		giveName string
+		packages []*composerPackage
+		wontDeps []string
+	}{
+		{"dependencies", dependencies, []string{"monolog/monolog@2.3.5", "symfony/polyfill-ctype@1.23.0"}},
+		{"devDependencies", devDependencies, []string{"phpunit/phpunit@9.5.10"}},
+	}
+	for _, td := range testdata {
+		names := []string{}
+		for _, pkg := range td.packages {
+			names = append(names, pkg.Name())
+		}
+		if strings.Join(names, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("%s did not match, wont %v, got %v", td.giveName, td.wontDeps, names)
+		}
+	}
+	if pkg, ok := lock.packages["psr/log-implementation"]; !ok || pkg.Name() != "monolog/monolog@2.3.5" {
+		t.Errorf("psr/log-implementation wont be provided by monolog/monolog@2.3.5, got %v", pkg)
+	}
+}
+
+func TestParseComposerOverDepth(t *testing.T) {
+	parser := &composerParser{context: NewContext(true, "json", -1)}
+	if _, err := parser.Parse(NewPath("testdata/composerproject")); err == nil {
+		t.Errorf("Parse with depth -1 wont error, but got nil")
+	}
+}
+
+func TestComposerLicenses(t *testing.T) {
+	testdata := []struct {
+		giveLicenses []string
+		wontSpdxIDs  []string
+	}{
+		{[]string{"MIT"}, []string{"MIT"}},
+		{[]string{"(LGPL-2.1-only or GPL-3.0-or-later)"}, []string{"LGPL-2.1-only", "GPL-3.0-or-later"}},
+		{[]string{"proprietary"}, []string{"proprietary"}},
+		{[]string{}, []string{}},
+	}
+	for _, td := range testdata {
+		spdxIDs := []string{}
+		for _, license := range composerLicenses(td.giveLicenses) {
+			spdxIDs = append(spdxIDs, license.SpdxID)
+		}
+		if strings.Join(spdxIDs, ",") != strings.Join(td.wontSpdxIDs, ",") {
+			t.Errorf("composerLicenses(%v) did not match, wont %v, got %v", td.giveLicenses, td.wontSpdxIDs, spdxIDs)
+		}
+	}
+}
+
+func TestWriteDevDependencies(t *testing.T) {
+	testdata := []struct {
+		format string
+		wont   string
+	}{
+		{"markdown", "    * phpunit/phpunit@9.5.10 (dev): [BSD 3-Clause \"New\" or \"Revised\" License]"},
+		{"csv", "phpunit/phpunit@9.5.10 (dev),"},
+		{"json", `"dev-dependencies":[{"project-name":"phpunit/phpunit@9.5.10"`},
+		{"yaml", "dev-dependencies:"},
+		{"xml", "<dev-dependency>"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, td.format, 1)
+		tree, err := (&composerParser{context: context}).Parse(NewPath("testdata/composerproject"))
+		if err != nil {
+			t.Errorf("testdata/composerproject: parse failed: %s", err.Error())
+			continue
+		}
+		out := &bytes.Buffer{}
+		writer, _ := context.NewWriter(out)
+		writer.Write(tree)
+		if !strings.Contains(out.String(), td.wont) {
+			t.Errorf("%s: output wont contain %s, got %s", td.format, td.wont, out.String())
+		}
+	}
+}
@@ -3,12 +3,13 @@ module github.com/tamadalab/purplecat
 go 1.15
 
 require (
-	github.com/antchfx/htmlquery v1.2.3
 	github.com/antchfx/xmlquery v1.3.3
 	github.com/asaskevich/govalidator v0.0.0-20200907205600-7a23bdc65eef
 	github.com/go-resty/resty/v2 v2.3.0
 	github.com/gorilla/mux v1.8.0
 	github.com/mitchellh/go-homedir v1.1.0
+	github.com/pelletier/go-toml v1.9.5
 	github.com/spf13/pflag v1.0.5
-	golang.org/x/net v0.0.0-20201031054903-ff519b6c9102
+	golang.org/x/net v0.0.0-20201031054903-ff519b6c9102 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -2,60 +2,496 @@ package purplecat
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
 
-	"github.com/antchfx/htmlquery"
-	"golang.org/x/net/html"
+	homedir "github.com/mitchellh/go-homedir"
 
 	"github.com/tamadalab/purplecat/logger"
 )
 
 type goModParser struct {
 	context *Context
+	// replaces is the list of replace directives in the main modules (and go.work), they are applied for all modules.
+	replaces []*goReplace
+	// excludes is the list of the excluded modules in the main modules, they are ignored in all modules.
+	excludes []*goModule
+	// mainModules is the set of the module paths of the main modules (the modules in the workspace).
+	mainModules map[string]bool
+	// reached is the set of the module paths appeared in the dependency graph.
+	reached map[string]bool
+	// selected maps the module paths to the versions chosen by the minimal version selection.
+	selected map[string]string
+	// modFiles keeps go.mod of the modules read in the version selection, keyed by the module name.
+	modFiles map[string]*goModFile
 }
 
-const (
-	localGoModPath = "go/pkg/mod"
-	pkgGoDevURL    = "https://pkg.go.dev"
-)
+const localGoModPath = "go/pkg/mod"
 
 func (gmp *goModParser) IsTarget(path *Path, context *Context) bool {
 	base := path.Base()
-	if base == "go.mod" {
+	if base == "go.mod" || base == "go.work" {
 		return path.Exists(context)
 	}
-	join := path.Join("go.mod")
-	return join.Exists(context)
+	return path.Join("go.mod").Exists(context) || path.Join("go.work").Exists(context)
 }
 
+// Parse parses the given path as go.mod (or go.work) and returns the instance of Project.
 func (gmp *goModParser) Parse(path *Path) (*Project, error) {
-	return nil, fmt.Errorf("not implement yet")
+	if path.Base() != "go.mod" && path.Base() != "go.work" {
+		if workPath := path.Join("go.work"); workPath.Exists(gmp.context) {
+			path = workPath
+		} else {
+			path = path.Join("go.mod")
+		}
+	}
+	if !path.Exists(gmp.context) {
+		return nil, fmt.Errorf("%s: not go module project (go.mod not found)", path.Path)
+	}
+	if gmp.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", gmp.context.Depth, 0)
+	}
+	if path.Base() == "go.work" {
+		return gmp.parseGoWork(path)
+	}
+	return gmp.parseMainGoMod(path)
+}
+
+func (gmp *goModParser) parseMainGoMod(path *Path) (*Project, error) {
+	logger.Infof("parseMainGoMod(%s)", path.Path)
+	modFile, err := readGoMod(path, gmp.context)
+	if err != nil {
+		return nil, err
+	}
+	gmp.addMainModule(modFile, path.Dir())
+	if vendorPath := path.Dir().Join("vendor").Join("modules.txt"); vendorPath.Exists(gmp.context) {
+		return gmp.constructGoVendorProject(modFile, path.Dir(), vendorPath)
+	}
+	gmp.selectGoVersions([]*goModFile{modFile})
+	return gmp.constructGoMainProject(modFile, path.Dir())
+}
+
+// parseGoWork parses go.work, and builds the project which depends on the main modules in the workspace.
+func (gmp *goModParser) parseGoWork(path *Path) (*Project, error) {
+	logger.Infof("parseGoWork(%s)", path.Path)
+	workFile, err := readGoWork(path, gmp.context)
+	if err != nil {
+		return nil, err
+	}
+	gmp.addReplaces(workFile.replaces, path.Dir())
+	modFiles := []*goModFile{}
+	for _, use := range workFile.uses {
+		modFile, err := readGoMod(path.Dir().Join(use).Join("go.mod"), gmp.context)
+		if err != nil {
+			return nil, err
+		}
+		gmp.addMainModule(modFile, path.Dir().Join(use))
+		modFiles = append(modFiles, modFile)
+	}
+	gmp.selectGoVersions(modFiles)
+	project := gmp.context.NewProject(path.Path, Licenses{})
+	for i, modFile := range modFiles {
+		if _, err := gmp.constructGoMainProject(modFile, path.Dir().Join(workFile.uses[i])); err != nil {
+			return nil, err
+		}
+		project.Deps = append(project.Deps, modFile.module.Name())
+	}
+	return project, nil
+}
+
+func (gmp *goModParser) constructGoMainProject(modFile *goModFile, dir *Path) (*Project, error) {
+	licenses := findLicensesInDir(dir, gmp.context)
+	project, err := gmp.constructGoProject(modFile.module, modFile, licenses, 0)
+	if err != nil {
+		return nil, err
+	}
+	if sumPath := dir.Join("go.sum"); sumPath.Exists(gmp.context) {
+		gmp.supplementGoSum(project, sumPath)
+	}
+	return project, nil
+}
+
+// constructGoVendorProject builds the project from vendor/modules.txt, and the licenses of the dependencies are
+// read from the license files in vendor/<module path>/, or in the replacing directory.
+// The vendor directory is the authoritative source of the build, therefore, the vendored modules are the dependencies of
+// the main module, and no further dependencies are looked up.
+func (gmp *goModParser) constructGoVendorProject(modFile *goModFile, dir, vendorPath *Path) (*Project, error) {
+	logger.Infof("constructGoVendorProject(%s)", vendorPath.Path)
+	modules, err := readGoVendorModules(vendorPath, gmp.context)
+	if err != nil {
+		return nil, err
+	}
+	project := gmp.context.NewProject(modFile.module.Name(), findLicensesInDir(dir, gmp.context))
+	for _, module := range modules {
+		if module.replace != nil && module.replace.version == "" {
+			module.replace.dir = resolveGoLocalDir(dir, module.replace.path)
+		}
+		project.Deps = append(project.Deps, module.Name())
+		if _, ok := gmp.context.SearchCache(module.Name()); ok || gmp.context.Depth < 1 {
+			continue
+		}
+		gmp.context.NewProject(module.Name(), gmp.findGoVendoredLicenses(module, vendorPath.Dir()))
+	}
+	return project, nil
+}
+
+// findGoVendoredLicenses reads the licenses of the given vendored module.
+// The module replaced by a local directory is read from the directory, since the vendor directory keeps only the copied packages,
+// and the vendored copy is used if the directory is not available.
+func (gmp *goModParser) findGoVendoredLicenses(module *goModule, vendorDir *Path) Licenses {
+	if dir := module.target().dir; dir != nil {
+		if licenses := findLicensesInDir(dir, gmp.context); len(licenses) > 0 {
+			return licenses
+		}
+	}
+	return findLicensesInDir(vendorDir.Join(module.path), gmp.context)
+}
+
+// supplementGoSum adds the modules listed in go.sum, but not appeared in the dependency graph to the unreached dependencies of
+// the given main project. Such modules are required by the modules whose go.mod were not available
+// (e.g., in offline mode), or by the dependencies of the modules declared in go.mod before Go 1.17.
+// They are kept apart from the dependencies, since their requirers are unknown.
+func (gmp *goModParser) supplementGoSum(project *Project, sumPath *Path) {
+	modules, err := readGoSum(sumPath, gmp.context)
+	if err != nil {
+		logger.Warnf("%s", err.Error())
+		return
+	}
+	for _, module := range modules {
+		if gmp.reached[module.path] || gmp.mainModules[module.path] || gmp.isExcluded(module) {
+			continue
+		}
+		resolved := gmp.resolveRequire(module)
+		gmp.markReached(resolved)
+		project.UnreachedDeps = append(project.UnreachedDeps, resolved.Name())
+		if _, ok := gmp.context.SearchCache(resolved.Name()); !ok {
+			gmp.findGoModule(resolved, 1)
+		}
+	}
+}
+
+func (gmp *goModParser) markReached(module *goModule) {
+	if gmp.reached == nil {
+		gmp.reached = map[string]bool{}
+	}
+	gmp.reached[module.path] = true
+}
+
+// addMainModule registers the replace and exclude directives of the given main module.
+// Note that the go command ignores the replace and exclude directives in the modules other than the main modules.
+func (gmp *goModParser) addMainModule(modFile *goModFile, dir *Path) {
+	if gmp.mainModules == nil {
+		gmp.mainModules = map[string]bool{}
+	}
+	gmp.mainModules[modFile.module.path] = true
+	gmp.addReplaces(modFile.replaces, dir)
+	gmp.excludes = append(gmp.excludes, modFile.excludes...)
+}
+
+// addReplaces appends the given replace directives, the directives for specific versions take precedence over the ones for all versions.
+// The local directory replacements are resolved from the given dir.
+func (gmp *goModParser) addReplaces(replaces []*goReplace, dir *Path) {
+	for _, specific := range []bool{true, false} {
+		for _, replace := range replaces {
+			if (replace.old.version != "") != specific {
+				continue
+			}
+			if replace.new.version == "" && replace.new.dir == nil {
+				replace.new.dir = resolveGoLocalDir(dir, replace.new.path)
+			}
+			gmp.replaces = append(gmp.replaces, replace)
+		}
+	}
+}
+
+func resolveGoLocalDir(base *Path, dir string) *Path {
+	if filepath.IsAbs(dir) {
+		return NewPath(dir)
+	}
+	return base.Join(filepath.ToSlash(dir))
+}
+
+func (gmp *goModParser) isExcluded(module *goModule) bool {
+	for _, exclude := range gmp.excludes {
+		if exclude.path == module.path && exclude.version == module.version {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRequire applies the replace directives in the main modules for the given require.
+// If the given module is the one of main modules in the workspace, this function returns the main module.
+func (gmp *goModParser) resolveRequire(require *goModule) *goModule {
+	if gmp.mainModules[require.path] {
+		return newGoModule(require.path, "")
+	}
+	for _, replace := range gmp.replaces {
+		if replace.match(require) {
+			resolved := newGoModule(require.path, require.version)
+			resolved.indirect = require.indirect
+			resolved.replace = replace.new
+			return resolved
+		}
+	}
+	return require
+}
+
+// requires returns the requirements of the given module after applying the exclude and replace directives in the main modules.
+func (gmp *goModParser) requires(modFile *goModFile) []*goModule {
+	requires := []*goModule{}
+	for _, require := range modFile.requires {
+		if gmp.isExcluded(require) {
+			logger.Infof("%s: excluded by the main module", require.Name())
+			continue
+		}
+		requires = append(requires, gmp.resolveRequire(gmp.selectVersion(require)))
+	}
+	return requires
+}
+
+// selectVersion returns the given require with the version chosen by the minimal version selection.
+// Before the selection, this function returns the given require as it is.
+func (gmp *goModParser) selectVersion(require *goModule) *goModule {
+	version, ok := gmp.selected[require.path]
+	if !ok || version == require.version {
+		return require
+	}
+	selected := newGoModule(require.path, version)
+	selected.indirect = require.indirect
+	return selected
+}
+
+// selectGoVersions performs the minimal version selection of the go command for the given main modules,
+// that is, the highest version among the requirements reached from the main modules is chosen for each module path.
+// The requirement graph is read up to the parsing depth, and the modules whose go.mod are not available are treated as leaves.
+func (gmp *goModParser) selectGoVersions(modFiles []*goModFile) {
+	type requirement struct {
+		modFile *goModFile
+		depth   int
+	}
+	selected := map[string]string{}
+	visited := map[string]bool{}
+	queue := []*requirement{}
+	for _, modFile := range modFiles {
+		queue = append(queue, &requirement{modFile: modFile, depth: 0})
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, require := range gmp.requires(current.modFile) {
+			if gmp.mainModules[require.path] {
+				continue
+			}
+			if version, ok := selected[require.path]; !ok || compareGoVersion(require.version, version) > 0 {
+				selected[require.path] = require.version
+			}
+			if visited[require.Name()] || gmp.context.Depth < current.depth+1 {
+				continue
+			}
+			visited[require.Name()] = true
+			modFile, err := gmp.readRequiredGoMod(require)
+			if err != nil {
+				logger.Debugf("%s: %s", require.Name(), err.Error())
+				continue
+			}
+			queue = append(queue, &requirement{modFile: modFile, depth: current.depth + 1})
+		}
+	}
+	gmp.selected = selected
 }
 
-func findLicenseViaPkgGoDev(path *Path, context *Context, currentDepth int) (*Project, error) {
-	if !context.Allow(NetworkAccessFlag) {
-		return nil, fmt.Errorf("network access denied")
+// readRequiredGoMod reads go.mod of the given module from the replacing directory, the local module cache, or the module proxies.
+// The go.mod read from the module proxies are kept for constructing the projects of the selected modules.
+func (gmp *goModParser) readRequiredGoMod(module *goModule) (*goModFile, error) {
+	target := module.target()
+	if target.dir != nil {
+		return readGoMod(target.dir.Join("go.mod"), gmp.context)
+	}
+	if dir := constructLocalGoModulePath(target); existDir(dir.Path) {
+		return readLocalGoMod(target, dir, gmp.context)
 	}
-	if context.Depth < currentDepth {
-		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", context.Depth, currentDepth)
+	var lastErr = fmt.Errorf("%s: no module proxies available", module.Name())
+	for _, proxy := range goProxies(target.path) {
+		modFile, err := proxy.goMod(target, gmp.context)
+		if err == nil {
+			if gmp.modFiles == nil {
+				gmp.modFiles = map[string]*goModFile{}
+			}
+			gmp.modFiles[target.Name()] = modFile
+			return modFile, nil
+		}
+		lastErr = err
+		if !proxy.canFallback(err) {
+			break
+		}
 	}
-	logger.Infof("findLicenseViaPkgGoDev(%s, %d)", path.Path, currentDepth)
-	return findLicenseViaPkgGoDevImpl(path, context, currentDepth)
+	return nil, lastErr
 }
 
-func findLicenseViaPkgGoDevImpl(path *Path, context *Context, currentDepth int) (*Project, error) {
-	modulePage, err := path.Open(context)
+func readGoMod(path *Path, context *Context) (*goModFile, error) {
+	reader, err := path.Open(context)
 	if err != nil {
 		return nil, err
 	}
-	defer modulePage.Close()
+	defer reader.Close()
+	modFile, err := parseGoModContent(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return modFile, nil
+}
 
-	doc, err := htmlquery.Parse(modulePage)
+func readGoVendorModules(path *Path, context *Context) ([]*goModule, error) {
+	reader, err := path.Open(context)
 	if err != nil {
 		return nil, err
 	}
-	return parseProject(doc)
+	defer reader.Close()
+	modules, err := parseGoVendorModules(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return modules, nil
+}
+
+func readGoSum(path *Path, context *Context) ([]*goModule, error) {
+	reader, err := path.Open(context)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	modules, err := parseGoSum(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return modules, nil
+}
+
+func readGoWork(path *Path, context *Context) (*goWorkFile, error) {
+	reader, err := path.Open(context)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	workFile, err := parseGoWorkContent(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return workFile, nil
+}
+
+func (gmp *goModParser) constructGoProject(module *goModule, modFile *goModFile, licenses Licenses, currentDepth int) (*Project, error) {
+	project := gmp.context.NewProject(module.Name(), licenses)
+	requires := gmp.requires(modFile)
+	for _, require := range requires {
+		gmp.markReached(require)
+		project.Deps = append(project.Deps, require.Name())
+	}
+	for _, require := range requires {
+		if _, ok := gmp.context.SearchCache(require.Name()); ok || gmp.mainModules[require.Name()] {
+			continue
+		}
+		gmp.findGoModule(require, currentDepth+1)
+	}
+	return project, nil
 }
 
-func parseProject(root *html.Node) (*Project, error) {
-	return nil, nil
+func (gmp *goModParser) findGoModule(module *goModule, currentDepth int) (*Project, error) {
+	if gmp.context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", gmp.context.Depth, currentDepth)
+	}
+	if module.target().dir != nil {
+		return gmp.findGoModuleInDir(module, currentDepth)
+	}
+	finders := []func(*goModule, int) (*Project, error){
+		gmp.findGoModuleInLocalCache,
+		gmp.findGoModuleViaGoProxy,
+	}
+	for _, finder := range finders {
+		project, err := finder(module, currentDepth)
+		if err == nil && project != nil {
+			return project, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: module not found", module.Name())
+}
+
+// findGoModuleInDir builds the project from the local directory replacing the given module.
+func (gmp *goModParser) findGoModuleInDir(module *goModule, currentDepth int) (*Project, error) {
+	dir := module.target().dir
+	logger.Infof("findGoModuleInDir(%s, %d)", dir.Path, currentDepth)
+	modFile, err := readGoMod(dir.Join("go.mod"), gmp.context)
+	if err != nil {
+		return nil, err
+	}
+	licenses := findLicensesInDir(dir, gmp.context)
+	return gmp.constructGoProject(module, modFile, licenses, currentDepth)
+}
+
+func (gmp *goModParser) findGoModuleInLocalCache(module *goModule, currentDepth int) (*Project, error) {
+	dir := constructLocalGoModulePath(module.target())
+	if !existDir(dir.Path) {
+		return nil, fmt.Errorf("%s: not found in the local module cache", module.Name())
+	}
+	logger.Infof("findGoModuleInLocalCache(%s, %d)", dir.Path, currentDepth)
+	modFile, err := readLocalGoMod(module.target(), dir, gmp.context)
+	if err != nil {
+		return nil, err
+	}
+	licenses := findLicensesInDir(dir, gmp.context)
+	return gmp.constructGoProject(module, modFile, licenses, currentDepth)
+}
+
+// readLocalGoMod reads go.mod in the extracted module directory.
+// If the module has no go.mod (the module before Go modules era), this function reads
+// the go.mod synthesized by the go command in the download cache.
+func readLocalGoMod(module *goModule, dir *Path, context *Context) (*goModFile, error) {
+	goModPath := dir.Join("go.mod")
+	if !goModPath.Exists(context) {
+		goModPath = constructLocalGoModCachePath(module)
+	}
+	if !goModPath.Exists(context) {
+		return &goModFile{module: newGoModule(module.path, ""), requires: []*goModule{}}, nil
+	}
+	return readGoMod(goModPath, context)
+}
+
+// localGoModCacheDir returns the location of the module cache in the same manner as the go command,
+// that is, $GOMODCACHE, $GOPATH/pkg/mod, or $HOME/go/pkg/mod.
+func localGoModCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	if gopath := os.Getenv("GOPATH"); gopath != "" {
+		return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+	}
+	home, _ := homedir.Dir()
+	return filepath.Join(home, localGoModPath)
+}
+
+func constructLocalGoModulePath(module *goModule) *Path {
+	name := fmt.Sprintf("%s@%s", escapeGoModulePath(module.path), escapeGoModulePath(module.version))
+	return NewPath(filepath.Join(localGoModCacheDir(), filepath.FromSlash(name)))
+}
+
+func constructLocalGoModCachePath(module *goModule) *Path {
+	name := fmt.Sprintf("%s/@v/%s.mod", escapeGoModulePath(module.path), escapeGoModulePath(module.version))
+	return NewPath(filepath.Join(localGoModCacheDir(), "cache", "download", filepath.FromSlash(name)))
+}
+
+// escapeGoModulePath escapes the upper case letters in the module path (or version) by `!` and its lower case,
+// for the case-insensitive file systems (e.g., `github.com/BurntSushi/toml` -> `github.com/!burnt!sushi/toml`).
+func escapeGoModulePath(path string) string {
+	builder := strings.Builder{}
+	for _, c := range path {
+		if unicode.IsUpper(c) {
+			builder.WriteRune('!')
+			c = unicode.ToLower(c)
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String()
 }
@@ -0,0 +1,211 @@
+package purplecat
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	projectName := "testdata/goproject"
+	parser := &goModParser{context: NewContext(true, "json", 1)}
+
+	tree, err := parser.Parse(NewPath(projectName))
+	if err != nil {
+		t.Errorf("%s: parse failed: %s", projectName, err.Error())
+		return
+	}
+	if tree.Name() != "github.com/tamadalab/goproject4test" {
+		t.Errorf("project name did not match, wont github.com/tamadalab/goproject4test, got %s", tree.Name())
+	}
+	wontDeps := []string{
+		"github.com/asaskevich/govalidator@v0.0.0-20200907205600-7a23bdc65eef",
+		"github.com/mitchellh/go-homedir@v1.1.0",
+		"golang.org/x/text@v0.3.3",
+		"github.com/spf13/pflag@v1.0.5",
+	}
+	if len(tree.Deps) != len(wontDeps) {
+		t.Errorf("dependency count did not match, wont %d, got %d", len(wontDeps), len(tree.Deps))
+		return
+	}
+	for i, wont := range wontDeps {
+		if tree.Deps[i] != wont {
+			t.Errorf("dependency[%d] did not match, wont %s, got %s", i, wont, tree.Deps[i])
+		}
+	}
+}
+
+func TestParseGoModOverDepth(t *testing.T) {
+	parser := &goModParser{context: NewContext(true, "json", -1)}
+	if _, err := parser.Parse(NewPath("testdata/goproject/go.mod")); err == nil {
+		t.Errorf("Parse with depth -1 wont error, but got nil")
+	}
+}
+
+func TestParseGoWork(t *testing.T) {
+	os.Setenv("GOMODCACHE", "testdata/gomodcache")
+	defer os.Unsetenv("GOMODCACHE")
+	parser := &goModParser{context: NewContext(true, "json", 2)}
+
+	tree, err := parser.Parse(NewPath("testdata/goworkspace"))
+	if err != nil {
+		t.Errorf("testdata/goworkspace: parse failed: %s", err.Error())
+		return
+	}
+	if tree.Name() != "testdata/goworkspace/go.work" || len(tree.Dependencies()) != 2 {
+		t.Errorf("workspace did not match, wont testdata/goworkspace/go.work with 2 modules, got %s with %d modules", tree.Name(), len(tree.Dependencies()))
+		return
+	}
+	app := tree.Dependencies()[0]
+	validateGoDependencyTree(t, app, "github.com/tamadalab/app", "WTFPL", 4)
+	validateGoDependencyTree(t, tree.Dependencies()[1], "github.com/tamadalab/lib", "MIT", 0)
+	validateGoDependencyTree(t, app.Dependencies()[0], "github.com/tamadalab/forked@v1.0.0 => testdata/goworkspace/forked", "ISC", 0)
+	validateGoDependencyTree(t, app.Dependencies()[1], "github.com/tamadalab/lib", "MIT", 0)
+	validateGoDependencyTree(t, app.Dependencies()[2], "github.com/tamadalab/liba@v1.0.0", "MIT", 1)
+	validateGoDependencyTree(t, app.Dependencies()[3], "github.com/tamadalab/old@v0.1.0 => github.com/Tamada/libc@v1.1.0", "ISC", 0)
+	validateGoDependencyTree(t, app.Dependencies()[2].Dependencies()[0], "github.com/Tamada/libc@v1.1.0", "ISC", 0)
+}
+
+func TestParseGoModWithMinimalVersionSelection(t *testing.T) {
+	os.Setenv("GOMODCACHE", "testdata/gomodcache")
+	defer os.Unsetenv("GOMODCACHE")
+	parser := &goModParser{context: NewContext(true, "json", 2)}
+
+	tree, err := parser.Parse(NewPath("testdata/gomvsproject"))
+	if err != nil {
+		t.Errorf("testdata/gomvsproject: parse failed: %s", err.Error())
+		return
+	}
+	wontDeps := []string{"github.com/tamadalab/liba@v1.0.0", "github.com/tamadalab/libb@v0.2.0"}
+	if len(tree.Deps) != len(wontDeps) || tree.Deps[0] != wontDeps[0] || tree.Deps[1] != wontDeps[1] {
+		t.Errorf("dependencies did not match, wont %v, got %v", wontDeps, tree.Deps)
+	}
+	if len(tree.Dependencies()) != 2 {
+		t.Errorf("resolved dependency count did not match, wont 2, got %d", len(tree.Dependencies()))
+		return
+	}
+	validateGoDependencyTree(t, tree.Dependencies()[0], "github.com/tamadalab/liba@v1.0.0", "MIT", 2)
+	validateGoDependencyTree(t, tree.Dependencies()[0].Dependencies()[1], "github.com/tamadalab/libb@v0.2.0", "BSD-3-Clause", 0)
+	validateGoDependencyTree(t, tree.Dependencies()[1], "github.com/tamadalab/libb@v0.2.0", "BSD-3-Clause", 0)
+	if _, ok := parser.context.SearchCache("github.com/tamadalab/libb@v0.1.0"); ok {
+		t.Errorf("github.com/tamadalab/libb@v0.1.0 is not selected, but was found in the cache")
+	}
+}
+
+func TestFindGoModuleInLocalCache(t *testing.T) {
+	os.Setenv("GOMODCACHE", "testdata/gomodcache")
+	defer os.Unsetenv("GOMODCACHE")
+	parser := &goModParser{context: NewContext(true, "json", 2)}
+
+	project, err := parser.findGoModule(newGoModule("github.com/tamadalab/liba", "v1.0.0"), 1)
+	if err != nil {
+		t.Errorf("findGoModule failed: %s", err.Error())
+		return
+	}
+	validateGoDependencyTree(t, project, "github.com/tamadalab/liba@v1.0.0", "MIT", 2)
+	validateGoDependencyTree(t, project.Dependencies()[0], "github.com/Tamada/libc@v1.1.0", "ISC", 0)
+	validateGoDependencyTree(t, project.Dependencies()[1], "github.com/tamadalab/libb@v0.2.0", "BSD-3-Clause", 0)
+}
+
+func TestEscapeGoModulePath(t *testing.T) {
+	testdata := []struct {
+		givePath string
+		wontPath string
+	}{
+		{"github.com/tamadalab/purplecat", "github.com/tamadalab/purplecat"},
+		{"github.com/BurntSushi/toml", "github.com/!burnt!sushi/toml"},
+		{"v1.0.0-RC1", "v1.0.0-!r!c1"},
+	}
+	for _, td := range testdata {
+		gotPath := escapeGoModulePath(td.givePath)
+		if gotPath != td.wontPath {
+			t.Errorf("escapeGoModulePath(%s) did not match, wont %s, got %s", td.givePath, td.wontPath, gotPath)
+		}
+	}
+}
+
+func validateGoDependencyTree(t *testing.T, tree *Project, wontProjectName, wontSpdxID string, wontDependencyCount int) {
+	if tree.Name() != wontProjectName {
+		t.Errorf("project name did not match, wont %s, got %s", wontProjectName, tree.Name())
+	}
+	if len(tree.Dependencies()) != wontDependencyCount {
+		t.Errorf("%s: dependency count did not match, wont %d, got %d", wontProjectName, wontDependencyCount, len(tree.Dependencies()))
+	}
+	if len(tree.Licenses()) == 0 {
+		t.Errorf("%s: license count is 0", wontProjectName)
+		return
+	}
+	if tree.Licenses()[0].SpdxID != wontSpdxID {
+		t.Errorf("%s: license did not match, wont %s, got %s", wontProjectName, wontSpdxID, tree.Licenses()[0].SpdxID)
+	}
+}
+
+func TestParseGoVendor(t *testing.T) {
+	parser := &goModParser{context: NewContext(true, "json", 1)}
+
+	tree, err := parser.Parse(NewPath("testdata/govendorproject"))
+	if err != nil {
+		t.Errorf("testdata/govendorproject: parse failed: %s", err.Error())
+		return
+	}
+	validateGoDependencyTree(t, tree.Dependencies()[0], "github.com/tamadalab/liba@v1.0.0", "MIT", 0)
+	if len(tree.Dependencies()) != 3 {
+		t.Errorf("vendored module count did not match, wont 3, got %d", len(tree.Dependencies()))
+		return
+	}
+	validateGoDependencyTree(t, tree.Dependencies()[1], "github.com/tamadalab/libb@v0.2.0", "BSD-3-Clause", 0)
+	validateGoDependencyTree(t, tree.Dependencies()[2], "github.com/tamadalab/old@v0.1.0 => testdata/goworkspace/forked", "ISC", 0)
+}
+
+func TestParseGoSum(t *testing.T) {
+	os.Setenv("GOMODCACHE", "testdata/gomodcache")
+	defer os.Unsetenv("GOMODCACHE")
+	parser := &goModParser{context: NewContext(true, "json", 5)}
+
+	tree, err := parser.Parse(NewPath("testdata/gosumproject"))
+	if err != nil {
+		t.Errorf("testdata/gosumproject: parse failed: %s", err.Error())
+		return
+	}
+	if len(tree.Deps) != 1 || tree.Deps[0] != "github.com/tamadalab/missing@v1.0.0" {
+		t.Errorf("dependencies did not match, wont [github.com/tamadalab/missing@v1.0.0], got %v", tree.Deps)
+	}
+	if len(tree.UnreachedDeps) != 1 || tree.UnreachedDeps[0] != "github.com/tamadalab/libb@v0.2.0" {
+		t.Errorf("unreached dependencies did not match, wont [github.com/tamadalab/libb@v0.2.0], got %v", tree.UnreachedDeps)
+	}
+	if len(tree.Dependencies()) != 0 || len(tree.UnreachedDependencies()) != 1 {
+		t.Errorf("resolved dependency count did not match, wont 0 and 1 unreached, got %d and %d", len(tree.Dependencies()), len(tree.UnreachedDependencies()))
+		return
+	}
+	validateGoDependencyTree(t, tree.UnreachedDependencies()[0], "github.com/tamadalab/libb@v0.2.0", "BSD-3-Clause", 0)
+}
+
+func TestWriteUnreachedDependencies(t *testing.T) {
+	os.Setenv("GOMODCACHE", "testdata/gomodcache")
+	defer os.Unsetenv("GOMODCACHE")
+	testdata := []struct {
+		format string
+		wont   string
+	}{
+		{"markdown", "    * github.com/tamadalab/libb@v0.2.0 (unreached): [BSD 3-Clause \"New\" or \"Revised\" License]"},
+		{"csv", "github.com/tamadalab/libb@v0.2.0 (unreached),"},
+		{"json", `"unreached-dependencies":[{"project-name":"github.com/tamadalab/libb@v0.2.0"`},
+		{"yaml", "unreached-dependencies:"},
+		{"xml", "<unreached-dependency>"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, td.format, 5)
+		tree, err := (&goModParser{context: context}).Parse(NewPath("testdata/gosumproject"))
+		if err != nil {
+			t.Errorf("testdata/gosumproject: parse failed: %s", err.Error())
+			continue
+		}
+		out := &bytes.Buffer{}
+		writer, _ := context.NewWriter(out)
+		writer.Write(tree)
+		if !strings.Contains(out.String(), td.wont) {
+			t.Errorf("%s: output wont contain %s, got %s", td.format, td.wont, out.String())
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package purplecat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type goModule struct {
+	path     string
+	version  string
+	indirect bool
+	// dir is the local directory of the module, and it is set only for the directory replacements.
+	dir *Path
+	// replace is the replacement module of the receiver module by the replace directive.
+	replace *goModule
+}
+
+// goReplace represents the replace directive, e.g., `replace old v1.0.0 => new v1.1.0`.
+// The empty version of old means the replacement for all versions of the module.
+type goReplace struct {
+	old *goModule
+	new *goModule
+}
+
+type goModFile struct {
+	module   *goModule
+	requires []*goModule
+	replaces []*goReplace
+	excludes []*goModule
+}
+
+// goWorkFile represents go.work, the workspace file of the go command.
+type goWorkFile struct {
+	uses     []string
+	replaces []*goReplace
+}
+
+// goModHandler receives each directive of go.mod, e.g., verb: `require`, args: [`golang.org/x/net`, `v0.1.0`], comment: `indirect`.
+type goModHandler func(verb string, args []string, comment string) error
+
+func newGoModule(path, version string) *goModule {
+	return &goModule{path: path, version: version}
+}
+
+// Name returns the name of the module in the form of `path@version`.
+// If the version of the receiver module is empty, Name returns only the module path.
+// If the receiver module is replaced, Name returns `path@version => replacement`.
+// The name of the directory replacement is its resolved directory path.
+func (module *goModule) Name() string {
+	if module.dir != nil {
+		return module.dir.Path
+	}
+	name := module.path
+	if module.version != "" {
+		name = fmt.Sprintf("%s@%s", module.path, module.version)
+	}
+	if module.replace != nil {
+		return fmt.Sprintf("%s => %s", name, module.replace.Name())
+	}
+	return name
+}
+
+// target returns the module for finding its go.mod and licenses, that is, the replacement module if replaced.
+func (module *goModule) target() *goModule {
+	if module.replace != nil {
+		return module.replace
+	}
+	return module
+}
+
+func (replace *goReplace) match(module *goModule) bool {
+	return replace.old.path == module.path && (replace.old.version == "" || replace.old.version == module.version)
+}
+
+func parseGoModContent(reader io.Reader) (*goModFile, error) {
+	modFile := &goModFile{requires: []*goModule{}, replaces: []*goReplace{}, excludes: []*goModule{}}
+	err := scanGoModDirectives(reader, func(verb string, args []string, comment string) error {
+		switch verb {
+		case "module":
+			if len(args) != 1 {
+				return fmt.Errorf("usage: module module/path")
+			}
+			modFile.module = newGoModule(args[0], "")
+		case "require":
+			if len(args) != 2 {
+				return fmt.Errorf("usage: require module/path v1.2.3")
+			}
+			require := newGoModule(args[0], args[1])
+			require.indirect = isIndirectComment(comment)
+			modFile.requires = append(modFile.requires, require)
+		case "exclude":
+			if len(args) != 2 {
+				return fmt.Errorf("usage: exclude module/path v1.2.3")
+			}
+			modFile.excludes = append(modFile.excludes, newGoModule(args[0], args[1]))
+		case "replace":
+			replace, err := parseGoReplace(args)
+			if err != nil {
+				return err
+			}
+			modFile.replaces = append(modFile.replaces, replace)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if modFile.module == nil {
+		return nil, fmt.Errorf("no module directive found")
+	}
+	return modFile, nil
+}
+
+func parseGoWorkContent(reader io.Reader) (*goWorkFile, error) {
+	workFile := &goWorkFile{uses: []string{}, replaces: []*goReplace{}}
+	err := scanGoModDirectives(reader, func(verb string, args []string, comment string) error {
+		switch verb {
+		case "use":
+			if len(args) != 1 {
+				return fmt.Errorf("usage: use local/dir")
+			}
+			workFile.uses = append(workFile.uses, args[0])
+		case "replace":
+			replace, err := parseGoReplace(args)
+			if err != nil {
+				return err
+			}
+			workFile.replaces = append(workFile.replaces, replace)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workFile, nil
+}
+
+func parseGoReplace(args []string) (*goReplace, error) {
+	arrow := -1
+	for i, arg := range args {
+		if arg == "=>" {
+			arrow = i
+		}
+	}
+	if arrow < 1 || arrow > 2 || len(args)-arrow-1 < 1 || len(args)-arrow-1 > 2 {
+		return nil, fmt.Errorf("usage: replace module/path [v1.2.3] => other/module v1.4\n\t or replace module/path [v1.2.3] => ../local/directory")
+	}
+	replace := &goReplace{old: newGoModule(args[0], ""), new: newGoModule(args[arrow+1], "")}
+	if arrow == 2 {
+		replace.old.version = args[1]
+	}
+	if len(args)-arrow-1 == 2 {
+		replace.new.version = args[arrow+2]
+	} else if !isGoLocalDirPath(replace.new.path) {
+		return nil, fmt.Errorf("%s: replacement module without version must be directory path (rooted or starting with ./ or ../)", replace.new.path)
+	}
+	return replace, nil
+}
+
+// isGoLocalDirPath returns true if the given path in the replace directive shows the local directory.
+func isGoLocalDirPath(path string) bool {
+	return strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || strings.HasPrefix(path, "/") ||
+		strings.HasPrefix(path, `.\`) || strings.HasPrefix(path, `..\`) || (len(path) >= 3 && path[1] == ':')
+}
+
+func isIndirectComment(comment string) bool {
+	return comment == "indirect" || strings.HasPrefix(comment, "indirect;")
+}
+
+// scanGoModDirectives reads the go.mod syntax from the given reader and calls handler for each directive.
+// The directives in the blocks (e.g., `require ( ... )`) are passed to handler with the verb of the block.
+func scanGoModDirectives(reader io.Reader, handler goModHandler) error {
+	scanner := bufio.NewScanner(reader)
+	block := ""
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line, comment := splitGoModComment(scanner.Text())
+		tokens, err := tokenizeGoModLine(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %s", lineNumber, err.Error())
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		if err := dispatchGoModTokens(tokens, comment, &block, handler); err != nil {
+			return fmt.Errorf("line %d: %s", lineNumber, err.Error())
+		}
+	}
+	return scanner.Err()
+}
+
+func dispatchGoModTokens(tokens []string, comment string, block *string, handler goModHandler) error {
+	if *block != "" {
+		if tokens[0] == ")" {
+			*block = ""
+			return nil
+		}
+		return handler(*block, tokens, comment)
+	}
+	if len(tokens) >= 2 && tokens[1] == "(" {
+		if len(tokens) == 2 {
+			*block = tokens[0]
+		}
+		return nil
+	}
+	return handler(tokens[0], tokens[1:], comment)
+}
+
+func splitGoModComment(line string) (string, string) {
+	inQuote := rune(0)
+	for i, c := range line {
+		switch {
+		case inQuote != 0 && c == inQuote:
+			inQuote = 0
+		case inQuote == 0 && (c == '"' || c == '`'):
+			inQuote = c
+		case inQuote == 0 && strings.HasPrefix(line[i:], "//"):
+			return line[:i], strings.TrimSpace(line[i+2:])
+		}
+	}
+	return line, ""
+}
+
+func tokenizeGoModLine(line string) ([]string, error) {
+	tokens := []string{}
+	for _, field := range splitGoModFields(line) {
+		if strings.HasPrefix(field, `"`) || strings.HasPrefix(field, "`") {
+			unquoted, err := strconv.Unquote(field)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid quoted string", field)
+			}
+			field = unquoted
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens, nil
+}
+
+func splitGoModFields(line string) []string {
+	fields := []string{}
+	current := []rune{}
+	inQuote := rune(0)
+	for _, c := range line {
+		switch {
+		case inQuote != 0:
+			current = append(current, c)
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '`':
+			inQuote = c
+			current = append(current, c)
+		case c == ' ' || c == '\t' || c == '\r':
+			if len(current) > 0 {
+				fields = append(fields, string(current))
+				current = []rune{}
+			}
+		default:
+			current = append(current, c)
+		}
+	}
+	if len(current) > 0 {
+		fields = append(fields, string(current))
+	}
+	return fields
+}
+
+// parseGoVendorModules parses vendor/modules.txt, and returns the vendored modules.
+// The modules without any vendored packages are ignored, since they are not built.
+// The modules not marked as `## explicit` are treated as the indirect requirements.
+func parseGoVendorModules(reader io.Reader) ([]*goModule, error) {
+	modules := []*goModule{}
+	var current *goModule
+	vendored := false
+	flush := func() {
+		if current != nil && vendored {
+			modules = append(modules, current)
+		}
+	}
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if current != nil && isExplicitVendorAnnotation(line) {
+				current.indirect = false
+			}
+		case strings.HasPrefix(line, "# "):
+			flush()
+			module, err := parseGoVendorModuleLine(strings.Fields(line[2:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNumber, err.Error())
+			}
+			current, vendored = module, false
+		case line != "":
+			vendored = true
+		}
+	}
+	flush()
+	return modules, scanner.Err()
+}
+
+func isExplicitVendorAnnotation(line string) bool {
+	for _, annotation := range strings.Split(strings.TrimPrefix(line, "## "), ";") {
+		if strings.TrimSpace(annotation) == "explicit" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGoVendorModuleLine parses the module line in vendor/modules.txt,
+// e.g., `path version`, `path version => new version`, or `path => ../dir`.
+func parseGoVendorModuleLine(fields []string) (*goModule, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("module line is empty")
+	}
+	for i, field := range fields {
+		if field == "=>" {
+			replace, err := parseGoReplace(fields)
+			if err != nil {
+				return nil, err
+			}
+			module := newGoModule(fields[0], "")
+			if i == 2 {
+				module.version = fields[1]
+			}
+			module.replace = replace.new
+			module.indirect = true
+			return module, nil
+		}
+	}
+	module := newGoModule(fields[0], "")
+	if len(fields) > 1 {
+		module.version = fields[1]
+	}
+	module.indirect = true
+	return module, nil
+}
+
+// parseGoSum parses go.sum, and returns the modules whose contents are required for the build.
+// The entries only for go.mod (`path version/go.mod hash`) are ignored,
+// and the latest version is selected if the multiple versions of the same module are listed.
+func parseGoSum(reader io.Reader) ([]*goModule, error) {
+	modules := []*goModule{}
+	indexes := map[string]int{}
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: malformed go.sum entry", lineNumber)
+		}
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		if index, ok := indexes[fields[0]]; ok {
+			if compareGoVersion(fields[1], modules[index].version) > 0 {
+				modules[index].version = fields[1]
+			}
+			continue
+		}
+		indexes[fields[0]] = len(modules)
+		module := newGoModule(fields[0], fields[1])
+		module.indirect = true
+		modules = append(modules, module)
+	}
+	return modules, scanner.Err()
+}
@@ -0,0 +1,128 @@
+package purplecat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGoModContent(t *testing.T) {
+	testdata := []struct {
+		content      string
+		successFlag  bool
+		wontModule   string
+		wontRequires []string
+		wontIndirect []bool
+	}{
+		{"module example.com/a\nrequire example.com/b v1.0.0\n", true, "example.com/a", []string{"example.com/b@v1.0.0"}, []bool{false}},
+		{"module \"example.com/a\" // comment\nrequire (\n\texample.com/b v1.0.0 // indirect\n\t\"example.com/c\" v0.1.0\n)\n", true, "example.com/a", []string{"example.com/b@v1.0.0", "example.com/c@v0.1.0"}, []bool{true, false}},
+		{"go 1.15\nrequire ()\n", false, "", []string{}, []bool{}},
+		{"module example.com/a\nrequire example.com/b\n", false, "", []string{}, []bool{}},
+	}
+	for _, td := range testdata {
+		modFile, err := parseGoModContent(strings.NewReader(td.content))
+		if (err == nil) != td.successFlag {
+			t.Errorf("parseGoModContent(%q) wont success %v, got %v", td.content, td.successFlag, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if modFile.module.Name() != td.wontModule {
+			t.Errorf("module name did not match, wont %s, got %s", td.wontModule, modFile.module.Name())
+		}
+		if len(modFile.requires) != len(td.wontRequires) {
+			t.Errorf("require count did not match, wont %d, got %d", len(td.wontRequires), len(modFile.requires))
+			continue
+		}
+		for i, require := range modFile.requires {
+			if require.Name() != td.wontRequires[i] || require.indirect != td.wontIndirect[i] {
+				t.Errorf("require[%d] did not match, wont %s (indirect: %v), got %s (indirect: %v)", i, td.wontRequires[i], td.wontIndirect[i], require.Name(), require.indirect)
+			}
+		}
+	}
+}
+
+func TestParseGoReplace(t *testing.T) {
+	testdata := []struct {
+		args        string
+		successFlag bool
+		wontOld     string
+		wontNew     string
+	}{
+		{"example.com/a => example.com/b v1.0.0", true, "example.com/a", "example.com/b@v1.0.0"},
+		{"example.com/a v0.1.0 => example.com/b v1.0.0", true, "example.com/a@v0.1.0", "example.com/b@v1.0.0"},
+		{"example.com/a v0.1.0 => ../a", true, "example.com/a@v0.1.0", "../a"},
+		{"example.com/a => example.com/b", false, "", ""},
+		{"example.com/a v0.1.0", false, "", ""},
+	}
+	for _, td := range testdata {
+		replace, err := parseGoReplace(strings.Fields(td.args))
+		if (err == nil) != td.successFlag {
+			t.Errorf("parseGoReplace(%s) wont success %v, got %v", td.args, td.successFlag, err)
+			continue
+		}
+		if err == nil && (replace.old.Name() != td.wontOld || replace.new.Name() != td.wontNew) {
+			t.Errorf("parseGoReplace(%s) did not match, wont %s => %s, got %s => %s", td.args, td.wontOld, td.wontNew, replace.old.Name(), replace.new.Name())
+		}
+	}
+}
+
+func TestParseGoWorkContent(t *testing.T) {
+	content := "go 1.18\n\nuse (\n\t./app\n\t./lib\n)\n\nreplace example.com/a => ./a\n"
+	workFile, err := parseGoWorkContent(strings.NewReader(content))
+	if err != nil {
+		t.Errorf("parseGoWorkContent failed: %s", err.Error())
+		return
+	}
+	if len(workFile.uses) != 2 || workFile.uses[0] != "./app" || workFile.uses[1] != "./lib" {
+		t.Errorf("uses did not match, wont [./app ./lib], got %v", workFile.uses)
+	}
+	if len(workFile.replaces) != 1 || workFile.replaces[0].new.path != "./a" {
+		t.Errorf("replaces did not match, wont 1 replace to ./a, got %d", len(workFile.replaces))
+	}
+}
+
+func TestParseGoVendorModules(t *testing.T) {
+	content := `# example.com/a v1.0.0
+## explicit; go 1.14
+example.com/a
+# example.com/b v0.1.0
+# example.com/c v0.2.0 => example.com/d v0.3.0
+example.com/c/sub
+`
+	modules, err := parseGoVendorModules(strings.NewReader(content))
+	if err != nil {
+		t.Errorf("parseGoVendorModules failed: %s", err.Error())
+		return
+	}
+	wontNames := []string{"example.com/a@v1.0.0", "example.com/c@v0.2.0 => example.com/d@v0.3.0"}
+	wontIndirects := []bool{false, true}
+	if len(modules) != len(wontNames) {
+		t.Errorf("module count did not match, wont %d, got %d", len(wontNames), len(modules))
+		return
+	}
+	for i, module := range modules {
+		if module.Name() != wontNames[i] || module.indirect != wontIndirects[i] {
+			t.Errorf("module[%d] did not match, wont %s (indirect: %v), got %s (indirect: %v)", i, wontNames[i], wontIndirects[i], module.Name(), module.indirect)
+		}
+	}
+}
+
+func TestParseGoSumContent(t *testing.T) {
+	content := `example.com/a v1.0.0 h1:aaa=
+example.com/a v1.0.0/go.mod h1:aaa=
+example.com/b v0.1.0/go.mod h1:bbb=
+example.com/a v1.2.0 h1:ccc=
+`
+	modules, err := parseGoSum(strings.NewReader(content))
+	if err != nil {
+		t.Errorf("parseGoSum failed: %s", err.Error())
+		return
+	}
+	if len(modules) != 1 || modules[0].Name() != "example.com/a@v1.2.0" {
+		t.Errorf("parseGoSum did not match, wont [example.com/a@v1.2.0], got %d modules", len(modules))
+	}
+	if _, err := parseGoSum(strings.NewReader("example.com/a v1.0.0\n")); err == nil {
+		t.Errorf("parseGoSum for malformed line wont error, but got nil")
+	}
+}
@@ -0,0 +1,375 @@
+package purplecat
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// defaultGoProxy is the default value of GOPROXY environment variable of the go command.
+const defaultGoProxy = "https://proxy.golang.org,direct"
+
+// goProxy represents the one of the module proxies listed in GOPROXY environment.
+type goProxy struct {
+	url string
+	// fallbackAnyError shows the proxy is separated by `|`, that is, the next proxy is tried on any errors.
+	// Otherwise (separated by `,`), the next proxy is tried only on 404 (Not Found) or 410 (Gone).
+	fallbackAnyError bool
+}
+
+// parseGoProxies parses the given GOPROXY value, and returns the list of proxies.
+// The special values `direct` and `off` are stopped the list, since purplecat does not access the version control systems.
+func parseGoProxies(value string) []*goProxy {
+	if value == "" {
+		value = defaultGoProxy
+	}
+	proxies := []*goProxy{}
+	for value != "" {
+		index := strings.IndexAny(value, ",|")
+		item, fallbackAnyError := value, false
+		if index >= 0 {
+			item, fallbackAnyError = value[:index], value[index] == '|'
+			value = value[index+1:]
+		} else {
+			value = ""
+		}
+		item = strings.TrimSpace(item)
+		if item == "direct" || item == "off" {
+			break
+		}
+		if item != "" {
+			proxies = append(proxies, &goProxy{url: strings.TrimSuffix(item, "/"), fallbackAnyError: fallbackAnyError})
+		}
+	}
+	return proxies
+}
+
+// goProxies returns the list of module proxies for the given module path.
+// The download cache in the local module cache is always the first one, since it has the same layout as the module proxy.
+// The private modules (matched GONOPROXY or GOPRIVATE) are looked up only in the download cache, since they are never fetched via the proxies.
+func goProxies(modulePath string) []*goProxy {
+	localCache := &goProxy{url: filepath.Join(localGoModCacheDir(), "cache", "download"), fallbackAnyError: true}
+	if isGoPrivateModule(modulePath) {
+		logger.Infof("%s: private module (matched GONOPROXY or GOPRIVATE), the module proxies are skipped", modulePath)
+		return []*goProxy{localCache}
+	}
+	return append([]*goProxy{localCache}, parseGoProxies(os.Getenv("GOPROXY"))...)
+}
+
+// isGoPrivateModule returns true if the given module path matches GONOPROXY (or GOPRIVATE if GONOPROXY is not set).
+func isGoPrivateModule(modulePath string) bool {
+	patterns := os.Getenv("GONOPROXY")
+	if patterns == "" {
+		patterns = os.Getenv("GOPRIVATE")
+	}
+	return matchGoModulePatterns(patterns, modulePath)
+}
+
+// matchGoModulePatterns reports whether any path prefix of the given module path matches one of the glob patterns
+// in the given comma-separated list, in the same manner as GOPRIVATE of the go command.
+func matchGoModulePatterns(patterns, modulePath string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSuffix(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+		count := strings.Count(pattern, "/")
+		elements := strings.Split(modulePath, "/")
+		if len(elements) <= count {
+			continue
+		}
+		prefix := strings.Join(elements[:count+1], "/")
+		if matched, _ := path.Match(pattern, prefix); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (proxy *goProxy) path(module *goModule, suffix string) *Path {
+	location := fmt.Sprintf("%s/%s/@v/%s", proxy.url, escapeGoModulePath(module.path), suffix)
+	if strings.HasPrefix(location, "file://") {
+		return NewPath(filepath.FromSlash(strings.TrimPrefix(location, "file://")))
+	}
+	return NewPath(location)
+}
+
+func (proxy *goProxy) read(path *Path, context *Context) ([]byte, error) {
+	reader, err := path.Open(context)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// versions returns the known versions of the given module by `$GOPROXY/<module>/@v/list`.
+func (proxy *goProxy) versions(module *goModule, context *Context) ([]string, error) {
+	data, err := proxy.read(proxy.path(module, "list"), context)
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			versions = append(versions, fields[0])
+		}
+	}
+	return versions, scanner.Err()
+}
+
+// goMod returns go.mod of the given module by `$GOPROXY/<module>/@v/<version>.mod`.
+func (proxy *goProxy) goMod(module *goModule, context *Context) (*goModFile, error) {
+	return readGoMod(proxy.path(module, escapeGoModulePath(module.version)+".mod"), context)
+}
+
+// licenses returns the licenses of the given module from the license files in `$GOPROXY/<module>/@v/<version>.zip`.
+func (proxy *goProxy) licenses(module *goModule, context *Context) (Licenses, error) {
+	data, err := proxy.read(proxy.path(module, escapeGoModulePath(module.version)+".zip"), context)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	return findLicensesInGoModuleZip(archive, module), nil
+}
+
+func findLicensesInGoModuleZip(archive *zip.Reader, module *goModule) Licenses {
+	prefix := module.Name() + "/"
+	licenses := Licenses{}
+	found := false
+	for _, file := range archive.File {
+		if !strings.HasPrefix(file.Name, prefix) || !isLicenseFileName(strings.TrimPrefix(file.Name, prefix)) {
+			continue
+		}
+		found = true
+		if license, ok := readLicenseInZip(file); ok {
+			licenses = appendLicenseIfAbsent(licenses, license)
+		}
+	}
+	if found && len(licenses) == 0 {
+		return Licenses{UnknownLicense}
+	}
+	return licenses
+}
+
+func readLicenseInZip(file *zip.File) (*License, bool) {
+	reader, err := file.Open()
+	if err != nil {
+		return UnknownLicense, false
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return UnknownLicense, false
+	}
+	return classifyLicense(string(data))
+}
+
+// resolveVersion determines the version of the given module.
+// If the version of the given module is empty, this function returns the latest release version in the list.
+func (proxy *goProxy) resolveVersion(module *goModule, context *Context) (*goModule, error) {
+	if module.version != "" {
+		return module, nil
+	}
+	versions, err := proxy.versions(module, context)
+	if err != nil {
+		return nil, err
+	}
+	latest := latestGoVersion(versions)
+	if latest == "" {
+		return nil, fmt.Errorf("%s: no versions found", module.path)
+	}
+	return newGoModule(module.path, latest), nil
+}
+
+func (proxy *goProxy) find(module *goModule, gmp *goModParser, currentDepth int) (*Project, error) {
+	target, err := proxy.resolveVersion(module.target(), gmp.context)
+	if err != nil {
+		return nil, err
+	}
+	modFile, ok := gmp.modFiles[target.Name()]
+	if !ok {
+		modFile, err = proxy.goMod(target, gmp.context)
+		if err != nil {
+			return nil, err
+		}
+	}
+	licenses, err := proxy.licenses(target, gmp.context)
+	if err != nil {
+		return nil, err
+	}
+	if module.replace == nil {
+		module = target
+	}
+	return gmp.constructGoProject(module, modFile, licenses, currentDepth)
+}
+
+func (proxy *goProxy) canFallback(err error) bool {
+	if proxy.fallbackAnyError {
+		return true
+	}
+	var statusErr *HTTPStatusError
+	return os.IsNotExist(err) || (errors.As(err, &statusErr) && statusErr.IsNotFound())
+}
+
+// findGoModuleViaGoProxy finds the given module through the module proxy protocol.
+func (gmp *goModParser) findGoModuleViaGoProxy(module *goModule, currentDepth int) (*Project, error) {
+	logger.Infof("findGoModuleViaGoProxy(%s, %d)", module.Name(), currentDepth)
+	var lastErr = fmt.Errorf("%s: no module proxies available", module.Name())
+	for _, proxy := range goProxies(module.target().path) {
+		project, err := proxy.find(module, gmp, currentDepth)
+		if err == nil {
+			return project, nil
+		}
+		logger.Debugf("%s: %s", proxy.url, err.Error())
+		lastErr = err
+		if !proxy.canFallback(err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// latestGoVersion returns the latest release version in the given versions.
+// If no release versions are given, this function returns the latest pre-release version.
+func latestGoVersion(versions []string) string {
+	latest, latestPre := "", ""
+	for _, version := range versions {
+		if !isValidGoVersion(version) {
+			continue
+		}
+		if goVersionPrerelease(version) == "" && (latest == "" || compareGoVersion(version, latest) > 0) {
+			latest = version
+		}
+		if latestPre == "" || compareGoVersion(version, latestPre) > 0 {
+			latestPre = version
+		}
+	}
+	if latest != "" {
+		return latest
+	}
+	return latestPre
+}
+
+type goVersion struct {
+	numbers    []int
+	prerelease string
+}
+
+func parseGoVersion(version string) (*goVersion, bool) {
+	if !strings.HasPrefix(version, "v") {
+		return nil, false
+	}
+	version = strings.TrimPrefix(version, "v")
+	if index := strings.Index(version, "+"); index >= 0 {
+		version = version[:index]
+	}
+	prerelease := ""
+	if index := strings.Index(version, "-"); index >= 0 {
+		version, prerelease = version[:index], version[index+1:]
+	}
+	numbers := []int{}
+	for _, item := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, false
+		}
+		numbers = append(numbers, number)
+	}
+	if len(numbers) == 0 || len(numbers) > 3 {
+		return nil, false
+	}
+	for len(numbers) < 3 {
+		numbers = append(numbers, 0)
+	}
+	return &goVersion{numbers: numbers, prerelease: prerelease}, true
+}
+
+func isValidGoVersion(version string) bool {
+	_, ok := parseGoVersion(version)
+	return ok
+}
+
+func goVersionPrerelease(version string) string {
+	if v, ok := parseGoVersion(version); ok {
+		return v.prerelease
+	}
+	return ""
+}
+
+// compareGoVersion compares the given two semantic versions, and returns -1, 0, or 1.
+// The invalid versions are considered smaller than all valid versions.
+func compareGoVersion(v1, v2 string) int {
+	version1, ok1 := parseGoVersion(v1)
+	version2, ok2 := parseGoVersion(v2)
+	switch {
+	case !ok1 && !ok2:
+		return strings.Compare(v1, v2)
+	case !ok1:
+		return -1
+	case !ok2:
+		return 1
+	}
+	for i := range version1.numbers {
+		if result := compareInt(version1.numbers[i], version2.numbers[i]); result != 0 {
+			return result
+		}
+	}
+	return comparePrerelease(version1.prerelease, version2.prerelease)
+}
+
+func comparePrerelease(pre1, pre2 string) int {
+	if pre1 == pre2 {
+		return 0
+	}
+	if pre1 == "" {
+		return 1
+	}
+	if pre2 == "" {
+		return -1
+	}
+	items1, items2 := strings.Split(pre1, "."), strings.Split(pre2, ".")
+	for i := 0; i < len(items1) && i < len(items2); i++ {
+		if result := comparePrereleaseItem(items1[i], items2[i]); result != 0 {
+			return result
+		}
+	}
+	return compareInt(len(items1), len(items2))
+}
+
+func comparePrereleaseItem(item1, item2 string) int {
+	number1, err1 := strconv.Atoi(item1)
+	number2, err2 := strconv.Atoi(item2)
+	switch {
+	case err1 == nil && err2 == nil:
+		return compareInt(number1, number2)
+	case err1 == nil:
+		return -1
+	case err2 == nil:
+		return 1
+	}
+	return strings.Compare(item1, item2)
+}
+
+func compareInt(i1, i2 int) int {
+	if i1 < i2 {
+		return -1
+	} else if i1 > i2 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,194 @@
+package purplecat
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newGoProxyServer serves the modules in testdata/goproxy by the module proxy protocol.
+// The zip files are created from the directories named by the version (e.g., `@v/v1.0.0/` for `@v/v1.0.0.zip`).
+func newGoProxyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Join("testdata/goproxy", filepath.FromSlash(r.URL.Path))
+		if !strings.HasSuffix(name, ".zip") {
+			http.ServeFile(w, r, name)
+			return
+		}
+		items := strings.SplitN(strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, ".zip"), "/"), "/@v/", 2)
+		if err := writeGoModuleZip(w, strings.TrimSuffix(name, ".zip"), items[0]+"@"+items[1]); err != nil {
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func writeGoModuleZip(w io.Writer, dir, prefix string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	archive := zip.NewWriter(w)
+	defer archive.Close()
+	for _, info := range infos {
+		data, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return err
+		}
+		writer, err := archive.Create(prefix + "/" + info.Name())
+		if err != nil {
+			return err
+		}
+		writer.Write(data)
+	}
+	return nil
+}
+
+func TestFindGoModuleViaGoProxy(t *testing.T) {
+	server := newGoProxyServer()
+	defer server.Close()
+	defer setEnv(map[string]string{"GOPROXY": server.URL, "GOMODCACHE": "testdata/gomodcache"})()
+
+	testdata := []struct {
+		module       *goModule
+		denyNetwork  bool
+		goPrivate    string
+		successFlag  bool
+		wontName     string
+		wontSpdxID   string
+		wontDepCount int
+	}{
+		{newGoModule("github.com/tamadalab/libd", "v1.0.0"), false, "", true, "github.com/tamadalab/libd@v1.0.0", "MIT", 1},
+		{newGoModule("github.com/tamadalab/libe", ""), false, "", true, "github.com/tamadalab/libe@v0.1.0", "Unlicense", 0},
+		{newGoModule("github.com/tamadalab/libd", "v1.0.0"), true, "", false, "", "", 0},
+		{newGoModule("github.com/tamadalab/libd", "v1.0.0"), false, "github.com/tamadalab/*", false, "", "", 0},
+		{newGoModule("github.com/tamadalab/unknown", "v1.0.0"), false, "", false, "", "", 0},
+	}
+	for _, td := range testdata {
+		os.Setenv("GOPRIVATE", td.goPrivate)
+		parser := &goModParser{context: NewContext(td.denyNetwork, "json", 2)}
+		project, err := parser.findGoModuleViaGoProxy(td.module, 1)
+		if (err == nil) != td.successFlag {
+			t.Errorf("findGoModuleViaGoProxy(%s) wont success %v, got %v", td.module.Name(), td.successFlag, err)
+			continue
+		}
+		if err == nil {
+			validateGoDependencyTree(t, project, td.wontName, td.wontSpdxID, td.wontDepCount)
+		}
+	}
+	os.Unsetenv("GOPRIVATE")
+}
+
+func TestFindPrivateGoModuleInDownloadCache(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	cacheDir, err := ioutil.TempDir("", "gomodcache")
+	if err != nil {
+		t.Errorf("TempDir failed: %s", err.Error())
+		return
+	}
+	defer os.RemoveAll(cacheDir)
+	downloadDir := filepath.Join(cacheDir, "cache", "download", "github.com", "tamadalab", "libd", "@v")
+	os.MkdirAll(downloadDir, 0755)
+	data, _ := ioutil.ReadFile("testdata/goproxy/github.com/tamadalab/libd/@v/v1.0.0.mod")
+	ioutil.WriteFile(filepath.Join(downloadDir, "v1.0.0.mod"), data, 0644)
+	file, _ := os.Create(filepath.Join(downloadDir, "v1.0.0.zip"))
+	writeGoModuleZip(file, "testdata/goproxy/github.com/tamadalab/libd/@v/v1.0.0", "github.com/tamadalab/libd@v1.0.0")
+	file.Close()
+	defer setEnv(map[string]string{"GOPROXY": server.URL, "GOMODCACHE": cacheDir, "GOPRIVATE": "github.com/tamadalab/*"})()
+
+	testdata := []struct {
+		module      *goModule
+		successFlag bool
+	}{
+		{newGoModule("github.com/tamadalab/libd", "v1.0.0"), true},
+		{newGoModule("github.com/tamadalab/libe", "v0.1.0"), false},
+	}
+	for _, td := range testdata {
+		parser := &goModParser{context: NewContext(false, "json", 1)}
+		project, err := parser.findGoModuleViaGoProxy(td.module, 1)
+		if (err == nil) != td.successFlag {
+			t.Errorf("findGoModuleViaGoProxy(%s) wont success %v, got %v", td.module.Name(), td.successFlag, err)
+			continue
+		}
+		if err == nil {
+			validateGoDependencyTree(t, project, "github.com/tamadalab/libd@v1.0.0", "MIT", 0)
+		}
+	}
+	if requested {
+		t.Errorf("the private modules wont be requested to the module proxy")
+	}
+}
+
+func TestParseGoProxies(t *testing.T) {
+	testdata := []struct {
+		value    string
+		wontURLs []string
+		wontAny  []bool
+	}{
+		{"", []string{"https://proxy.golang.org"}, []bool{false}},
+		{"https://a.example.com/,https://b.example.com|https://c.example.com,direct,https://d.example.com", []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"}, []bool{false, true, false}},
+		{"off", []string{}, []bool{}},
+	}
+	for _, td := range testdata {
+		proxies := parseGoProxies(td.value)
+		if len(proxies) != len(td.wontURLs) {
+			t.Errorf("parseGoProxies(%s) length did not match, wont %d, got %d", td.value, len(td.wontURLs), len(proxies))
+			continue
+		}
+		for i, proxy := range proxies {
+			if proxy.url != td.wontURLs[i] || proxy.fallbackAnyError != td.wontAny[i] {
+				t.Errorf("parseGoProxies(%s)[%d] did not match, wont %s (%v), got %s (%v)", td.value, i, td.wontURLs[i], td.wontAny[i], proxy.url, proxy.fallbackAnyError)
+			}
+		}
+	}
+}
+
+func TestMatchGoModulePatterns(t *testing.T) {
+	testdata := []struct {
+		patterns   string
+		modulePath string
+		wontMatch  bool
+	}{
+		{"github.com/tamadalab", "github.com/tamadalab/purplecat", true},
+		{"*.corp.example.com,rsc.io/private", "git.corp.example.com/xyzzy", true},
+		{"*.corp.example.com,rsc.io/private", "rsc.io/private/quux", true},
+		{"*.corp.example.com,rsc.io/private", "rsc.io/public", false},
+		{"", "github.com/tamadalab/purplecat", false},
+	}
+	for _, td := range testdata {
+		if got := matchGoModulePatterns(td.patterns, td.modulePath); got != td.wontMatch {
+			t.Errorf("matchGoModulePatterns(%s, %s) did not match, wont %v, got %v", td.patterns, td.modulePath, td.wontMatch, got)
+		}
+	}
+}
+
+func TestCompareGoVersion(t *testing.T) {
+	testdata := []struct {
+		v1, v2     string
+		wontResult int
+	}{
+		{"v1.0.0", "v1.0.0", 0},
+		{"v1.0.0", "v1.0.1", -1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"v1.0.0-rc.2", "v1.0.0-rc.10", -1},
+		{"v0.0.0-20200907205600-7a23bdc65eef", "v0.0.0-20201031054903-ff519b6c9102", -1},
+		{"v2.0.0+incompatible", "v1.9.9", 1},
+		{"invalid", "v0.0.1", -1},
+	}
+	for _, td := range testdata {
+		if got := compareGoVersion(td.v1, td.v2); got != td.wontResult {
+			t.Errorf("compareGoVersion(%s, %s) did not match, wont %d, got %d", td.v1, td.v2, td.wontResult, got)
+		}
+	}
+}
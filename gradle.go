@@ -0,0 +1,365 @@
+package purplecat
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// gradleParser is the instance of Parser for parsing build.gradle and build.gradle.kts.
+type gradleParser struct {
+	context *Context
+}
+
+var gradleBuildFileNames = []string{"build.gradle", "build.gradle.kts"}
+
+// gradleConfigurationSuffixes is the suffixes of the configurations declaring the dependencies, e.g., `debugImplementation`.
+var gradleConfigurationSuffixes = []string{
+	"implementation", "Implementation", "api", "Api", "compileOnly", "CompileOnly",
+	"runtimeOnly", "RuntimeOnly", "compile", "Compile", "runtime", "Runtime",
+	"annotationProcessor", "AnnotationProcessor", "kapt", "Kapt",
+}
+
+var (
+	gradleStringNotation = regexp.MustCompile(`^([A-Za-z]\w*)\s*\(?\s*['"]([^'"]+)['"]`)
+	gradleMapNotation    = regexp.MustCompile(`^([A-Za-z]\w*)\s*\(?\s*(group\s*[:=].*)$`)
+	gradleKotlinNotation = regexp.MustCompile(`^([A-Za-z]\w*)\s*\(\s*kotlin\(\s*"([^"]+)"(?:\s*,\s*"([^"]+)")?\s*\)`)
+	gradleKeyValue       = regexp.MustCompile(`(\w+)\s*[:=]\s*['"]([^'"]*)['"]`)
+	gradleVariable       = regexp.MustCompile(`(?m)^\s*(?:(?:def|val|var)\s+|(?:project\.)?ext\.)(\w+)\s*=\s*['"]([^'"]*)['"]`)
+	gradleKotlinExtra    = regexp.MustCompile(`(?m)^\s*(?:val\s+(\w+)\s+by\s+extra\(\s*"([^"]*)"\s*\)|extra\[\s*"(\w+)"\s*\]\s*=\s*"([^"]*)")`)
+	gradleProjectValue   = regexp.MustCompile(`(?m)^\s*(group|version)\s*=\s*['"]([^'"]*)['"]`)
+	gradleRootName       = regexp.MustCompile(`rootProject\.name\s*=\s*['"]([^'"]*)['"]`)
+	gradlePropReference  = regexp.MustCompile(`\$\{?((?:rootProject\.|project\.)?(?:ext\.)?)([\w.]+)\}?`)
+)
+
+func isGradleBuildFile(name string) bool {
+	for _, fileName := range gradleBuildFileNames {
+		if name == fileName {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTarget returns true if the project located on the given path is gradle project.
+func (gp *gradleParser) IsTarget(path *Path, context *Context) bool {
+	if isGradleBuildFile(path.Base()) {
+		return path.Exists(context)
+	}
+	_, ok := findGradleBuildFile(path, context)
+	return ok
+}
+
+func findGradleBuildFile(dir *Path, context *Context) (*Path, bool) {
+	for _, name := range gradleBuildFileNames {
+		if path := dir.Join(name); path.Exists(context) {
+			return path, true
+		}
+	}
+	return nil, false
+}
+
+// Parse parses the given path as build.gradle (or build.gradle.kts) and returns the instance of Project.
+func (gp *gradleParser) Parse(path *Path) (*Project, error) {
+	if !isGradleBuildFile(path.Base()) {
+		buildFile, ok := findGradleBuildFile(path, gp.context)
+		if !ok {
+			return nil, fmt.Errorf("%s: not gradle project (build.gradle not found)", path.Path)
+		}
+		path = buildFile
+	}
+	return parseGradle(path, gp.context, 0)
+}
+
+func readText(path *Path, context *Context) (string, error) {
+	reader, err := path.Open(context)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func parseGradle(path *Path, context *Context, currentDepth int) (*Project, error) {
+	if context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", context.Depth, currentDepth)
+	}
+	logger.Infof("parseGradle(%s, %d)", path.Path, currentDepth)
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := readGradleVersionCatalog(path.Dir(), context)
+	if err != nil {
+		return nil, err
+	}
+	script := stripGradleComments(content)
+	artifact := gradleProjectArtifact(script, path.Dir(), context)
+	project := context.NewProject(artifact.Name(), findLicensesInDir(path.Dir(), context))
+	dependencies := findGradleDependencies(script, catalog)
+	for _, dependency := range dependencies {
+		interpolateGradleArtifact(dependency, artifact.properties)
+	}
+	for _, dependency := range readGradleLock(path.Dir(), context).apply(dependencies) {
+		project.Deps = append(project.Deps, dependency.Name())
+	}
+	resolveMavenDependencies(project, context, currentDepth)
+	return project, nil
+}
+
+// gradleProjectArtifact builds the artifact of the project from the build script, gradle.properties, and settings.gradle.
+// The properties of the returned artifact are the variables and the extra properties declared in them.
+func gradleProjectArtifact(script string, dir *Path, context *Context) *artifact {
+	properties := readGradleProperties(dir.Join("gradle.properties"), context)
+	for key, value := range findGradleVariables(script) {
+		properties[key] = value
+	}
+	for _, match := range gradleProjectValue.FindAllStringSubmatch(script, -1) {
+		properties[match[1]] = match[2]
+	}
+	artifact := newArtifact(properties["group"], findGradleRootProjectName(dir, context), properties["version"])
+	for key, value := range properties {
+		artifact.properties[key] = value
+	}
+	return artifact
+}
+
+func findGradleRootProjectName(dir *Path, context *Context) string {
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+		content, err := readText(dir.Join(name), context)
+		if err != nil {
+			continue
+		}
+		if match := gradleRootName.FindStringSubmatch(stripGradleComments(content)); match != nil {
+			return match[1]
+		}
+	}
+	return dir.Base()
+}
+
+func readGradleProperties(path *Path, context *Context) map[string]string {
+	properties := map[string]string{}
+	content, err := readText(path, context)
+	if err != nil {
+		return properties
+	}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if index := strings.IndexAny(line, "=:"); index > 0 {
+			properties[strings.TrimSpace(line[:index])] = strings.TrimSpace(line[index+1:])
+		}
+	}
+	return properties
+}
+
+func findGradleVariables(script string) map[string]string {
+	variables := map[string]string{}
+	for _, match := range gradleVariable.FindAllStringSubmatch(script, -1) {
+		variables[match[1]] = match[2]
+	}
+	for _, match := range gradleKotlinExtra.FindAllStringSubmatch(script, -1) {
+		if match[1] != "" {
+			variables[match[1]] = match[2]
+		} else {
+			variables[match[3]] = match[4]
+		}
+	}
+	for _, block := range findGradleBlocks(script, "ext") {
+		for _, match := range gradleKeyValue.FindAllStringSubmatch(block, -1) {
+			variables[match[1]] = match[2]
+		}
+	}
+	return variables
+}
+
+// interpolateGradleArtifact replaces the variable references (`$name` and `${name}`) in the given artifact by the given properties.
+func interpolateGradleArtifact(artifact *artifact, properties map[string]string) {
+	replacer := func(value string) string {
+		return gradlePropReference.ReplaceAllStringFunc(value, func(reference string) string {
+			match := gradlePropReference.FindStringSubmatch(reference)
+			if value, ok := properties[match[2]]; ok {
+				return value
+			}
+			return reference
+		})
+	}
+	artifact.groupID = replacer(artifact.groupID)
+	artifact.artifactID = replacer(artifact.artifactID)
+	artifact.version = replacer(artifact.version)
+}
+
+// findGradleDependencies finds the dependencies declared in the top-level dependencies blocks.
+// The dependencies blocks in the other blocks (e.g., buildscript) are ignored, since they are not the dependencies of the project.
+// The catalog accessors (e.g., `libs.guava` and `libs.bundles.testing`) are resolved by the given version catalog.
+func findGradleDependencies(script string, catalog *gradleVersionCatalog) []*artifact {
+	dependencies := []*artifact{}
+	for _, block := range findGradleBlocks(script, "dependencies") {
+		scanner := bufio.NewScanner(strings.NewReader(block))
+		for scanner.Scan() {
+			for _, statement := range strings.Split(scanner.Text(), ";") {
+				statement = strings.TrimSpace(statement)
+				if libraries, ok := parseGradleCatalogDependency(statement, catalog); ok {
+					dependencies = append(dependencies, libraries...)
+				} else if dependency, ok := parseGradleDependency(statement); ok {
+					dependencies = append(dependencies, dependency)
+				}
+			}
+		}
+	}
+	return dependencies
+}
+
+func parseGradleDependency(statement string) (*artifact, bool) {
+	if match := gradleKotlinNotation.FindStringSubmatch(statement); match != nil && isGradleConfiguration(match[1]) {
+		return newArtifact("org.jetbrains.kotlin", "kotlin-"+match[2], match[3]), true
+	}
+	if match := gradleMapNotation.FindStringSubmatch(statement); match != nil && isGradleConfiguration(match[1]) {
+		values := map[string]string{}
+		for _, kv := range gradleKeyValue.FindAllStringSubmatch(match[2], -1) {
+			values[kv[1]] = kv[2]
+		}
+		return newArtifact(values["group"], values["name"], values["version"]), values["name"] != ""
+	}
+	if match := gradleStringNotation.FindStringSubmatch(statement); match != nil && isGradleConfiguration(match[1]) {
+		return parseGradleCoordinate(match[2])
+	}
+	return nil, false
+}
+
+// parseGradleCoordinate parses the string notation of the dependency, `group:name:version[:classifier][@extension]`.
+func parseGradleCoordinate(coordinate string) (*artifact, bool) {
+	if index := strings.Index(coordinate, "@"); index >= 0 {
+		coordinate = coordinate[:index]
+	}
+	items := strings.Split(coordinate, ":")
+	if len(items) < 2 || items[0] == "" || items[1] == "" {
+		return nil, false
+	}
+	version := ""
+	if len(items) >= 3 {
+		version = items[2]
+	}
+	return newArtifact(items[0], items[1], version), true
+}
+
+func isGradleConfiguration(name string) bool {
+	if name == "classpath" {
+		return false
+	}
+	for _, suffix := range gradleConfigurationSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// findGradleBlocks returns the contents of the top-level blocks named by the given name, e.g., `dependencies { ... }`.
+func findGradleBlocks(script, name string) []string {
+	blocks := []string{}
+	depth := 0
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '"' || c == '\'':
+			i = skipGradleString(script, i)
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		case depth == 0 && isGradleBlockStart(script, i, name):
+			start := strings.IndexByte(script[i:], '{') + i
+			end := findGradleBlockEnd(script, start)
+			blocks = append(blocks, script[start+1:end])
+			i = end
+		}
+	}
+	return blocks
+}
+
+func isGradleBlockStart(script string, index int, name string) bool {
+	if !strings.HasPrefix(script[index:], name) || (index > 0 && isGradleIdentifierChar(script[index-1])) {
+		return false
+	}
+	rest := strings.TrimLeft(script[index+len(name):], " \t\r\n")
+	return strings.HasPrefix(rest, "{")
+}
+
+func isGradleIdentifierChar(c byte) bool {
+	return c == '_' || c == '.' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func findGradleBlockEnd(script string, start int) int {
+	depth := 0
+	for i := start; i < len(script); i++ {
+		switch script[i] {
+		case '"', '\'':
+			i = skipGradleString(script, i)
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(script)
+}
+
+// skipGradleString returns the index of the closing quote of the string literal starting at the given index.
+func skipGradleString(script string, start int) int {
+	quote := script[start]
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		case '\n':
+			return i
+		}
+	}
+	return len(script)
+}
+
+// stripGradleComments removes the comments (`// ...` and `/* ... */`) in the given script.
+func stripGradleComments(script string) string {
+	builder := strings.Builder{}
+	for i := 0; i < len(script); i++ {
+		switch {
+		case script[i] == '"' || script[i] == '\'':
+			end := skipGradleString(script, i)
+			if end >= len(script) {
+				end = len(script) - 1
+			}
+			builder.WriteString(script[i : end+1])
+			i = end
+		case strings.HasPrefix(script[i:], "//"):
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			builder.WriteByte('\n')
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return builder.String()
+			}
+			i += end + 3
+		default:
+			builder.WriteByte(script[i])
+		}
+	}
+	return builder.String()
+}
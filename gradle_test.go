@@ -0,0 +1,61 @@
+package purplecat
+
+import "testing"
+
+func TestParseGradle(t *testing.T) {
+	testdata := []struct {
+		path            string
+		wontProjectName string
+		wontDeps        []string
+	}{
+		{"testdata/gradleproject", "jp.ac.kyoto_su/project4test/1.0.0", []string{"args4j/args4j/2.33", "com.google.guava/guava/30.0-jre", "junit/junit/4.13.1", "org.slf4j/slf4j-simple/1.7.30"}},
+		{"testdata/gradlektsproject/build.gradle.kts", "jp.ac.kyoto_su/gradlektsproject/1.0.0", []string{"org.jetbrains.kotlin/kotlin-stdlib/1.4.21", "args4j/args4j/2.33", "org.hamcrest/hamcrest-all/1.3"}},
+		{"testdata/gradlecatalogproject", "jp.ac.kyoto_su/catalog4test/1.0.0", []string{"com.google.guava/guava/30.0-jre", "org.apache.commons/commons-lang3/3.11", "args4j/args4j/2.33", "junit/junit/4.13.1", "org.hamcrest/hamcrest-core/1.3", "com.google.guava/failureaccess/1.0.1", "com.example/gradle-plugin/1.0"}},
+	}
+	for _, td := range testdata {
+		parser := &gradleParser{context: NewContext(true, "json", 1)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		if tree.Name() != td.wontProjectName {
+			t.Errorf("%s: project name did not match, wont %s, got %s", td.path, td.wontProjectName, tree.Name())
+		}
+		if len(tree.Deps) != len(td.wontDeps) {
+			t.Errorf("%s: dependency count did not match, wont %d, got %d (%v)", td.path, len(td.wontDeps), len(tree.Deps), tree.Deps)
+			continue
+		}
+		for i, wont := range td.wontDeps {
+			if tree.Deps[i] != wont {
+				t.Errorf("%s: dependency[%d] did not match, wont %s, got %s", td.path, i, wont, tree.Deps[i])
+			}
+		}
+	}
+}
+
+func TestParseGradleDependency(t *testing.T) {
+	testdata := []struct {
+		statement   string
+		successFlag bool
+		wontName    string
+	}{
+		{`implementation 'args4j:args4j:2.33'`, true, "args4j/args4j/2.33"},
+		{`api("org.slf4j:slf4j-api:1.7.30:sources@jar")`, true, "org.slf4j/slf4j-api/1.7.30"},
+		{`debugImplementation group: 'g', name: 'a', version: 'v'`, true, "g/a/v"},
+		{`testImplementation(kotlin("test"))`, true, "org.jetbrains.kotlin/kotlin-test/"},
+		{`classpath 'com.example:plugin:1.0'`, false, ""},
+		{`implementation project(':sub')`, false, ""},
+		{`exclude group: 'g', module: 'a'`, false, ""},
+	}
+	for _, td := range testdata {
+		dependency, ok := parseGradleDependency(td.statement)
+		if ok != td.successFlag {
+			t.Errorf("parseGradleDependency(%s) wont success %v, got %v", td.statement, td.successFlag, ok)
+			continue
+		}
+		if ok && dependency.Name() != td.wontName {
+			t.Errorf("parseGradleDependency(%s) did not match, wont %s, got %s", td.statement, td.wontName, dependency.Name())
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package purplecat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// gradleVersionCatalogPath is the relative path of the default version catalog (`libs`) from the root project.
+const gradleVersionCatalogPath = "gradle/libs.versions.toml"
+
+var gradleCatalogNotation = regexp.MustCompile(`^([A-Za-z]\w*)\s*\(?\s*libs\.([\w.]+)`)
+
+// gradleVersionCatalog represents the version catalog declared in gradle/libs.versions.toml.
+// The keys of libraries and bundles are the accessor names, e.g., `commons.lang3` for the alias `commons-lang3`.
+type gradleVersionCatalog struct {
+	libraries map[string]*artifact
+	bundles   map[string][]string
+}
+
+func newGradleVersionCatalog() *gradleVersionCatalog {
+	return &gradleVersionCatalog{libraries: map[string]*artifact{}, bundles: map[string][]string{}}
+}
+
+// readGradleVersionCatalog reads gradle/libs.versions.toml in the given directory.
+// If the catalog does not exist, this function returns the empty catalog.
+func readGradleVersionCatalog(dir *Path, context *Context) (*gradleVersionCatalog, error) {
+	path := dir.Join(gradleVersionCatalogPath)
+	if !path.Exists(context) {
+		return newGradleVersionCatalog(), nil
+	}
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := parseGradleVersionCatalog(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return catalog, nil
+}
+
+func parseGradleVersionCatalog(content string) (*gradleVersionCatalog, error) {
+	tree, err := toml.Load(content)
+	if err != nil {
+		return nil, err
+	}
+	catalog := newGradleVersionCatalog()
+	versions := map[string]string{}
+	for alias, value := range tomlTable(tree.Get("versions")) {
+		versions[alias] = gradleCatalogVersion(value, versions)
+	}
+	for alias, value := range tomlTable(tree.Get("libraries")) {
+		if library, ok := parseGradleCatalogLibrary(value, versions); ok {
+			catalog.libraries[gradleCatalogAccessor(alias)] = library
+		}
+	}
+	for alias, value := range tomlTable(tree.Get("bundles")) {
+		items, _ := value.([]interface{})
+		for _, item := range items {
+			if name, ok := item.(string); ok {
+				catalog.bundles[gradleCatalogAccessor(alias)] = append(catalog.bundles[gradleCatalogAccessor(alias)], gradleCatalogAccessor(name))
+			}
+		}
+	}
+	return catalog, nil
+}
+
+func tomlTable(value interface{}) map[string]interface{} {
+	if tree, ok := value.(*toml.Tree); ok {
+		return tree.ToMap()
+	}
+	if table, ok := value.(map[string]interface{}); ok {
+		return table
+	}
+	return map[string]interface{}{}
+}
+
+// gradleCatalogAccessor converts the given alias into the accessor name, since `-`, `_`, and `.` are the separators of the alias.
+func gradleCatalogAccessor(alias string) string {
+	return strings.NewReplacer("-", ".", "_", ".").Replace(alias)
+}
+
+// parseGradleCatalogLibrary parses the library declaration in the catalog.
+// The declaration is either the string notation (`group:name:version`) or the table with `module` (or `group` and `name`) and `version`.
+func parseGradleCatalogLibrary(value interface{}, versions map[string]string) (*artifact, bool) {
+	if coordinate, ok := value.(string); ok {
+		return parseGradleCoordinate(coordinate)
+	}
+	table := tomlTable(value)
+	var library *artifact
+	if module, ok := table["module"].(string); ok {
+		items := strings.Split(module, ":")
+		if len(items) != 2 {
+			return nil, false
+		}
+		library = newArtifact(items[0], items[1], "")
+	} else {
+		group, _ := table["group"].(string)
+		name, _ := table["name"].(string)
+		if group == "" || name == "" {
+			return nil, false
+		}
+		library = newArtifact(group, name, "")
+	}
+	library.version = gradleCatalogVersion(table["version"], versions)
+	return library, true
+}
+
+// gradleCatalogVersion returns the version from the given version declaration.
+// The declaration is either the string, or the table with `ref`, `strictly`, `require`, or `prefer`.
+func gradleCatalogVersion(value interface{}, versions map[string]string) string {
+	if version, ok := value.(string); ok {
+		return version
+	}
+	table := tomlTable(value)
+	if ref, ok := table["ref"].(string); ok {
+		return versions[ref]
+	}
+	for _, key := range []string{"strictly", "require", "prefer"} {
+		if version, ok := table[key].(string); ok {
+			return version
+		}
+	}
+	return ""
+}
+
+// find returns the libraries referred by the given accessor, e.g., `guava` or `bundles.testing` of `libs.bundles.testing`.
+func (catalog *gradleVersionCatalog) find(accessor string) ([]*artifact, bool) {
+	if strings.HasPrefix(accessor, "bundles.") {
+		names, ok := catalog.bundles[strings.TrimPrefix(accessor, "bundles.")]
+		libraries := []*artifact{}
+		for _, name := range names {
+			if library, found := catalog.libraries[name]; found {
+				libraries = append(libraries, library.copy())
+			}
+		}
+		return libraries, ok
+	}
+	library, ok := catalog.libraries[accessor]
+	if !ok {
+		return nil, false
+	}
+	return []*artifact{library.copy()}, true
+}
+
+// parseGradleCatalogDependency parses the dependency declared by the catalog accessor, e.g., `implementation(libs.guava)`.
+func parseGradleCatalogDependency(statement string, catalog *gradleVersionCatalog) ([]*artifact, bool) {
+	match := gradleCatalogNotation.FindStringSubmatch(statement)
+	if match == nil || !isGradleConfiguration(match[1]) {
+		return nil, false
+	}
+	return catalog.find(strings.TrimSuffix(match[2], ".get"))
+}
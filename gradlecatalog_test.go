@@ -0,0 +1,75 @@
+package purplecat
+
+import "testing"
+
+func TestParseGradleVersionCatalog(t *testing.T) {
+	catalog, err := parseGradleVersionCatalog(`[versions]
+guava = "30.0-jre"
+slf4j = { strictly = "1.7.30" }
+
+[libraries]
+guava = { module = "com.google.guava:guava", version.ref = "guava" }
+slf4j-api = { group = "org.slf4j", name = "slf4j-api", version.ref = "slf4j" }
+commons_lang3 = { module = "org.apache.commons:commons-lang3", version = { require = "3.11" } }
+args4j = "args4j:args4j:2.33"
+invalid = { version = "1.0" }
+
+[bundles]
+logging = ["slf4j-api", "commons_lang3"]
+`)
+	if err != nil {
+		t.Fatalf("parse failed: %s", err.Error())
+	}
+	testdata := []struct {
+		accessor    string
+		successFlag bool
+		wontNames   []string
+	}{
+		{"guava", true, []string{"com.google.guava/guava/30.0-jre"}},
+		{"slf4j.api", true, []string{"org.slf4j/slf4j-api/1.7.30"}},
+		{"commons.lang3", true, []string{"org.apache.commons/commons-lang3/3.11"}},
+		{"args4j", true, []string{"args4j/args4j/2.33"}},
+		{"bundles.logging", true, []string{"org.slf4j/slf4j-api/1.7.30", "org.apache.commons/commons-lang3/3.11"}},
+		{"invalid", false, []string{}},
+		{"unknown", false, []string{}},
+	}
+	for _, td := range testdata {
+		libraries, ok := catalog.find(td.accessor)
+		if ok != td.successFlag {
+			t.Errorf("find(%s) wont success %v, got %v", td.accessor, td.successFlag, ok)
+			continue
+		}
+		if len(libraries) != len(td.wontNames) {
+			t.Errorf("find(%s) library count did not match, wont %d, got %d", td.accessor, len(td.wontNames), len(libraries))
+			continue
+		}
+		for i, wont := range td.wontNames {
+			if libraries[i].Name() != wont {
+				t.Errorf("find(%s)[%d] did not match, wont %s, got %s", td.accessor, i, wont, libraries[i].Name())
+			}
+		}
+	}
+}
+
+func TestParseGradleCatalogDependency(t *testing.T) {
+	catalog, _ := parseGradleVersionCatalog(`[libraries]
+guava = "com.google.guava:guava:30.0-jre"
+`)
+	testdata := []struct {
+		statement   string
+		successFlag bool
+	}{
+		{`implementation(libs.guava)`, true},
+		{`implementation libs.guava`, true},
+		{`api(libs.guava.get())`, true},
+		{`implementation(platform(libs.guava))`, false},
+		{`classpath libs.guava`, false},
+		{`implementation(libs.unknown)`, false},
+	}
+	for _, td := range testdata {
+		_, ok := parseGradleCatalogDependency(td.statement, catalog)
+		if ok != td.successFlag {
+			t.Errorf("parseGradleCatalogDependency(%s) wont success %v, got %v", td.statement, td.successFlag, ok)
+		}
+	}
+}
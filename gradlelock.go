@@ -0,0 +1,77 @@
+package purplecat
+
+import (
+	"bufio"
+	"strings"
+)
+
+// gradleLockFileNames is the lockfiles generated by `gradle dependencies --write-locks`.
+// buildscript-gradle.lockfile locks the classpath of the build script.
+var gradleLockFileNames = []string{"gradle.lockfile", "buildscript-gradle.lockfile"}
+
+// gradleLock represents the locked dependencies in the lockfiles, in order of appearance.
+type gradleLock struct {
+	artifacts []*artifact
+	versions  map[string]string
+}
+
+func newGradleLock() *gradleLock {
+	return &gradleLock{artifacts: []*artifact{}, versions: map[string]string{}}
+}
+
+// readGradleLock reads the lockfiles in the given directory.
+// If no lockfiles exist, this function returns the empty lock.
+func readGradleLock(dir *Path, context *Context) *gradleLock {
+	lock := newGradleLock()
+	for _, name := range gradleLockFileNames {
+		content, err := readText(dir.Join(name), context)
+		if err == nil {
+			lock.parse(content)
+		}
+	}
+	return lock
+}
+
+// parse parses the content of the lockfile, whose lines are `group:name:version=configurations`.
+// The line starting with `empty=` lists the configurations without dependencies.
+func (lock *gradleLock) parse(content string) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "empty=") {
+			continue
+		}
+		if index := strings.Index(line, "="); index >= 0 {
+			line = line[:index]
+		}
+		items := strings.Split(line, ":")
+		if len(items) != 3 {
+			continue
+		}
+		key := items[0] + ":" + items[1]
+		if _, ok := lock.versions[key]; ok {
+			continue
+		}
+		lock.versions[key] = items[2]
+		lock.artifacts = append(lock.artifacts, newArtifact(items[0], items[1], items[2]))
+	}
+}
+
+// apply replaces the versions of the given dependencies by the locked versions, and
+// appends the locked dependencies which are not declared in the build script (the transitive ones).
+func (lock *gradleLock) apply(dependencies []*artifact) []*artifact {
+	declared := map[string]bool{}
+	for _, dependency := range dependencies {
+		key := dependency.groupID + ":" + dependency.artifactID
+		if version, ok := lock.versions[key]; ok {
+			dependency.version = version
+		}
+		declared[key] = true
+	}
+	for _, locked := range lock.artifacts {
+		if !declared[locked.groupID+":"+locked.artifactID] {
+			dependencies = append(dependencies, locked.copy())
+		}
+	}
+	return dependencies
+}
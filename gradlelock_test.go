@@ -0,0 +1,23 @@
+package purplecat
+
+import "testing"
+
+func TestGradleLockApply(t *testing.T) {
+	lock := newGradleLock()
+	lock.parse(`# This is a Gradle generated file for dependency locking.
+args4j:args4j:2.33=compileClasspath,runtimeClasspath
+com.google.guava:failureaccess:1.0.1=compileClasspath
+com.google.guava:guava:30.0-jre=compileClasspath
+empty=annotationProcessor
+`)
+	dependencies := lock.apply([]*artifact{newArtifact("com.google.guava", "guava", "30.+"), newArtifact("junit", "junit", "4.13.1")})
+	wontNames := []string{"com.google.guava/guava/30.0-jre", "junit/junit/4.13.1", "args4j/args4j/2.33", "com.google.guava/failureaccess/1.0.1"}
+	if len(dependencies) != len(wontNames) {
+		t.Fatalf("dependency count did not match, wont %d, got %d", len(wontNames), len(dependencies))
+	}
+	for i, wont := range wontNames {
+		if dependencies[i].Name() != wont {
+			t.Errorf("dependency[%d] did not match, wont %s, got %s", i, wont, dependencies[i].Name())
+		}
+	}
+}
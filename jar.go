@@ -0,0 +1,239 @@
+package purplecat
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// jarParser is the instance of Parser for scanning the libraries shipped in the Java archives (fat jar of Spring Boot, war, and ear).
+type jarParser struct {
+	context *Context
+}
+
+// jarLibrary is the library embedded in the Java archive, whose artifact is nil if the archive has no pom.properties.
+type jarLibrary struct {
+	fileName string
+	artifact *artifact
+	archive  *zip.Reader
+	// shaded shows the classes of the library are shaded into the receiver archive, which is the archive containing them.
+	shaded bool
+}
+
+func (library *jarLibrary) Name() string {
+	if library.artifact == nil {
+		return library.fileName
+	}
+	return library.artifact.Name()
+}
+
+func isJavaArchive(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".jar" || ext == ".war" || ext == ".ear"
+}
+
+// IsTarget returns true if the given path is the Java archive.
+func (jp *jarParser) IsTarget(path *Path, context *Context) bool {
+	return isJavaArchive(path.Base()) && path.Exists(context)
+}
+
+// Parse scans the given Java archive, and returns the project whose dependencies are the embedded libraries
+// (`BOOT-INF/lib`, `WEB-INF/lib`, and `lib` of ear) and the shaded artifacts (`META-INF/maven/*/*/pom.properties`).
+// The libraries are not resolved transitively, since the archive contains all of the shipped libraries.
+func (jp *jarParser) Parse(path *Path) (*Project, error) {
+	if jp.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", jp.context.Depth, 0)
+	}
+	logger.Infof("parseJavaArchive(%s)", path.Path)
+	archive, closeArchive, err := openJavaArchive(path, jp.context)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	defer closeArchive()
+	root := &jarLibrary{fileName: path.Base(), archive: archive}
+	shaded := []*jarLibrary{}
+	for _, art := range readPomPropertiesInJar(archive) {
+		if art.artifactID+"-"+art.version == strings.TrimSuffix(root.fileName, filepath.Ext(root.fileName)) {
+			root.artifact = art
+		} else {
+			shaded = append(shaded, &jarLibrary{fileName: art.artifactID + "-" + art.version + ".jar", artifact: art, archive: archive, shaded: true})
+		}
+	}
+	project := jp.context.NewProject(root.Name(), findLicensesInJar(archive, root.artifact))
+	if jp.context.Depth < 1 {
+		return project, nil
+	}
+	found := map[string]bool{}
+	addLibrary := func(library *jarLibrary) {
+		if found[library.Name()] {
+			return
+		}
+		found[library.Name()] = true
+		if _, ok := jp.context.SearchCache(library.Name()); !ok {
+			jp.context.NewProject(library.Name(), jp.findLicenses(library))
+		}
+		project.Deps = append(project.Deps, library.Name())
+	}
+	visitJarLibraries(archive, addLibrary)
+	for _, library := range shaded {
+		addLibrary(library)
+	}
+	return project, nil
+}
+
+// findLicenses finds the licenses of the given library from its pom in the repositories, and its META-INF in this order.
+// The licenses of the shaded artifacts are read from the poms embedded in the archive.
+func (jp *jarParser) findLicenses(library *jarLibrary) Licenses {
+	if library.artifact != nil {
+		if licenses, ok := findLicensesOfMavenArtifact(library.artifact, jp.context); ok {
+			return licenses
+		}
+	}
+	if library.shaded {
+		return findLicensesInEmbeddedPom(library.archive, library.artifact)
+	}
+	return findLicensesInJar(library.archive, library.artifact)
+}
+
+// findLicensesOfMavenArtifact returns the licenses declared in the pom of the given artifact (or its ancestors) without its dependencies.
+func findLicensesOfMavenArtifact(art *artifact, context *Context) (Licenses, bool) {
+	pomPath, err := constructPomPath(art, context, nil)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+		return Licenses{}, false
+	}
+	doc, err := readXML(pomPath, context)
+	if err != nil {
+		logger.Debugf("%s: %s", pomPath.Path, err.Error())
+		return Licenses{}, false
+	}
+	found := parseProjectInfo(doc)
+	if found == nil {
+		return Licenses{}, false
+	}
+	readProperties(doc, found)
+	return findMavenLicenses(buildMavenModel(found, doc, pomPath.Dir(), context, map[string]bool{}))
+}
+
+// openJavaArchive opens the given Java archive, and returns the function for closing it.
+// The local archive is read on demand, and the archive on the web is read into memory, since zip requires the random access.
+func openJavaArchive(path *Path, context *Context) (*zip.Reader, func(), error) {
+	if _, ok := path.supporter.(*localFilePathSupporter); ok {
+		reader, err := zip.OpenReader(path.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &reader.Reader, func() { reader.Close() }, nil
+	}
+	reader, err := path.Open(context)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	return archive, func() {}, err
+}
+
+// visitJarLibraries calls the given function for each library embedded in the given archive, and the wars in the ear are scanned recursively.
+// The nested archives are read into memory one at a time, and released after the visit, so that the visitor should not keep them.
+func visitJarLibraries(archive *zip.Reader, visit func(*jarLibrary)) {
+	for _, file := range archive.File {
+		dir, name := path.Dir(file.Name), path.Base(file.Name)
+		isLibrary := (dir == "BOOT-INF/lib" || dir == "WEB-INF/lib" || dir == "lib") && strings.EqualFold(path.Ext(name), ".jar")
+		isModule := dir == "." && strings.EqualFold(path.Ext(name), ".war")
+		if !isLibrary && !isModule {
+			continue
+		}
+		nested, err := readJarEntryArchive(file)
+		if err != nil {
+			logger.Warnf("%s: %s", file.Name, err.Error())
+			continue
+		}
+		if isModule {
+			visitJarLibraries(nested, visit)
+			continue
+		}
+		visit(newJarLibrary(name, nested))
+	}
+}
+
+func readJarEntryArchive(file *zip.File) (*zip.Reader, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// newJarLibrary creates the library of the given nested jar. The artifact is identified by pom.properties in the jar,
+// and the one matched with the file name is taken if the jar has several pom.properties (e.g., the shaded jar).
+func newJarLibrary(fileName string, archive *zip.Reader) *jarLibrary {
+	library := &jarLibrary{fileName: fileName, archive: archive}
+	artifacts := readPomPropertiesInJar(archive)
+	for _, art := range artifacts {
+		if art.artifactID+"-"+art.version+".jar" == fileName {
+			library.artifact = art
+			return library
+		}
+	}
+	if len(artifacts) == 1 {
+		library.artifact = artifacts[0]
+	}
+	return library
+}
+
+// readPomPropertiesInJar reads the coordinates of the artifacts from `META-INF/maven/<groupId>/<artifactId>/pom.properties` of the given archive.
+func readPomPropertiesInJar(archive *zip.Reader) []*artifact {
+	artifacts := []*artifact{}
+	for _, file := range archive.File {
+		if !strings.HasPrefix(file.Name, "META-INF/maven/") || path.Base(file.Name) != "pom.properties" {
+			continue
+		}
+		properties, err := readPomProperties(file)
+		if err != nil {
+			logger.Debugf("%s: %s", file.Name, err.Error())
+			continue
+		}
+		if art := newArtifact(properties["groupId"], properties["artifactId"], properties["version"]); art.isValid() {
+			artifacts = append(artifacts, art)
+		}
+	}
+	return artifacts
+}
+
+// readPomProperties reads pom.properties generated by Maven, which has the lines of `key=value`, and the comments beginning with `#`.
+func readPomProperties(file *zip.File) (map[string]string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if items := strings.SplitN(line, "=", 2); len(items) == 2 {
+			properties[strings.TrimSpace(items[0])] = strings.TrimSpace(items[1])
+		}
+	}
+	return properties, scanner.Err()
+}
@@ -0,0 +1,86 @@
+package purplecat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseJavaArchive(t *testing.T) {
+	dir, err := buildJavaArchiveTree("testdata/javaarchives")
+	defer os.RemoveAll(dir)
+	if err != nil {
+		t.Errorf("testdata/javaarchives: build failed: %s", err.Error())
+		return
+	}
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	testdata := []struct {
+		giveArchive  string
+		wontName     string
+		wontLicenses string
+		wontDeps     []string
+		wontLibs     []string
+	}{
+		{"app-1.0.0.jar", "com.example/app/1.0.0", "MIT License",
+			[]string{"args4j/args4j/2.33", "plain-2.0.jar", "com.example/unknown-lib/3.0", "com.example/shaded/1.2.0"},
+			[]string{"MIT License", "Apache License 2.0", "BSD 3-Clause \"New\" or \"Revised\" License", "Eclipse Public License 2.0"}},
+		{"enterprise-1.0.0.ear", "enterprise-1.0.0.ear", "",
+			[]string{"junit/junit/4.13.1", "args4j/args4j/2.33"},
+			[]string{"Eclipse Public License 1.0", "MIT License"}},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, "json", 1)
+		path := NewPath(filepath.Join(dir, td.giveArchive))
+		parser, err := context.GenerateParser(path)
+		if err != nil {
+			t.Errorf("%s: parser not found: %s", td.giveArchive, err.Error())
+			continue
+		}
+		tree, err := parser.Parse(path)
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.giveArchive, err.Error())
+			continue
+		}
+		if tree.Name() != td.wontName || joinLicenseNames(tree) != td.wontLicenses {
+			t.Errorf("%s: project did not match, wont %s [%s], got %s [%s]", td.giveArchive, td.wontName, td.wontLicenses, tree.Name(), joinLicenseNames(tree))
+		}
+		if strings.Join(tree.Deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("%s: dependencies did not match, wont %v, got %v", td.giveArchive, td.wontDeps, tree.Deps)
+		}
+		licenses := []string{}
+		for _, dep := range tree.Dependencies() {
+			licenses = append(licenses, joinLicenseNames(dep))
+		}
+		if strings.Join(licenses, ",") != strings.Join(td.wontLibs, ",") {
+			t.Errorf("%s: licenses of the libraries did not match, wont %v, got %v", td.giveArchive, td.wontLibs, licenses)
+		}
+	}
+}
+
+func TestParseJavaArchiveViaURL(t *testing.T) {
+	dir, err := buildJavaArchiveTree("testdata/javaarchives")
+	defer os.RemoveAll(dir)
+	if err != nil {
+		t.Errorf("testdata/javaarchives: build failed: %s", err.Error())
+		return
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(false, "json", 0)
+	path := NewPath(server.URL + "/app-1.0.0.jar")
+	if !(&jarParser{context: context}).IsTarget(path, context) {
+		t.Errorf("%s: wont be the target of jarParser", path.Path)
+	}
+	tree, err := (&jarParser{context: context}).Parse(path)
+	if err != nil {
+		t.Errorf("%s: parse failed: %s", path.Path, err.Error())
+		return
+	}
+	if tree.Name() != "com.example/app/1.0.0" || len(tree.Deps) != 0 {
+		t.Errorf("%s: project did not match, wont com.example/app/1.0.0 without dependencies (depth 0), got %s %v", path.Path, tree.Name(), tree.Deps)
+	}
+}
@@ -0,0 +1,250 @@
+package purplecat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// licenseFileNames is the list of the file names which may contain the license text of the project.
+var licenseFileNames = []string{
+	"LICENSE", "LICENSE.md", "LICENSE.txt",
+	"LICENCE", "LICENCE.md", "LICENCE.txt",
+	"COPYING", "COPYING.md", "COPYING.txt",
+	"NOTICE", "NOTICE.md", "NOTICE.txt",
+	"LICENSE-MIT", "LICENSE-APACHE", "MIT-LICENSE",
+}
+
+func isLicenseFileName(name string) bool {
+	for _, licenseFileName := range licenseFileNames {
+		if name == licenseFileName {
+			return true
+		}
+	}
+	return false
+}
+
+type licenseRule struct {
+	spdxID   string
+	name     string
+	keywords []string
+}
+
+// licenseRules is the list of rules for classifying the license text.
+// The rules are ordered from the specific ones, since the later licenses are often mentioned in the former ones.
+// The GNU licenses with "any later version" (e.g., the standard headers of the source files) are the `-or-later` licenses.
+var licenseRules = []*licenseRule{
+	{"AGPL-3.0-or-later", "GNU Affero General Public License v3.0 or later", []string{"gnu affero general public license", "version 3", "any later version"}},
+	{"AGPL-3.0-only", "GNU Affero General Public License v3.0 only", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0-or-later", "GNU Lesser General Public License v3.0 or later", []string{"gnu lesser general public license", "version 3", "any later version"}},
+	{"LGPL-3.0-only", "GNU Lesser General Public License v3.0 only", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1-or-later", "GNU Lesser General Public License v2.1 or later", []string{"gnu lesser general public license", "version 2.1", "any later version"}},
+	{"LGPL-2.1-only", "GNU Lesser General Public License v2.1 only", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0-or-later", "GNU General Public License v3.0 or later", []string{"gnu general public license", "version 3", "any later version"}},
+	{"GPL-3.0-only", "GNU General Public License v3.0 only", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0-or-later", "GNU General Public License v2.0 or later", []string{"gnu general public license", "version 2", "any later version"}},
+	{"GPL-2.0-only", "GNU General Public License v2.0 only", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", "Mozilla Public License 2.0", []string{"mozilla public license", "2.0"}},
+	{"EPL-2.0", "Eclipse Public License 2.0", []string{"eclipse public license", "v 2.0"}},
+	{"EPL-1.0", "Eclipse Public License 1.0", []string{"eclipse public license", "v 1.0"}},
+	{"Apache-2.0", "Apache License 2.0", []string{"apache license", "version 2.0"}},
+	{"Apache-2.0", "Apache License 2.0", []string{"apache software license", "version 2.0"}},
+	{"BSD-3-Clause", "BSD 3-Clause \"New\" or \"Revised\" License", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", "BSD 2-Clause \"Simplified\" License", []string{"redistribution and use in source and binary forms"}},
+	{"ISC", "ISC License", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"MIT", "MIT License", []string{"permission is hereby granted, free of charge"}},
+	{"Unlicense", "The Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+	{"CC0-1.0", "Creative Commons Zero v1.0 Universal", []string{"cc0 1.0 universal"}},
+	{"WTFPL", "Do What The F*ck You Want To Public License", []string{"do what the fuck you want to public license"}},
+}
+
+func newSpdxLicense(spdxID, name string) *License {
+	return &License{Name: name, SpdxID: spdxID, URL: fmt.Sprintf("https://spdx.org/licenses/%s.html", spdxID)}
+}
+
+// deprecatedGnuSpdxIDPattern matches the deprecated SPDX IDs of the GNU licenses, e.g., `GPL-2.0` and `LGPL-2.1+`.
+var deprecatedGnuSpdxIDPattern = regexp.MustCompile(`^(?i)((?:A|L)?GPL|GFDL)-(\d\.\d)(\+?)$`)
+
+// currentSpdxID returns the current SPDX ID of the given ID, the deprecated IDs of the GNU licenses are replaced with
+// the `-only` (e.g., `GPL-2.0` to `GPL-2.0-only`) or the `-or-later` IDs (e.g., `GPL-2.0+` to `GPL-2.0-or-later`).
+func currentSpdxID(spdxID string) string {
+	match := deprecatedGnuSpdxIDPattern.FindStringSubmatch(spdxID)
+	if match == nil {
+		return spdxID
+	}
+	if match[3] == "+" {
+		return strings.ToUpper(match[1]) + "-" + match[2] + "-or-later"
+	}
+	return strings.ToUpper(match[1]) + "-" + match[2] + "-only"
+}
+
+// newLicenseBySpdxID creates the license of the given SPDX ID, the deprecated IDs are replaced with the current ones.
+// The name of the license is taken from licenseRules and the license templates, and the SPDX ID itself is used if they do not know it.
+func newLicenseBySpdxID(spdxID string) *License {
+	spdxID = currentSpdxID(spdxID)
+	for _, rule := range licenseRules {
+		if strings.EqualFold(rule.spdxID, spdxID) {
+			return newSpdxLicense(rule.spdxID, rule.name)
+		}
+	}
+	for _, template := range licenseTemplates {
+		if strings.EqualFold(template.spdxID, spdxID) {
+			return newSpdxLicense(template.spdxID, template.name)
+		}
+	}
+	return newSpdxLicense(spdxID, spdxID)
+}
+
+// parseSpdxExpression parses the given SPDX license expression (e.g., `(MIT OR Apache-2.0)`), and returns the licenses in it.
+// The exceptions are kept with the license (e.g., `Apache-2.0 WITH LLVM-exception`), and
+// the deprecated notation separated by `/` (e.g., `MIT/Apache-2.0`) is also accepted.
+func parseSpdxExpression(expression string) Licenses {
+	tokens := strings.Fields(strings.NewReplacer("(", " ", ")", " ", "/", " ").Replace(expression))
+	ids := []string{}
+	for i := 0; i < len(tokens); i++ {
+		switch strings.ToUpper(tokens[i]) {
+		case "OR", "AND":
+			continue
+		case "WITH":
+			if len(ids) > 0 && i+1 < len(tokens) {
+				ids[len(ids)-1] = ids[len(ids)-1] + " WITH " + tokens[i+1]
+				i++
+			}
+			continue
+		}
+		ids = append(ids, tokens[i])
+	}
+	licenses := Licenses{}
+	for _, id := range ids {
+		licenses = appendLicenseIfAbsent(licenses, newLicenseBySpdxID(id))
+	}
+	return licenses
+}
+
+func normalizeLicenseText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+func (rule *licenseRule) match(normalizedText string) bool {
+	for _, keyword := range rule.keywords {
+		if !strings.Contains(normalizedText, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// classifyLicense finds the license from the given license text by the SPDX license templates (see classifyLicenseText).
+// If the confidence is too low (e.g., the fragments of the license texts), the license is found by the keywords of licenseRules.
+// If the license text was not classified, this function returns UnknownLicense and false.
+func classifyLicense(text string) (*License, bool) {
+	if license, confidence := classifyLicenseText(text); confidence >= licenseConfidenceThreshold {
+		return license, true
+	}
+	normalizedText := normalizeLicenseText(text)
+	for _, rule := range licenseRules {
+		if rule.match(normalizedText) {
+			return newSpdxLicense(rule.spdxID, rule.name), true
+		}
+	}
+	return UnknownLicense, false
+}
+
+// licenseURLPatterns maps the fragments of the well-known license urls (in lower case, without the schemes) into the SPDX IDs.
+// The urls of the GNU licenses are mapped into the `-only` licenses, since the urls do not show "any later version".
+var licenseURLPatterns = []struct {
+	fragment string
+	spdxID   string
+}{
+	{"apache.org/licenses/license-2.0", "Apache-2.0"},
+	{"opensource.org/licenses/apache-2.0", "Apache-2.0"},
+	{"opensource.org/licenses/mit", "MIT"},
+	{"opensource.org/licenses/bsd-3-clause", "BSD-3-Clause"},
+	{"opensource.org/licenses/bsd-2-clause", "BSD-2-Clause"},
+	{"eclipse.org/legal/epl-v10", "EPL-1.0"},
+	{"eclipse.org/legal/epl-2.0", "EPL-2.0"},
+	{"eclipse.org/org/documents/epl-v10", "EPL-1.0"},
+	{"gnu.org/licenses/agpl-3.0", "AGPL-3.0-only"},
+	{"gnu.org/licenses/lgpl-3.0", "LGPL-3.0-only"},
+	{"gnu.org/licenses/lgpl-2.1", "LGPL-2.1-only"},
+	{"gnu.org/licenses/old-licenses/lgpl-2.1", "LGPL-2.1-only"},
+	{"gnu.org/licenses/gpl-3.0", "GPL-3.0-only"},
+	{"gnu.org/licenses/old-licenses/gpl-2.0", "GPL-2.0-only"},
+	{"mozilla.org/mpl/2.0", "MPL-2.0"},
+	{"creativecommons.org/publicdomain/zero/1.0", "CC0-1.0"},
+	{"unlicense.org", "Unlicense"},
+}
+
+// classifyLicenseURL finds the license from the given well-known license url.
+func classifyLicenseURL(url string) (*License, bool) {
+	normalized := strings.ToLower(url)
+	for _, pattern := range licenseURLPatterns {
+		if strings.Contains(normalized, pattern.fragment) {
+			return newLicenseBySpdxID(pattern.spdxID), true
+		}
+	}
+	return UnknownLicense, false
+}
+
+// classifyLicenseName finds the license from the given license name, SPDX ID, or url, e.g., `Apache License, Version 2.0` or `MIT`.
+func classifyLicenseName(name string) (*License, bool) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(name), "The ")
+	spdxID := currentSpdxID(trimmed)
+	for _, rule := range licenseRules {
+		if strings.EqualFold(spdxID, rule.spdxID) || strings.EqualFold(trimmed, rule.name) {
+			return newSpdxLicense(rule.spdxID, rule.name), true
+		}
+	}
+	if strings.Contains(trimmed, "://") {
+		return classifyLicenseURL(trimmed)
+	}
+	return classifyLicense(trimmed)
+}
+
+func readLicense(path *Path, context *Context) (*License, bool) {
+	reader, err := path.Open(context)
+	if err != nil {
+		return UnknownLicense, false
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return UnknownLicense, false
+	}
+	license, ok := classifyLicense(string(data))
+	logger.Debugf("readLicense(%s): %s (%v)", path.Path, license.SpdxID, ok)
+	return license, ok
+}
+
+// findLicensesInDir classifies the license files (LICENSE, COPYING, NOTICE, and so on) in the given directory.
+// If the license files exist, but no licenses were classified, this function returns UnknownLicense.
+func findLicensesInDir(dir *Path, context *Context) Licenses {
+	licenses := Licenses{}
+	found := false
+	for _, name := range licenseFileNames {
+		path := dir.Join(name)
+		if !path.Exists(context) {
+			continue
+		}
+		found = true
+		if license, ok := readLicense(path, context); ok {
+			licenses = appendLicenseIfAbsent(licenses, license)
+		}
+	}
+	if found && len(licenses) == 0 {
+		return Licenses{UnknownLicense}
+	}
+	return licenses
+}
+
+func appendLicenseIfAbsent(licenses Licenses, license *License) Licenses {
+	for _, item := range licenses {
+		if item.SpdxID == license.SpdxID {
+			return licenses
+		}
+	}
+	return append(licenses, license)
+}
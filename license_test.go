@@ -0,0 +1,100 @@
+package purplecat
+
+import "testing"
+
+func TestClassifyLicense(t *testing.T) {
+	testdata := []struct {
+		text        string
+		successFlag bool
+		wontSpdxID  string
+	}{
+		{"Permission is hereby granted, free of charge, to any person obtaining a copy", true, "MIT"},
+		{"Apache License\n    Version 2.0, January 2004", true, "Apache-2.0"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\n Version 2.1, February 1999", true, "LGPL-2.1-only"},
+		{"GNU GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007", true, "GPL-3.0-only"},
+		{"the Free Software Foundation; either version 2 of the GNU General Public License, or\n(at your option) any later version.", true, "GPL-2.0-or-later"},
+		{"This is free and unencumbered software released into the public domain.", true, "Unlicense"},
+		{"All rights reserved.", false, "unknown"},
+	}
+	for _, td := range testdata {
+		license, ok := classifyLicense(td.text)
+		if ok != td.successFlag || license.SpdxID != td.wontSpdxID {
+			t.Errorf("classifyLicense(%q) did not match, wont %s (%v), got %s (%v)", td.text, td.wontSpdxID, td.successFlag, license.SpdxID, ok)
+		}
+	}
+}
+
+func TestFindLicensesInDir(t *testing.T) {
+	testdata := []struct {
+		dir        string
+		wontSpdxID []string
+	}{
+		{"testdata/gomodcache/github.com/tamadalab/liba@v1.0.0", []string{"MIT"}},
+		{"testdata/gomodcache/github.com/!tamada/libc@v1.1.0", []string{"ISC"}},
+		{"testdata/unknownproject", []string{}},
+	}
+	context := NewContext(true, "json", 1)
+	for _, td := range testdata {
+		licenses := findLicensesInDir(NewPath(td.dir), context)
+		if len(licenses) != len(td.wontSpdxID) {
+			t.Errorf("%s: license count did not match, wont %d, got %d", td.dir, len(td.wontSpdxID), len(licenses))
+			continue
+		}
+		for i, license := range licenses {
+			if license.SpdxID != td.wontSpdxID[i] {
+				t.Errorf("%s: license[%d] did not match, wont %s, got %s", td.dir, i, td.wontSpdxID[i], license.SpdxID)
+			}
+		}
+	}
+}
+
+func TestParseSpdxExpression(t *testing.T) {
+	testdata := []struct {
+		expression string
+		wontSpdxID []string
+		wontNames  []string
+	}{
+		{"MIT", []string{"MIT"}, []string{"MIT License"}},
+		{"(MIT OR Apache-2.0)", []string{"MIT", "Apache-2.0"}, []string{"MIT License", "Apache License 2.0"}},
+		{"MIT/Apache-2.0", []string{"MIT", "Apache-2.0"}, []string{"MIT License", "Apache License 2.0"}},
+		{"GPL-3.0-or-later AND MIT", []string{"GPL-3.0-or-later", "MIT"}, []string{"GNU General Public License v3.0 or later", "MIT License"}},
+		{"Apache-2.0 WITH LLVM-exception", []string{"Apache-2.0 WITH LLVM-exception"}, []string{"Apache-2.0 WITH LLVM-exception"}},
+		{"", []string{}, []string{}},
+	}
+	for _, td := range testdata {
+		licenses := parseSpdxExpression(td.expression)
+		if len(licenses) != len(td.wontSpdxID) {
+			t.Errorf("parseSpdxExpression(%s) license count did not match, wont %d, got %d", td.expression, len(td.wontSpdxID), len(licenses))
+			continue
+		}
+		for i, license := range licenses {
+			if license.SpdxID != td.wontSpdxID[i] || license.Name != td.wontNames[i] {
+				t.Errorf("parseSpdxExpression(%s)[%d] did not match, wont %s (%s), got %s (%s)", td.expression, i, td.wontSpdxID[i], td.wontNames[i], license.SpdxID, license.Name)
+			}
+		}
+	}
+}
+
+func TestClassifyLicenseName(t *testing.T) {
+	testdata := []struct {
+		name        string
+		successFlag bool
+		wontSpdxID  string
+	}{
+		{"MIT", true, "MIT"},
+		{"The MIT License", true, "MIT"},
+		{"Apache License, Version 2.0", true, "Apache-2.0"},
+		{"http://www.apache.org/licenses/LICENSE-2.0.txt", true, "Apache-2.0"},
+		{"https://www.gnu.org/licenses/old-licenses/lgpl-2.1.html", true, "LGPL-2.1-only"},
+		{"GPL-2.0", true, "GPL-2.0-only"},
+		{"LGPL-2.1+", true, "LGPL-2.1-or-later"},
+		{"Proprietary", false, "unknown"},
+		{"https://example.com/LICENSE", false, "unknown"},
+	}
+	for _, td := range testdata {
+		license, ok := classifyLicenseName(td.name)
+		if ok != td.successFlag || license.SpdxID != td.wontSpdxID {
+			t.Errorf("classifyLicenseName(%q) did not match, wont %s (%v), got %s (%v)", td.name, td.wontSpdxID, td.successFlag, license.SpdxID, ok)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package purplecat
+
+import (
+	"regexp"
+	"strings"
+)
+
+// licenseConfidenceThreshold is the minimum confidence for accepting the result of classifyLicenseText.
+const licenseConfidenceThreshold = 0.8
+
+// licenseShingleSize is the number of the words in a shingle for comparing the license texts.
+const licenseShingleSize = 3
+
+// licenseTemplateVariable is the placeholder of the variable parts in the license templates.
+const licenseTemplateVariable = "<<var>>"
+
+// licenseLineMarkerPattern matches the comment markers (`#`, `//`, `/*`, `*`, and so on) and the list markers (`1.`, `1.1.`, `(a)`, `-`, and so on)
+// at the beginning of the lines, which vary among the copies of the same license.
+var licenseLineMarkerPattern = regexp.MustCompile(`(?m)^[ \t]*(?:[#;*]+|//+|/\*+|--+)?[ \t]*(?:(?:\d+\.)+|\(?[a-z0-9]\)|[-*•])?[ \t]+`)
+
+var licenseWordPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+var licenseWordReplacer = strings.NewReplacer("licence", "license", "&", " and ", "https://", "http://")
+
+//go:generate go run spdxtemplates_gen.go
+
+// spdxLicenseTemplate is the text of the license in the SPDX license list, the variable parts (e.g., the copyright holders) are
+// written as `<<var>>`. The standard headers for the source files are also the templates of the licenses.
+// The templates are generated from assets/spdx into spdxtemplates.go.
+type spdxLicenseTemplate struct {
+	spdxID string
+	name   string
+	text   string
+}
+
+// licenseTemplate is the compiled spdxLicenseTemplate, which holds the shingles of the template text.
+type licenseTemplate struct {
+	spdxID   string
+	name     string
+	shingles map[string]bool
+}
+
+// licenseTemplates is the templates compiled from spdxLicenseTemplateCorpus.
+var licenseTemplates = compileLicenseTemplates(spdxLicenseTemplateCorpus)
+
+func compileLicenseTemplates(corpus []*spdxLicenseTemplate) []*licenseTemplate {
+	templates := []*licenseTemplate{}
+	for _, template := range corpus {
+		shingles := map[string]bool{}
+		// the shingles do not go across the variable parts, since the words of the variable parts are unknown.
+		for _, segment := range strings.Split(template.text, licenseTemplateVariable) {
+			for shingle := range licenseShingles(segment) {
+				shingles[shingle] = true
+			}
+		}
+		templates = append(templates, &licenseTemplate{spdxID: template.spdxID, name: template.name, shingles: shingles})
+	}
+	return templates
+}
+
+// licenseWords splits the given license text into the normalized words, which ignore the case, the punctuations,
+// the line markers, and the spelling variants (e.g., `licence` and `license`).
+func licenseWords(text string) []string {
+	text = licenseLineMarkerPattern.ReplaceAllString(strings.ToLower(text), "")
+	return licenseWordPattern.FindAllString(licenseWordReplacer.Replace(text), -1)
+}
+
+// licenseShingles returns the set of the sequences of licenseShingleSize words in the given text.
+func licenseShingles(text string) map[string]bool {
+	words := licenseWords(text)
+	shingles := map[string]bool{}
+	for i := 0; i+licenseShingleSize <= len(words); i++ {
+		shingles[strings.Join(words[i:i+licenseShingleSize], " ")] = true
+	}
+	return shingles
+}
+
+// confidence returns the ratio of the shingles of the receiver template found in the given shingles of the license text.
+func (template *licenseTemplate) confidence(shingles map[string]bool) (float64, int) {
+	if len(template.shingles) == 0 {
+		return 0, 0
+	}
+	matched := 0
+	for shingle := range template.shingles {
+		if shingles[shingle] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(template.shingles)), matched
+}
+
+// classifyLicenseText finds the best-matching license of the given license text by the SPDX license templates, and returns it
+// with the confidence (from 0.0 to 1.0), which is the ratio of the template text found in the given text.
+// The template matched with more words is chosen if the confidences are equal (e.g., BSD-3-Clause is preferred to BSD-2-Clause).
+// If no templates are matched, this function returns UnknownLicense and 0.
+func classifyLicenseText(text string) (*License, float64) {
+	shingles := licenseShingles(text)
+	var best *licenseTemplate
+	bestConfidence, bestMatched := 0.0, 0
+	for _, template := range licenseTemplates {
+		confidence, matched := template.confidence(shingles)
+		if confidence > bestConfidence || (confidence == bestConfidence && matched > bestMatched) {
+			best, bestConfidence, bestMatched = template, confidence, matched
+		}
+	}
+	if best == nil || bestMatched == 0 {
+		return UnknownLicense, 0
+	}
+	return newSpdxLicense(best.spdxID, best.name), bestConfidence
+}
@@ -0,0 +1,102 @@
+package purplecat
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestClassifyLicenseText(t *testing.T) {
+	testdata := []struct {
+		givePath       string
+		wontSpdxID     string
+		wontConfidence float64
+	}{
+		{"testdata/licenses/MIT.txt", "MIT", 1.0},
+		{"testdata/licenses/BSD-3-Clause.txt", "BSD-3-Clause", 0.95},
+		{"testdata/licenses/BSD-2-Clause.txt", "BSD-2-Clause", 1.0},
+		{"testdata/licenses/0BSD.txt", "0BSD", 1.0},
+		{"testdata/licenses/Apache-2.0-header.java", "Apache-2.0", 1.0},
+		{"testdata/licenses/BSL-1.0.txt", "BSL-1.0", 0.95},
+		{"testdata/licenses/EPL-1.0.txt", "EPL-1.0", 0.95},
+		{"testdata/licenses/Apache-2.0.txt", "Apache-2.0", 0.95},
+		{"testdata/licenses/MPL-2.0.txt", "MPL-2.0", 0.95},
+		{"testdata/licenses/GPL-2.0-only.txt", "GPL-2.0-only", 0.95},
+		{"testdata/licenses/GPL-3.0-only.txt", "GPL-3.0-only", 0.95},
+		{"testdata/licenses/LGPL-2.1-only.txt", "LGPL-2.1-only", 0.95},
+		{"testdata/licenses/AGPL-3.0-only.txt", "AGPL-3.0-only", 0.95},
+	}
+	for _, td := range testdata {
+		data, err := ioutil.ReadFile(td.givePath)
+		if err != nil {
+			t.Errorf("%s: read failed: %s", td.givePath, err.Error())
+			continue
+		}
+		license, confidence := classifyLicenseText(string(data))
+		if license.SpdxID != td.wontSpdxID || confidence < td.wontConfidence {
+			t.Errorf("classifyLicenseText(%s) did not match, wont %s (>= %f), got %s (%f)", td.givePath, td.wontSpdxID, td.wontConfidence, license.SpdxID, confidence)
+		}
+	}
+}
+
+func TestClassifyLicenseTextWithLowConfidence(t *testing.T) {
+	testdata := []struct {
+		giveText string
+	}{
+		{"Copyright (c) 2020 Tamada Lab. All rights reserved."},
+		{"Permission is hereby granted, free of charge, to any person obtaining a copy"},
+		{""},
+	}
+	for _, td := range testdata {
+		if license, confidence := classifyLicenseText(td.giveText); confidence >= licenseConfidenceThreshold {
+			t.Errorf("classifyLicenseText(%q) wont be classified, got %s (%f)", td.giveText, license.SpdxID, confidence)
+		}
+	}
+}
+
+func TestClassifyLicenseByTemplates(t *testing.T) {
+	testdata := []struct {
+		givePath    string
+		successFlag bool
+		wontSpdxID  string
+	}{
+		{"testdata/licenses/BSL-1.0.txt", true, "BSL-1.0"},
+		{"testdata/licenses/BSD-2-Clause.txt", true, "BSD-2-Clause"},
+		{"testdata/licenses/GPL-3.0-only.txt", true, "GPL-3.0-only"},
+		{"testdata/licenses/GPL-2.0-or-later-header.txt", true, "GPL-2.0-or-later"},
+		{"testdata/licenses/proprietary.txt", false, "unknown"},
+	}
+	context := NewContext(true, "json", 1)
+	for _, td := range testdata {
+		license, ok := readLicense(NewPath(td.givePath), context)
+		if ok != td.successFlag || license.SpdxID != td.wontSpdxID {
+			t.Errorf("readLicense(%s) did not match, wont %s (%v), got %s (%v)", td.givePath, td.wontSpdxID, td.successFlag, license.SpdxID, ok)
+		}
+	}
+}
+
+func TestBuildLicense(t *testing.T) {
+	testdata := []struct {
+		giveXML    string
+		wontName   string
+		wontSpdxID string
+	}{
+		{"<license><name>The Apache Software License, Version 2.0</name><url>http://www.apache.org/licenses/LICENSE-2.0.txt</url></license>", "The Apache Software License, Version 2.0", "Apache-2.0"},
+		{"<license><name>MIT</name></license>", "MIT", "MIT"},
+		{"<license><name>EPL</name><url>https://www.eclipse.org/legal/epl-2.0/</url></license>", "EPL", "EPL-2.0"},
+		{"<license><name>Custom License</name><url>https://example.com/LICENSE</url></license>", "Custom License", ""},
+	}
+	for _, td := range testdata {
+		doc, err := xmlquery.Parse(strings.NewReader(td.giveXML))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.giveXML, err.Error())
+			continue
+		}
+		license := buildLicense(xmlquery.FindOne(doc, "/license"))
+		if license.Name != td.wontName || license.SpdxID != td.wontSpdxID {
+			t.Errorf("buildLicense(%s) did not match, wont %s (%s), got %s (%s)", td.giveXML, td.wontName, td.wontSpdxID, license.Name, license.SpdxID)
+		}
+	}
+}
@@ -2,8 +2,9 @@ package purplecat
 
 import (
 	"fmt"
-	"path"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/antchfx/xmlquery"
@@ -16,6 +17,9 @@ const (
 	mavenCentralRepository = "repo.maven.apache.org/maven2/"
 )
 
+// MavenLocalRepositoryEnvName is the environment name for the path of the local repository, which overrides `~/.m2/repository`.
+const MavenLocalRepositoryEnvName = "PURPLECAT_MAVEN_LOCAL_REPOSITORY"
+
 type artifact struct {
 	groupID    string
 	artifactID string
@@ -51,9 +55,18 @@ func (artifact *artifact) Name() string {
 	return fmt.Sprintf("%s/%s/%s", artifact.groupID, artifact.artifactID, artifact.version)
 }
 
+func (artifact *artifact) copy() *artifact {
+	return newArtifact(artifact.groupID, artifact.artifactID, artifact.version)
+}
+
 func (artifact *artifact) repoPath() string {
+	return fmt.Sprintf("%s/%s", artifact.artifactDirPath(), artifact.version)
+}
+
+// artifactDirPath returns the path of the directory of all versions of the artifact in the repositories.
+func (artifact *artifact) artifactDirPath() string {
 	path := strings.ReplaceAll(artifact.groupID, ".", "/")
-	return fmt.Sprintf("%s/%s/%s", path, artifact.artifactID, artifact.version)
+	return fmt.Sprintf("%s/%s", path, artifact.artifactID)
 }
 
 func (artifact *artifact) pomPath() string {
@@ -91,6 +104,9 @@ func (mp *mavenParser) Parse(pomPath *Path) (*Project, error) {
 	if !pomPath.Exists(mp.context) {
 		return nil, fmt.Errorf("%s: not maven project (pom.xml not found)", pomPath.Path)
 	}
+	if reactor := collectMavenReactor(pomPath, mp.context); reactor.isMultiModule() {
+		return reactor.parse(mp.context)
+	}
 	return parsePom(pomPath, mp.context, 0)
 }
 
@@ -103,141 +119,175 @@ func readXML(pomPath *Path, context *Context) (*xmlquery.Node, error) {
 	return xmlquery.Parse(pom)
 }
 
+// parsePom parses the given pom as the root of the dependency graph.
 func parsePom(pomPath *Path, context *Context, currentDepth int) (*Project, error) {
-	if context.Depth < currentDepth {
-		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", context.Depth, currentDepth)
-	}
-	logger.Infof("parsePom(%s, %d)", pomPath.Path, currentDepth)
-	doc, err := readXML(pomPath, context)
-	if err != nil {
-		return nil, err
-	}
-	return parsePomProject(doc, pomPath, context, currentDepth)
+	return newMavenResolver(context, context.Cache).resolve(pomPath, rootMavenNode(), currentDepth)
 }
 
-func parsePomProject(doc *xmlquery.Node, pomPath *Path, context *Context, currentDepth int) (*Project, error) {
-	project, err := constructProject(doc, pomPath.Dir(), context, currentDepth)
-	if err != nil {
-		return nil, err
-	}
-	for _, dep := range project.Deps {
-		if _, ok := context.SearchCache(dep); ok {
-			continue
+// findParentLicense returns the licenses declared in the nearest ancestor of the given model.
+func findParentLicense(model *mavenModel) Licenses {
+	for parent := model.parent; parent != nil; parent = parent.parent {
+		if licenses, ok := findLicensesFromPom(parent.artifact, parent.doc); ok {
+			return licenses
 		}
-		path, err := generatePomPath(dep, context)
-		if err == nil {
-			parsePom(path, context, currentDepth+1)
+		if licenses, ok := parent.profileLicenses(); ok {
+			return licenses
 		}
 	}
-	return project, nil
-	// return constructDependencyTree(doc, pomPath.Dir(), context, currentDepth)
+	return []*License{}
 }
 
-func hitCache(artifact *artifact, context *Context) (*Project, bool) {
-	if project, ok := context.SearchCache(artifact.Name()); ok {
-		return project, true
+// findMavenLicenses returns the licenses declared in the pom of the given model, its active profiles, or its nearest ancestor.
+func findMavenLicenses(model *mavenModel) (Licenses, bool) {
+	if licenses, ok := findLicensesFromPom(model.artifact, model.doc); ok {
+		return licenses, true
+	}
+	if licenses, ok := model.profileLicenses(); ok {
+		return licenses, true
 	}
-	return nil, false
+	licenses := findParentLicense(model)
+	return licenses, len(licenses) > 0
 }
 
-func findParentLicense(parent *artifact, context *Context, currentDepth int) []*License {
-	parentPomPath, err1 := constructPomPath(parent, context)
-	if err1 != nil {
-		return []*License{}
-	}
-	dep, err2 := parsePom(parentPomPath, context, currentDepth)
-	if err2 != nil {
-		return []*License{}
+// constructProject constructs the project of the given pom, and returns the nodes of its dependencies to be resolved.
+// The dependencies are read from the pom even if the artifact is cached, since they depend on the path from the root and the context.
+func constructProject(root *xmlquery.Node, path *Path, resolver *mavenResolver, currentDepth int, node *mavenNode) (*Project, []*mavenNode, error) {
+	context := resolver.context
+	artifact := parseProjectInfo(root)
+	if artifact == nil {
+		return nil, nil, fmt.Errorf("%s: not maven project", path.Path)
 	}
-	return dep.Licenses()
+	readProperties(root, artifact)
+	resolver.resolved[artifact.Name()] = true
+	model := buildMavenModel(artifact, root, path, context, map[string]bool{})
+	project := resolver.newProject(artifact.Name(), findCachedMavenLicenses(model, context))
+	return project, readDependencies(model, project, node, resolver), nil
 }
 
-func readProperties(node *xmlquery.Node, artifact *artifact) {
-	list, err := xmlquery.QueryAll(node, "/project/properties/*")
-	if err == nil {
-		for _, property := range list {
-			artifact.properties[property.Data] = property.InnerText()
-		}
+// findCachedMavenLicenses returns the licenses of the artifact of the given model from the cache, or its pom and its jar.
+// The found licenses are stored into the cache as the project without the dependencies, keyed by mavenCacheKey.
+func findCachedMavenLicenses(model *mavenModel, context *Context) Licenses {
+	key := mavenCacheKey(model.artifact, context)
+	if cached, ok := context.SearchCache(key); ok {
+		return cached.Licenses()
+	}
+	licenses, ok := findMavenLicenses(model)
+	if !ok {
+		licenses, _ = findLicensesFromJar(model.artifact, context)
 	}
+	context.NewProject(key, licenses)
+	return licenses
 }
 
-func constructProject(root *xmlquery.Node, path *Path, context *Context, currentDepth int) (*Project, error) {
-	artifact := parseProjectInfo(root)
-	if dep, ok := hitCache(artifact, context); ok {
-		return dep, nil
+// mavenCacheKey returns the key of the given artifact in the cache. The key contains the profiles selected by the context
+// (e.g., `com.example/foo/1.0.0?profiles=!dev,ci`), since the active profiles change the licenses of the artifact.
+func mavenCacheKey(artifact *artifact, context *Context) string {
+	if len(context.Profiles) == 0 {
+		return artifact.Name()
 	}
-	readProperties(root, artifact)
-	licenses, ok := findLicensesFromPom(artifact, root)
-	if !ok && artifact.parent != nil {
-		licenses = findParentLicense(artifact.parent, context, currentDepth)
+	profiles := []string{}
+	for _, profile := range context.Profiles {
+		profile = strings.TrimPrefix(profile, "+")
+		if strings.HasPrefix(profile, "-") {
+			profile = "!" + profile[1:]
+		}
+		profiles = append(profiles, profile)
 	}
-	project := context.NewProject(artifact.Name(), licenses)
-	context.RegisterCache(project)
-	return readDependencies(artifact, project, root)
+	sort.Strings(profiles)
+	return fmt.Sprintf("%s?profiles=%s", artifact.Name(), strings.Join(profiles, ","))
 }
 
-func constructCentralRepoPomPath(artifact *artifact) *Path {
-	url := path.Join(mavenCentralRepository, artifact.pomPath())
-	return NewPath("https://" + url)
+// localMavenRepositoryDir returns the directory of the local repository of Maven, which is given by the environment variable,
+// localRepository of the settings, or `~/.m2/repository` in this order.
+// The settings are the ones loaded once for the given context.
+func localMavenRepositoryDir(context *Context) string {
+	if dir := os.Getenv(MavenLocalRepositoryEnvName); dir != "" {
+		return dir
+	}
+	if dir := context.loadMavenSettings().localRepository; dir != "" {
+		return dir
+	}
+	home, _ := homedir.Dir()
+	return filepath.Join(home, localMavenRepository)
 }
 
-func constructLocalPomPath(artifact *artifact) *Path {
-	home, _ := homedir.Dir()
-	return NewPath(filepath.Join(home, localMavenRepository, artifact.pomPath()))
+func constructLocalPomPath(artifact *artifact, context *Context) *Path {
+	return NewPath(filepath.Join(localMavenRepositoryDir(context), artifact.pomPath()))
 }
 
 func generatePomPath(name string, context *Context) (*Path, error) {
 	items := strings.Split(name, "/")
 	artifact := newArtifact(items[0], items[1], items[2])
-	return constructPomPath(artifact, context)
+	return constructPomPath(artifact, context, nil)
 }
 
-func constructPomPath(art *artifact, context *Context) (*Path, error) {
-	pomPathGenerators := []func(*artifact) *Path{
-		constructLocalPomPath,
-		constructCentralRepoPomPath,
+// constructPomPath finds the pom of the given artifact from the local repository, and the repositories of the context
+// with the given declared ones in order. The repositories not serving the version of the artifact are skipped.
+// The SNAPSHOT artifacts are looked up by maven-metadata.xml, since they are stored as the timestamped files.
+func constructPomPath(art *artifact, context *Context, declared []*mavenRepository) (*Path, error) {
+	if pomPath := constructLocalPomPath(art, context); pomPath.Exists(context) {
+		return pomPath, nil
 	}
-	for _, generator := range pomPathGenerators {
-		pomPath := generator(art)
-		if pomPath.Exists(context) {
+	repositories := context.mavenRepositories(declared)
+	if isMavenSnapshot(art.version) {
+		if pomPath, ok := findLocalSnapshotPomPath(art, context); ok {
+			return pomPath, nil
+		}
+		if pomPath, ok := findRemoteSnapshotPomPath(art, repositories, context); ok {
 			return pomPath, nil
 		}
 	}
-	return nil, fmt.Errorf("%s: pom not found", art.Name())
-}
-
-func updateByProps(target string, props map[string]string) string {
-	for k, v := range props {
-		target = strings.ReplaceAll(target, fmt.Sprintf(`${%s}`, k), v)
+	for _, repository := range repositories {
+		if !repository.accepts(art.version) {
+			continue
+		}
+		if pomPath := repository.path(art.pomPath()); pomPath.Exists(context) {
+			return pomPath, nil
+		}
 	}
-	return target
-}
-
-func normalizeProject(target, base *artifact) *artifact {
-	target.version = updateByProps(target.version, base.properties)
-	target.artifactID = updateByProps(target.artifactID, base.properties)
-	target.groupID = updateByProps(target.groupID, base.properties)
-
-	return target
+	return nil, fmt.Errorf("%s: pom not found", art.Name())
 }
 
-func readDependencies(artifact *artifact, project *Project, root *xmlquery.Node) (*Project, error) {
-	dependencies, err := xmlquery.QueryAll(root, "/project/dependencies/dependency")
-	if err != nil {
-		return project, err
-	}
-	for _, dep := range dependencies {
-		dependency := newArtifactXPath(dep)
-		normalizeProject(dependency, artifact)
-		project.Deps = append(project.Deps, dependency.Name())
+// readDependencies appends the dependencies of the given model into the project with the attributes of the edges, the versions managed by
+// dependencyManagement of the ancestors and the imported BOMs are filled, and the version ranges are resolved. The dependencies omitted in
+// the path of the given node (by the exclusions, the optional flag, and the transitive scopes), and the scopes not accepted by the context
+// are skipped. The dependencies omitted by the version mediation are appended into the omitted dependencies with the reasons.
+func readDependencies(model *mavenModel, project *Project, node *mavenNode, resolver *mavenResolver) []*mavenNode {
+	children := []*mavenNode{}
+	for _, dependency := range model.dependencies() {
+		child, ok := node.child(dependency)
+		if !ok || !resolver.context.AcceptScope(child.scope) {
+			continue
+		}
+		child.repositories = append(append([]*mavenRepository{}, model.repositories...), node.repositories...)
+		resolveMavenVersionRange(dependency, resolver.context, child.repositories)
+		name := dependency.artifact().Name()
+		_, internal := resolver.reactor.findModule(name)
+		attribute := &DependencyAttribute{Scope: child.scope, Optional: dependency.optional, Internal: internal}
+		if omitted, ok := resolver.mediate(dependency); ok {
+			attribute.Omitted = omitted
+			project.OmittedDeps = append(project.OmittedDeps, name)
+		} else {
+			project.Deps = append(project.Deps, name)
+			children = append(children, child)
+		}
+		project.SetAttribute(name, attribute)
 	}
-	return project, nil
+	return children
 }
 
+// buildLicense creates the license of the given license element of the pom, whose SPDX ID is classified from the name, or the url.
+// The name and the url are kept as declared in the pom.
 func buildLicense(licenseNode *xmlquery.Node) *License {
 	licenseName, _ := getStringByXPath("name", licenseNode)
 	url, _ := getStringByXPath("url", licenseNode)
-	return &License{Name: licenseName, URL: url}
+	license := &License{Name: licenseName, URL: url}
+	if found, ok := classifyLicenseName(licenseName); ok {
+		license.SpdxID = found.SpdxID
+	} else if found, ok := classifyLicenseURL(url); ok {
+		license.SpdxID = found.SpdxID
+	}
+	return license
 }
 
 func findLicensesFromPom(artifact *artifact, root *xmlquery.Node) (Licenses, bool) {
@@ -263,7 +313,7 @@ func parentArtifact(root *xmlquery.Node) (*artifact, bool) {
 
 func parseProjectInfo(root *xmlquery.Node) *artifact {
 	node, err := xmlquery.Query(root, "/project")
-	if err != nil {
+	if err != nil || node == nil {
 		return nil
 	}
 	artifact := newArtifactXPath(node)
@@ -271,6 +321,9 @@ func parseProjectInfo(root *xmlquery.Node) *artifact {
 	if ok {
 		merge(artifact, parent)
 		artifact.parent = parent
+		artifact.properties["project.parent.groupId"] = parent.groupID
+		artifact.properties["project.parent.artifactId"] = parent.artifactID
+		artifact.properties["project.parent.version"] = parent.version
 	}
 	return artifact
 }
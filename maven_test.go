@@ -32,3 +32,10 @@ func validateDependencyTree(t *testing.T, tree *Project, wontProjectName, wontLi
 		t.Errorf("%s: license did not match, wont %s, got %s", wontProjectName, wontLicense, tree.Licenses()[0].Name)
 	}
 }
+
+func TestParseNotMavenProject(t *testing.T) {
+	parser := &mavenParser{NewContext(true, "json", 1)}
+	if _, err := parser.Parse(NewPath("testdata/mavennotprojectpom")); err == nil {
+		t.Errorf("testdata/mavennotprojectpom: Parse wont succeed, since pom.xml has no project element")
+	}
+}
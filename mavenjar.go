@@ -0,0 +1,196 @@
+package purplecat
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// localMavenJarPath returns the path of the jar of the given artifact in the local repository.
+func localMavenJarPath(artifact *artifact, context *Context) string {
+	return filepath.Join(localMavenRepositoryDir(context), artifact.repoPath(), fmt.Sprintf("%s-%s.jar", artifact.artifactID, artifact.version))
+}
+
+// findLicensesFromJar finds the licenses from META-INF of the jar of the given artifact, if the jar is located in the local repository.
+func findLicensesFromJar(artifact *artifact, context *Context) (Licenses, bool) {
+	jarPath := localMavenJarPath(artifact, context)
+	archive, err := zip.OpenReader(jarPath)
+	if err != nil {
+		logger.Debugf("%s: %s", jarPath, err.Error())
+		return Licenses{}, false
+	}
+	defer archive.Close()
+	licenses := findLicensesInJar(&archive.Reader, artifact)
+	logger.Debugf("findLicensesFromJar(%s): %d licenses", jarPath, len(licenses))
+	return licenses, len(licenses) > 0
+}
+
+// findLicensesInJar finds the licenses in META-INF of the given jar, from the embedded pom of the given artifact
+// (`META-INF/maven/<groupId>/<artifactId>/pom.xml`), Bundle-License of MANIFEST.MF, and the license files
+// (`LICENSE*`, `NOTICE*`, and so on) in this order. The licenses of the first found source are returned.
+func findLicensesInJar(archive *zip.Reader, art *artifact) Licenses {
+	finders := []func(*zip.Reader, *artifact) Licenses{
+		findLicensesInEmbeddedPom,
+		findLicensesInManifest,
+		findLicensesInJarLicenseFiles,
+	}
+	for _, finder := range finders {
+		if licenses := finder(archive, art); len(licenses) > 0 {
+			return licenses
+		}
+	}
+	return Licenses{}
+}
+
+func findJarEntry(archive *zip.Reader, name string) (*zip.File, bool) {
+	for _, file := range archive.File {
+		if file.Name == name {
+			return file, true
+		}
+	}
+	return nil, false
+}
+
+func findLicensesInEmbeddedPom(archive *zip.Reader, artifact *artifact) Licenses {
+	if artifact == nil {
+		return Licenses{}
+	}
+	file, ok := findJarEntry(archive, fmt.Sprintf("META-INF/maven/%s/%s/pom.xml", artifact.groupID, artifact.artifactID))
+	if !ok {
+		return Licenses{}
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return Licenses{}
+	}
+	defer reader.Close()
+	doc, err := xmlquery.Parse(reader)
+	if err != nil {
+		logger.Debugf("%s: %s", file.Name, err.Error())
+		return Licenses{}
+	}
+	licenses, _ := findLicensesFromPom(artifact, doc)
+	return licenses
+}
+
+func findLicensesInManifest(archive *zip.Reader, artifact *artifact) Licenses {
+	file, ok := findJarEntry(archive, "META-INF/MANIFEST.MF")
+	if !ok {
+		return Licenses{}
+	}
+	manifest, err := readJarManifest(file)
+	if err != nil {
+		logger.Debugf("%s: %s", file.Name, err.Error())
+		return Licenses{}
+	}
+	return parseBundleLicense(manifest["Bundle-License"])
+}
+
+// readJarManifest reads the main attributes of the given MANIFEST.MF. The lines beginning with a space continue the previous line.
+func readJarManifest(file *zip.File) (map[string]string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	manifest := map[string]string{}
+	scanner := bufio.NewScanner(reader)
+	key := ""
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "":
+			return manifest, nil
+		case strings.HasPrefix(line, " ") && key != "":
+			manifest[key] = manifest[key] + line[1:]
+		default:
+			if items := strings.SplitN(line, ":", 2); len(items) == 2 {
+				key = strings.TrimSpace(items[0])
+				manifest[key] = strings.TrimSpace(items[1])
+			}
+		}
+	}
+	return manifest, scanner.Err()
+}
+
+// parseBundleLicense parses Bundle-License header of OSGi, which is the comma separated list of the license names, SPDX IDs,
+// or urls with the optional attributes (e.g., `Apache-2.0;link="https://www.apache.org/licenses/LICENSE-2.0.txt"`).
+func parseBundleLicense(header string) Licenses {
+	licenses := Licenses{}
+	for _, clause := range splitManifestClauses(header, ',') {
+		items := splitManifestClauses(clause, ';')
+		name := strings.Trim(strings.TrimSpace(items[0]), `"`)
+		if name == "" || name == "<<EXTERNAL>>" {
+			continue
+		}
+		link := ""
+		for _, attribute := range items[1:] {
+			if kv := strings.SplitN(attribute, "=", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == "link" {
+				link = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+			}
+		}
+		license, ok := classifyLicenseName(name)
+		if !ok && link != "" {
+			license, ok = classifyLicenseURL(link)
+		}
+		if !ok {
+			license = &License{Name: name, URL: link}
+		}
+		licenses = appendLicenseIfAbsent(licenses, license)
+	}
+	return licenses
+}
+
+// splitManifestClauses splits the given header by the given separator, except the separators in the quoted strings.
+func splitManifestClauses(header string, separator rune) []string {
+	clauses := []string{}
+	quoted := false
+	start := 0
+	for i, c := range header {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == separator && !quoted:
+			clauses = append(clauses, header[start:i])
+			start = i + 1
+		}
+	}
+	return append(clauses, header[start:])
+}
+
+// findLicensesInJarLicenseFiles classifies the license files directly in META-INF of the given jar.
+// If the license files exist, but no licenses were classified, this function returns UnknownLicense.
+func findLicensesInJarLicenseFiles(archive *zip.Reader, artifact *artifact) Licenses {
+	licenses := Licenses{}
+	found := false
+	for _, file := range archive.File {
+		if path.Dir(file.Name) != "META-INF" || !isJarLicenseFileName(path.Base(file.Name)) {
+			continue
+		}
+		found = true
+		if license, ok := readLicenseInZip(file); ok {
+			licenses = appendLicenseIfAbsent(licenses, license)
+		}
+	}
+	if found && len(licenses) == 0 {
+		return Licenses{UnknownLicense}
+	}
+	return licenses
+}
+
+// isJarLicenseFileName checks the given file name is the license file in META-INF, e.g., LICENSE, LICENSE.txt, and NOTICE.md.
+func isJarLicenseFileName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, prefix := range []string{"LICENSE", "LICENCE", "NOTICE", "COPYING"} {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package purplecat
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func isArchiveDirName(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".jar" || ext == ".war" || ext == ".ear"
+}
+
+// buildJavaArchiveTree copies the given directory into a temporary directory, and the directories named as the archives
+// (e.g., `foo-1.0.0.jar/`) are zipped into the files. The archives in the archives are zipped recursively.
+func buildJavaArchiveTree(src string) (string, error) {
+	dest, err := ioutil.TempDir("", "purplecat")
+	if err != nil {
+		return "", err
+	}
+	return dest, filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == src {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dest, rel)
+		if info.IsDir() && !isArchiveDirName(info.Name()) {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := readArchiveEntry(path, info)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+func readArchiveEntry(path string, info os.FileInfo) ([]byte, error) {
+	if !info.IsDir() {
+		return ioutil.ReadFile(path)
+	}
+	buffer := &bytes.Buffer{}
+	archive := zip.NewWriter(buffer)
+	err := filepath.Walk(path, func(entry string, entryInfo os.FileInfo, err error) error {
+		if err != nil || entry == path || (entryInfo.IsDir() && !isArchiveDirName(entryInfo.Name())) {
+			return err
+		}
+		rel, _ := filepath.Rel(path, entry)
+		data, err := readArchiveEntry(entry, entryInfo)
+		if err != nil {
+			return err
+		}
+		writer, err := archive.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(writer, bytes.NewReader(data)); err != nil {
+			return err
+		}
+		if entryInfo.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func TestParseMavenWithJarLicenses(t *testing.T) {
+	repository, err := buildJavaArchiveTree("testdata/mavenjarrepository")
+	defer os.RemoveAll(repository)
+	if err != nil {
+		t.Errorf("testdata/mavenjarrepository: build failed: %s", err.Error())
+		return
+	}
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: repository})()
+	context := NewContext(true, "json", 1)
+	tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenjarproject"))
+	if err != nil {
+		t.Errorf("testdata/mavenjarproject: parse failed: %s", err.Error())
+		return
+	}
+	validateDependencyTree(t, tree, "com.example/jar4test/1.0.0", "MIT License", 3)
+	testdata := []struct {
+		giveName    string
+		wontSpdxIDs []string
+	}{
+		{"com.example/jar-pom/1.0.0", []string{"Apache-2.0"}},
+		{"com.example/jar-manifest/1.0.0", []string{"Apache-2.0", "MIT"}},
+		{"com.example/jar-license/1.0.0", []string{"MIT"}},
+	}
+	for _, td := range testdata {
+		project, ok := context.SearchCache(td.giveName)
+		if !ok {
+			t.Errorf("%s: not found", td.giveName)
+			continue
+		}
+		ids := []string{}
+		for _, license := range project.Licenses() {
+			ids = append(ids, license.SpdxID)
+		}
+		if strings.Join(ids, ",") != strings.Join(td.wontSpdxIDs, ",") {
+			t.Errorf("%s: licenses did not match, wont %v, got %v", td.giveName, td.wontSpdxIDs, ids)
+		}
+	}
+	if project, ok := context.SearchCache("com.example/jar-pom/1.0.0"); ok && joinLicenseNames(project) != "Apache License 2.0" {
+		t.Errorf("com.example/jar-pom/1.0.0: the licenses in the embedded pom wont be used, got %s", joinLicenseNames(project))
+	}
+}
+
+func TestParseBundleLicense(t *testing.T) {
+	testdata := []struct {
+		giveHeader  string
+		wontSpdxIDs []string
+		wontNames   []string
+	}{
+		{"Apache-2.0", []string{"Apache-2.0"}, []string{"Apache License 2.0"}},
+		{`"Apache License, Version 2.0";link="https://www.apache.org/licenses/LICENSE-2.0.txt", EPL-2.0`, []string{"Apache-2.0", "EPL-2.0"}, []string{"Apache License 2.0", "Eclipse Public License 2.0"}},
+		{"https://opensource.org/licenses/MIT", []string{"MIT"}, []string{"MIT License"}},
+		{`Custom License;link="https://www.eclipse.org/legal/epl-v10.html"`, []string{"EPL-1.0"}, []string{"Eclipse Public License 1.0"}},
+		{`Custom License;link="https://example.com/LICENSE"`, []string{""}, []string{"Custom License"}},
+		{"<<EXTERNAL>>", []string{}, []string{}},
+	}
+	for _, td := range testdata {
+		licenses := parseBundleLicense(td.giveHeader)
+		ids := []string{}
+		names := []string{}
+		for _, license := range licenses {
+			ids = append(ids, license.SpdxID)
+			names = append(names, license.Name)
+		}
+		if strings.Join(ids, ",") != strings.Join(td.wontSpdxIDs, ",") || strings.Join(names, ",") != strings.Join(td.wontNames, ",") {
+			t.Errorf("parseBundleLicense(%s) did not match, wont %v %v, got %v %v", td.giveHeader, td.wontSpdxIDs, td.wontNames, ids, names)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package purplecat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// mavenResolver resolves the dependency graph of the pom in breadth-first order, for selecting the nearest version of each artifact
+// in the manner of the nearest-wins strategy of Maven. The dependencies in the same depth are selected in the declared order.
+type mavenResolver struct {
+	context *Context
+	// selected is the versions of the artifacts selected in the graph, keyed by the management keys.
+	selected map[string]string
+	// resolved is the names of the artifacts parsed or enqueued in this run.
+	resolved map[string]bool
+	// projects is the projects resolved in this run. Their dependencies, omitted dependencies, and attributes depend on the paths
+	// in the graph of this run, therefore, only their licenses are stored into the cache of the context.
+	projects *overlayCacheDB
+	queue    []*mavenQueueItem
+	// reactor is the modules of the multi-module project, which are resolved from the local poms instead of the repositories.
+	reactor *mavenReactor
+}
+
+type mavenQueueItem struct {
+	path  *Path
+	node  *mavenNode
+	depth int
+}
+
+// newMavenResolver creates the resolver, whose projects are found before the projects of the given cache database.
+func newMavenResolver(context *Context, base CacheDB) *mavenResolver {
+	return &mavenResolver{context: context, selected: map[string]string{}, resolved: map[string]bool{}, projects: newOverlayCacheDB(base), queue: []*mavenQueueItem{}}
+}
+
+// newProject creates the project of the given name in this run.
+func (resolver *mavenResolver) newProject(name string, licenses Licenses) *Project {
+	project := &Project{PName: name, LicenseList: licenses, Deps: []string{}, context: resolver.projects}
+	resolver.projects.Register(project)
+	return project
+}
+
+// resolve parses the given pom, and its dependencies in breadth-first order.
+func (resolver *mavenResolver) resolve(pomPath *Path, node *mavenNode, currentDepth int) (*Project, error) {
+	project, err := resolver.parsePom(pomPath, node, currentDepth)
+	if err != nil {
+		return nil, err
+	}
+	resolver.resolveQueue()
+	return project, nil
+}
+
+func (resolver *mavenResolver) resolveQueue() {
+	for len(resolver.queue) > 0 {
+		item := resolver.queue[0]
+		resolver.queue = resolver.queue[1:]
+		resolver.parsePom(item.path, item.node, item.depth)
+	}
+}
+
+func (resolver *mavenResolver) parsePom(pomPath *Path, node *mavenNode, currentDepth int) (*Project, error) {
+	if resolver.context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", resolver.context.Depth, currentDepth)
+	}
+	logger.Infof("parsePom(%s, %d)", pomPath.Path, currentDepth)
+	doc, err := readXML(pomPath, resolver.context)
+	if err != nil {
+		return nil, err
+	}
+	project, children, err := constructProject(doc, pomPath.Dir(), resolver, currentDepth, node)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		resolver.enqueue(child, currentDepth+1)
+	}
+	return project, nil
+}
+
+// enqueue appends the given node into the queue for parsing, if the artifact is not resolved in this run yet.
+// The artifacts of system scope are not parsed, since they are not located in the repositories.
+func (resolver *mavenResolver) enqueue(node *mavenNode, depth int) {
+	artifact := node.dependency.artifact()
+	if resolver.resolved[artifact.Name()] || node.scope == "system" || resolver.context.Depth < depth {
+		return
+	}
+	resolver.resolved[artifact.Name()] = true
+	path, err := resolver.findPomPath(artifact, node.repositories)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+		return
+	}
+	resolver.queue = append(resolver.queue, &mavenQueueItem{path: path, node: node, depth: depth})
+}
+
+func (resolver *mavenResolver) findPomPath(artifact *artifact, repositories []*mavenRepository) (*Path, error) {
+	if module, ok := resolver.reactor.findModule(artifact.Name()); ok {
+		return module.path, nil
+	}
+	return constructPomPath(artifact, resolver.context, repositories)
+}
+
+// mediate selects the version of the given dependency, if the artifact is not selected yet.
+// Otherwise, it returns the reason for omitting the dependency, e.g., `omitted for conflict with 1.3.0`.
+func (resolver *mavenResolver) mediate(dependency *mavenDependency) (string, bool) {
+	key := dependency.managementKey()
+	selected, ok := resolver.selected[key]
+	if !ok {
+		resolver.selected[key] = dependency.version
+		return "", false
+	}
+	if selected == dependency.version {
+		return "omitted for duplicate", true
+	}
+	return "omitted for conflict with " + selected, true
+}
+
+// resolveMavenDependencies parses the poms of the dependencies (in the form of `groupId/artifactId/version`) of the given project.
+// The dependencies are treated as compile scope.
+func resolveMavenDependencies(project *Project, context *Context, currentDepth int) {
+	resolver := newMavenResolver(context, context.Cache)
+	project.context = resolver.projects
+	resolver.projects.Register(project)
+	for _, dep := range project.Deps {
+		items := strings.Split(dep, "/")
+		if len(items) < 3 {
+			continue
+		}
+		dependency := &mavenDependency{groupID: items[0], artifactID: items[1], version: items[2], scope: "compile", depType: "jar"}
+		resolver.mediate(dependency)
+		resolver.enqueue(&mavenNode{dependency: dependency, scope: "compile"}, currentDepth+1)
+	}
+	resolver.resolveQueue()
+}
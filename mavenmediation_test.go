@@ -0,0 +1,120 @@
+package purplecat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// findResolvedProject finds the project in the resolution of the given tree, whose dependencies and attributes are specific to the resolution.
+func findResolvedProject(tree *Project, name string) (*Project, bool) {
+	return tree.context.Find(name)
+}
+
+func TestParseMavenMediation(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(true, "json", 2)
+	tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenmediationproject"))
+	if err != nil {
+		t.Errorf("testdata/mavenmediationproject: parse failed: %s", err.Error())
+		return
+	}
+	if tree.Name() != "com.example/mediation4test/1.0.0" {
+		t.Errorf("project name did not match, wont com.example/mediation4test/1.0.0, got %s", tree.Name())
+	}
+	testdata := []struct {
+		giveName    string
+		wontDeps    []string
+		wontOmitted []string
+		wontReasons []string
+	}{
+		{"com.example/mediation4test/1.0.0", []string{"com.example/mediated-a/1.0.0", "com.example/lib-a/1.3.0", "com.example/mediated-c/1.0.0", "args4j/args4j/2.33"}, []string{}, []string{}},
+		{"com.example/mediated-a/1.0.0", []string{"com.example/mediated-b/1.5.0"}, []string{"com.example/lib-a/1.2.0"}, []string{"omitted for conflict with 1.3.0"}},
+		{"com.example/mediated-c/1.0.0", []string{}, []string{"com.example/mediated-b/1.1.0", "args4j/args4j/2.33"}, []string{"omitted for conflict with 1.5.0", "omitted for duplicate"}},
+	}
+	for _, td := range testdata {
+		project, ok := findResolvedProject(tree, td.giveName)
+		if !ok {
+			t.Errorf("%s: not found", td.giveName)
+			continue
+		}
+		if strings.Join(project.Deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("%s: dependencies did not match, wont %v, got %v", td.giveName, td.wontDeps, project.Deps)
+		}
+		if strings.Join(project.OmittedDeps, ",") != strings.Join(td.wontOmitted, ",") {
+			t.Errorf("%s: omitted dependencies did not match, wont %v, got %v", td.giveName, td.wontOmitted, project.OmittedDeps)
+		}
+		for i, omitted := range project.OmittedDeps {
+			if i < len(td.wontReasons) && project.Attribute(omitted).Omitted != td.wontReasons[i] {
+				t.Errorf("%s: reason did not match, wont %s, got %s", omitted, td.wontReasons[i], project.Attribute(omitted).Omitted)
+			}
+		}
+	}
+	if _, ok := context.SearchCache("com.example/lib-a/1.2.0"); ok {
+		t.Errorf("com.example/lib-a/1.2.0 wont be parsed")
+	}
+}
+
+func TestMavenResolutionKeepsEdges(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(true, "json", 1)
+	tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenreactorproject"))
+	if err != nil {
+		t.Errorf("testdata/mavenreactorproject: parse failed: %s", err.Error())
+		return
+	}
+	testdata := []struct {
+		giveRoot string
+		wontDeps []string
+	}{
+		{"com.example/reactor-core/1.0.0", []string{"args4j/args4j/2.33", "junit/junit/4.13.1"}},
+		{"com.example/reactor-app/1.0.0", []string{"args4j/args4j/2.33"}},
+	}
+	for _, td := range testdata {
+		root, ok := findResolvedProject(tree, td.giveRoot)
+		if !ok {
+			t.Errorf("%s: not found", td.giveRoot)
+			continue
+		}
+		core, ok := findResolvedProject(root, "com.example/reactor-core/1.0.0")
+		if !ok || strings.Join(core.Deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("%s: dependencies of com.example/reactor-core/1.0.0 did not match, wont %v, got %v", td.giveRoot, td.wontDeps, core)
+		}
+	}
+	cached, ok := context.SearchCache("com.example/reactor-core/1.0.0")
+	if !ok || len(cached.Deps) != 0 || len(cached.OmittedDeps) != 0 || cached.Attributes != nil {
+		t.Errorf("com.example/reactor-core/1.0.0: the cache wont have the edges of the resolutions, got %v", cached)
+	}
+	if got := joinLicenseNames(cached); got != "MIT License" {
+		t.Errorf("com.example/reactor-core/1.0.0: cached licenses did not match, wont MIT License, got %s", got)
+	}
+}
+
+func TestWriteOmittedDependencies(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	testdata := []struct {
+		format string
+		wont   string
+	}{
+		{"markdown", "        * com.example/lib-a/1.2.0 (omitted for conflict with 1.3.0): []"},
+		{"markdown", "        * args4j/args4j/2.33 (omitted for duplicate): [MIT License]"},
+		{"csv", "com.example/mediated-b/1.1.0 (omitted for conflict with 1.5.0),,com.example/mediated-c/1.0.0"},
+		{"json", `"omitted-dependencies":[{"project-name":"com.example/lib-a/1.2.0","license-names":[""],"scope":"compile","omitted":"omitted for conflict with 1.3.0"}]`},
+		{"yaml", "omitted:omitted for duplicate"},
+		{"xml", "<omitted-dependency>"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, td.format, 2)
+		tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenmediationproject"))
+		if err != nil {
+			t.Errorf("testdata/mavenmediationproject: parse failed: %s", err.Error())
+			continue
+		}
+		out := &bytes.Buffer{}
+		writer, _ := context.NewWriter(out)
+		writer.Write(tree)
+		if !strings.Contains(out.String(), td.wont) {
+			t.Errorf("%s: output wont contain %s, got %s", td.format, td.wont, out.String())
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package purplecat
+
+import (
+	"path/filepath"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// findMavenMetadataPaths returns the paths of maven-metadata.xml of the given artifact. The local repository stores them as
+// `maven-metadata-<repository id>.xml`, and the remote repositories (the ones of the context with the given declared ones) serve it as `maven-metadata.xml`.
+func findMavenMetadataPaths(artifact *artifact, context *Context, declared []*mavenRepository) []*Path {
+	paths := []*Path{}
+	locals, _ := filepath.Glob(filepath.Join(localMavenRepositoryDir(context), artifact.artifactDirPath(), "maven-metadata*.xml"))
+	for _, local := range locals {
+		paths = append(paths, NewPath(local))
+	}
+	for _, repository := range context.mavenRepositories(declared) {
+		if remote := repository.path(artifact.artifactDirPath() + "/maven-metadata.xml"); remote.Exists(context) {
+			paths = append(paths, remote)
+		}
+	}
+	return paths
+}
+
+// findMavenVersions returns the available versions of the given artifact listed in maven-metadata.xml of the repositories.
+func findMavenVersions(artifact *artifact, context *Context, declared []*mavenRepository) []string {
+	versions := []string{}
+	found := map[string]bool{}
+	for _, path := range findMavenMetadataPaths(artifact, context, declared) {
+		doc, err := readXML(path, context)
+		if err != nil {
+			logger.Debugf("%s: %s", path.Path, err.Error())
+			continue
+		}
+		list, _ := xmlquery.QueryAll(doc, "/metadata/versioning/versions/version")
+		for _, node := range list {
+			if version := node.InnerText(); !found[version] {
+				found[version] = true
+				versions = append(versions, version)
+			}
+		}
+	}
+	return versions
+}
+
+// resolveMavenVersionRange resolves the version range of the given dependency into the highest available version.
+// The version is left as it is, if it is not the range, or no versions are available in the range.
+func resolveMavenVersionRange(dependency *mavenDependency, context *Context, declared []*mavenRepository) {
+	if !isMavenVersionRange(dependency.version) {
+		return
+	}
+	versionRange, ok := parseMavenVersionRange(dependency.version)
+	if !ok {
+		logger.Warnf("%s: invalid version range", dependency.version)
+		return
+	}
+	if version, ok := versionRange.selectVersion(findMavenVersions(dependency.artifact(), context, declared)); ok {
+		logger.Debugf("%s:%s:%s: resolved to %s", dependency.groupID, dependency.artifactID, dependency.version, version)
+		dependency.version = version
+	}
+}
@@ -0,0 +1,46 @@
+package purplecat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindMavenVersions(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(true, "json", 1)
+	testdata := []struct {
+		giveArtifact *artifact
+		wont         []string
+	}{
+		{newArtifact("com.example", "mediated-b", ""), []string{"1.0.0", "1.1.0", "1.5.0", "2.0.0"}},
+		{newArtifact("com.example", "unknown", ""), []string{}},
+	}
+	for _, td := range testdata {
+		got := findMavenVersions(td.giveArtifact, context, nil)
+		if strings.Join(got, ",") != strings.Join(td.wont, ",") {
+			t.Errorf("findMavenVersions(%s) did not match, wont %v, got %v", td.giveArtifact.artifactDirPath(), td.wont, got)
+		}
+	}
+}
+
+func TestResolveMavenVersionRange(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(true, "json", 1)
+	testdata := []struct {
+		giveVersion string
+		wont        string
+	}{
+		{"[1.0,2.0)", "1.5.0"},
+		{"[1.0,)", "2.0.0"},
+		{"[1.1.0]", "1.1.0"},
+		{"[3.0,)", "[3.0,)"},
+		{"1.0.0", "1.0.0"},
+	}
+	for _, td := range testdata {
+		dependency := &mavenDependency{groupID: "com.example", artifactID: "mediated-b", version: td.giveVersion}
+		resolveMavenVersionRange(dependency, context, nil)
+		if dependency.version != td.wont {
+			t.Errorf("resolveMavenVersionRange(%s) did not match, wont %s, got %s", td.giveVersion, td.wont, dependency.version)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package purplecat
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// mavenDependency represents the dependency element in the pom, or the managed one in dependencyManagement.
+type mavenDependency struct {
+	groupID    string
+	artifactID string
+	version    string
+	// scope is empty if the scope is not declared (the default scope is compile, unless it is managed).
+	scope      string
+	depType    string
+	classifier string
+	optional   bool
+	exclusions []*mavenExclusion
+}
+
+// mavenExclusion represents the exclusion element of the dependency, whose groupId and artifactId may be the wildcard `*`.
+type mavenExclusion struct {
+	groupID    string
+	artifactID string
+}
+
+func (exclusion *mavenExclusion) matches(dependency *mavenDependency) bool {
+	return (exclusion.groupID == "*" || exclusion.groupID == dependency.groupID) &&
+		(exclusion.artifactID == "*" || exclusion.artifactID == dependency.artifactID)
+}
+
+func newMavenDependencyXPath(node *xmlquery.Node, properties map[string]string, context *Context) *mavenDependency {
+	dependency := &mavenDependency{}
+	fields := []struct {
+		xpath string
+		value *string
+	}{
+		{"./groupId", &dependency.groupID},
+		{"./artifactId", &dependency.artifactID},
+		{"./version", &dependency.version},
+		{"./scope", &dependency.scope},
+		{"./type", &dependency.depType},
+		{"./classifier", &dependency.classifier},
+	}
+	for _, field := range fields {
+		value, _ := getStringByXPath(field.xpath, node)
+		*field.value = updateByProps(value, properties, context)
+	}
+	if dependency.depType == "" {
+		dependency.depType = "jar"
+	}
+	optional, _ := getStringByXPath("./optional", node)
+	dependency.optional = updateByProps(optional, properties, context) == "true"
+	list, _ := xmlquery.QueryAll(node, "./exclusions/exclusion")
+	for _, exclusion := range list {
+		groupID, _ := getStringByXPath("./groupId", exclusion)
+		artifactID, _ := getStringByXPath("./artifactId", exclusion)
+		dependency.exclusions = append(dependency.exclusions, &mavenExclusion{groupID: updateByProps(groupID, properties, context), artifactID: updateByProps(artifactID, properties, context)})
+	}
+	return dependency
+}
+
+// managementKey returns the key for matching the dependency with the managed one, `groupId:artifactId:type[:classifier]`.
+func (dependency *mavenDependency) managementKey() string {
+	key := fmt.Sprintf("%s:%s:%s", dependency.groupID, dependency.artifactID, dependency.depType)
+	if dependency.classifier != "" {
+		key = key + ":" + dependency.classifier
+	}
+	return key
+}
+
+func (dependency *mavenDependency) artifact() *artifact {
+	return newArtifact(dependency.groupID, dependency.artifactID, dependency.version)
+}
+
+// isImport returns true if the given managed dependency is the BOM imported by `<scope>import</scope>`.
+func (dependency *mavenDependency) isImport() bool {
+	return dependency.scope == "import" && dependency.depType == "pom"
+}
+
+// mavenModel is the model of the pom built with its ancestors, in the manner of the model builder of Maven.
+type mavenModel struct {
+	artifact *artifact
+	doc      *xmlquery.Node
+	context  *Context
+	parent   *mavenModel
+	// profiles is the active profiles of the pom.
+	profiles []*xmlquery.Node
+	// repositories is the repositories declared in the pom, its active profiles, and its ancestors, in this order.
+	repositories []*mavenRepository
+	// managed is the dependency elements of dependencyManagement in the active profiles, the pom, and its ancestors, in this order.
+	managed []*xmlquery.Node
+	// management is the effective dependencyManagement interpolated by the properties of the pom, which is the managed dependencies
+	// (the entries of the descendants take precedence), and the entries of the imported BOMs.
+	management map[string]*mavenDependency
+}
+
+// buildMavenModel builds the model of the given pom. The properties of the active profiles and the ancestors are merged into the given artifact.
+// visited is the poms in building (the descendants, and the importing poms), which are not read again to avoid the cycles of parents and BOMs.
+func buildMavenModel(artifact *artifact, doc *xmlquery.Node, dir *Path, context *Context, visited map[string]bool) *mavenModel {
+	model := &mavenModel{artifact: artifact, doc: doc, context: context, management: map[string]*mavenDependency{}}
+	visited[artifact.Name()] = true
+	defer delete(visited, artifact.Name())
+	model.profiles = activeProfiles(doc, dir, artifact, context)
+	readProfileProperties(model.profiles, artifact)
+	repositories, _ := xmlquery.QueryAll(doc, "/project/repositories/repository")
+	for _, node := range append(repositories, model.queryProfiles("./repositories/repository")...) {
+		model.repositories = append(model.repositories, newMavenRepositoryXPath(node, artifact.properties, context))
+	}
+	managed, _ := xmlquery.QueryAll(doc, "/project/dependencyManagement/dependencies/dependency")
+	model.managed = append(model.queryProfiles("./dependencyManagement/dependencies/dependency"), managed...)
+	if artifact.parent != nil && !visited[artifact.parent.Name()] {
+		if parentDoc, parentDir, err := readParentPom(artifact.parent, doc, dir, context, model.repositories); err == nil {
+			parent := parseProjectInfo(parentDoc)
+			readProperties(parentDoc, parent)
+			model.parent = buildMavenModel(parent, parentDoc, parentDir, context, visited)
+			artifact.inheritProperties(parent)
+			model.managed = append(model.managed, model.parent.managed...)
+			model.repositories = append(model.repositories, model.parent.repositories...)
+		} else {
+			logger.Debugf("%s: %s", artifact.Name(), err.Error())
+		}
+	}
+	artifact.interpolate(context)
+	imports := []*mavenDependency{}
+	for _, node := range model.managed {
+		managed := newMavenDependencyXPath(node, artifact.properties, context)
+		if managed.isImport() {
+			imports = append(imports, managed)
+		} else if _, ok := model.management[managed.managementKey()]; !ok {
+			model.management[managed.managementKey()] = managed
+		}
+	}
+	for _, bom := range imports {
+		model.importBOM(bom, context, visited)
+	}
+	return model
+}
+
+// importBOM adds the effective dependencyManagement of the given BOM into the receiver model, if the entries are absent.
+func (model *mavenModel) importBOM(bom *mavenDependency, context *Context, visited map[string]bool) {
+	artifact := bom.artifact()
+	if visited[artifact.Name()] {
+		return
+	}
+	logger.Infof("importBOM(%s)", artifact.Name())
+	path, err := constructPomPath(artifact, context, model.repositories)
+	if err != nil {
+		logger.Debugf("%s: %s", model.artifact.Name(), err.Error())
+		return
+	}
+	doc, err := readXML(path, context)
+	if err != nil {
+		logger.Debugf("%s: %s", path.Path, err.Error())
+		return
+	}
+	bomArtifact := parseProjectInfo(doc)
+	if bomArtifact == nil {
+		logger.Debugf("%s: not maven project", path.Path)
+		return
+	}
+	readProperties(doc, bomArtifact)
+	bomModel := buildMavenModel(bomArtifact, doc, path.Dir(), context, visited)
+	for key, managed := range bomModel.management {
+		if _, ok := model.management[key]; !ok {
+			model.management[key] = managed
+		}
+	}
+}
+
+// dependencies returns the dependencies declared in the pom and the active profiles, whose versions and scopes are filled by the effective
+// dependencyManagement, and the managed exclusions are added. The dependencies in the profiles override the same ones in the pom.
+func (model *mavenModel) dependencies() []*mavenDependency {
+	dependencies := []*mavenDependency{}
+	indexes := map[string]int{}
+	list, _ := xmlquery.QueryAll(model.doc, "/project/dependencies/dependency")
+	for _, node := range append(list, model.queryProfiles("./dependencies/dependency")...) {
+		dependency := newMavenDependencyXPath(node, model.artifact.properties, model.context)
+		if managed, ok := model.management[dependency.managementKey()]; ok {
+			if dependency.version == "" {
+				dependency.version = managed.version
+			}
+			if dependency.scope == "" {
+				dependency.scope = managed.scope
+			}
+		}
+		if dependency.scope == "" {
+			dependency.scope = "compile"
+		}
+		if index, ok := indexes[dependency.managementKey()]; ok {
+			dependencies[index] = dependency
+			continue
+		}
+		indexes[dependency.managementKey()] = len(dependencies)
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies
+}
+
+// readParentPom reads the pom of the given parent from relativePath (`../pom.xml` by default) of the local project, or the repositories
+// with the given ones declared in the child.
+func readParentPom(parent *artifact, doc *xmlquery.Node, dir *Path, context *Context, declared []*mavenRepository) (*xmlquery.Node, *Path, error) {
+	relativePath, ok := getStringByXPath("/project/parent/relativePath", doc)
+	if !ok {
+		relativePath = "../pom.xml"
+	}
+	if relativePath != "" && dir != nil && dir.url == nil {
+		path := NewPath(filepath.Join(dir.Path, relativePath))
+		if filepath.Ext(path.Path) != ".xml" && filepath.Ext(path.Path) != ".pom" {
+			path = path.Join("pom.xml")
+		}
+		if parentDoc, err := readXML(path, context); err == nil {
+			if found := parseProjectInfo(parentDoc); found != nil && found.Name() == parent.Name() {
+				return parentDoc, path.Dir(), nil
+			}
+		}
+	}
+	path, err := constructPomPath(parent, context, declared)
+	if err != nil {
+		return nil, nil, err
+	}
+	parentDoc, err := readXML(path, context)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	if parseProjectInfo(parentDoc) == nil {
+		return nil, nil, fmt.Errorf("%s: not maven project", path.Path)
+	}
+	return parentDoc, path.Dir(), nil
+}
@@ -0,0 +1,81 @@
+package purplecat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMavenManagedVersions(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	parser := &mavenParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/mavenbomproject"))
+	if err != nil {
+		t.Errorf("testdata/mavenbomproject: parse failed: %s", err.Error())
+		return
+	}
+	validateDependencyTree(t, tree, "com.example/bom4test/1.0.0", "MIT License", 3)
+	wontDeps := []string{"args4j/args4j/2.33", "com.example/lib-a/1.3.0", "junit/junit/4.13.1"}
+	if strings.Join(tree.Deps, ",") != strings.Join(wontDeps, ",") {
+		t.Errorf("dependencies did not match, wont %v, got %v", wontDeps, tree.Deps)
+	}
+	validateDependencyTree(t, tree.Dependencies()[1], "com.example/lib-a/1.3.0", "The Apache Software License, Version 2.0", 0)
+}
+
+func TestBuildMavenModel(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(true, "json", 1)
+	path := NewPath("testdata/mavenbomproject/pom.xml")
+	doc, err := readXML(path, context)
+	if err != nil {
+		t.Errorf("%s: read failed: %s", path.Path, err.Error())
+		return
+	}
+	artifact := parseProjectInfo(doc)
+	readProperties(doc, artifact)
+	model := buildMavenModel(artifact, doc, path.Dir(), context, map[string]bool{})
+	testdata := []struct {
+		wontName  string
+		wontScope string
+	}{
+		{"args4j/args4j/2.33", "compile"},
+		{"com.example/lib-a/1.3.0", "compile"},
+		{"junit/junit/4.13.1", "test"},
+	}
+	dependencies := model.dependencies()
+	if len(dependencies) != len(testdata) {
+		t.Errorf("dependencies length did not match, wont %d, got %d", len(testdata), len(dependencies))
+		return
+	}
+	for i, td := range testdata {
+		if got := dependencies[i].artifact().Name(); got != td.wontName {
+			t.Errorf("dependencies[%d] did not match, wont %s, got %s", i, td.wontName, got)
+		}
+		if got := dependencies[i].scope; got != td.wontScope {
+			t.Errorf("%s: scope did not match, wont %s, got %s", td.wontName, td.wontScope, got)
+		}
+	}
+	if managed, ok := model.management["com.example:lib-a:jar"]; !ok || managed.version != "1.3.0" {
+		t.Errorf("com.example:lib-a:jar wont be managed with 1.3.0, got %v", managed)
+	}
+}
+
+func TestMavenDependencyManagementKey(t *testing.T) {
+	testdata := []struct {
+		giveDependency *mavenDependency
+		wontKey        string
+		wontImport     bool
+	}{
+		{&mavenDependency{groupID: "g", artifactID: "a", depType: "jar"}, "g:a:jar", false},
+		{&mavenDependency{groupID: "g", artifactID: "a", depType: "jar", classifier: "tests"}, "g:a:jar:tests", false},
+		{&mavenDependency{groupID: "g", artifactID: "bom", depType: "pom", scope: "import"}, "g:bom:pom", true},
+		{&mavenDependency{groupID: "g", artifactID: "a", depType: "jar", scope: "import"}, "g:a:jar", false},
+	}
+	for _, td := range testdata {
+		if got := td.giveDependency.managementKey(); got != td.wontKey {
+			t.Errorf("managementKey() did not match, wont %s, got %s", td.wontKey, got)
+		}
+		if got := td.giveDependency.isImport(); got != td.wontImport {
+			t.Errorf("%s: isImport() did not match, wont %v, got %v", td.wontKey, td.wontImport, got)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package purplecat
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// MavenJDKVersionEnvName is the environment name for the JDK version used by the jdk activation of the profiles.
+// If it is not set, purplecat reads the version from `$JAVA_HOME/release`.
+const MavenJDKVersionEnvName = "PURPLECAT_MAVEN_JDK_VERSION"
+
+// mavenOSNames maps GOOS into os.name of Java, in lower case.
+var mavenOSNames = map[string]string{"linux": "linux", "darwin": "mac os x", "windows": "windows", "freebsd": "freebsd", "openbsd": "openbsd", "netbsd": "netbsd", "solaris": "sunos", "aix": "aix"}
+
+// mavenOSArchs maps GOARCH into os.arch of Java.
+var mavenOSArchs = map[string]string{"amd64": "amd64", "386": "x86", "arm64": "aarch64", "arm": "arm", "ppc64le": "ppc64le", "s390x": "s390x"}
+
+// mavenProfileSelection returns the explicit selection of the given profile by the context, in the manner of `-P` option of Maven.
+// The profile ids prefixed with `!` or `-` are deactivated. The second result is false if the profile is not specified.
+func mavenProfileSelection(context *Context, id string) (bool, bool) {
+	for _, profile := range context.Profiles {
+		switch {
+		case profile == "!"+id || profile == "-"+id:
+			return false, true
+		case profile == id || profile == "+"+id:
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// activeProfiles returns the active profiles of the given pom. The profiles activated by default are active only if
+// no other profiles in the pom are active.
+func activeProfiles(doc *xmlquery.Node, dir *Path, artifact *artifact, context *Context) []*xmlquery.Node {
+	profiles, _ := xmlquery.QueryAll(doc, "/project/profiles/profile")
+	active := []*xmlquery.Node{}
+	defaults := []*xmlquery.Node{}
+	for _, profile := range profiles {
+		id, _ := getStringByXPath("./id", profile)
+		if selected, ok := mavenProfileSelection(context, id); ok {
+			if selected {
+				active = append(active, profile)
+			}
+			continue
+		}
+		if isActivatedProfile(profile, dir, artifact, context) {
+			active = append(active, profile)
+		} else if value, _ := getStringByXPath("./activation/activeByDefault", profile); value == "true" {
+			defaults = append(defaults, profile)
+		}
+	}
+	if len(active) == 0 {
+		active = defaults
+	}
+	for _, profile := range active {
+		id, _ := getStringByXPath("./id", profile)
+		logger.Infof("%s: profile %s is active", artifact.Name(), id)
+	}
+	return active
+}
+
+// isActivatedProfile returns true if all of the activation conditions (jdk, os, property, and file) of the given profile are satisfied.
+func isActivatedProfile(profile *xmlquery.Node, dir *Path, artifact *artifact, context *Context) bool {
+	activation, err := xmlquery.Query(profile, "./activation")
+	if err != nil || activation == nil {
+		return false
+	}
+	conditions := []struct {
+		xpath   string
+		matcher func(*xmlquery.Node) bool
+	}{
+		{"./jdk", matchJDKActivation},
+		{"./os", matchOSActivation},
+		{"./property", matchPropertyActivation},
+		{"./file", func(node *xmlquery.Node) bool { return matchFileActivation(node, dir, artifact, context) }},
+	}
+	found := false
+	for _, condition := range conditions {
+		node, err := xmlquery.Query(activation, condition.xpath)
+		if err != nil || node == nil {
+			continue
+		}
+		if !condition.matcher(node) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func matchNegatable(spec string, matcher func(string) bool) bool {
+	if strings.HasPrefix(spec, "!") {
+		return !matcher(strings.TrimPrefix(spec, "!"))
+	}
+	return matcher(spec)
+}
+
+func matchJDKActivation(node *xmlquery.Node) bool {
+	version, ok := mavenJDKVersion()
+	if !ok {
+		return false
+	}
+	return matchNegatable(strings.TrimSpace(node.InnerText()), func(spec string) bool {
+		if isMavenVersionRange(spec) {
+			versionRange, ok := parseMavenVersionRange(spec)
+			return ok && versionRange.contains(version)
+		}
+		return strings.HasPrefix(version, spec)
+	})
+}
+
+// mavenJDKVersion returns the JDK version from the environment variable, or `JAVA_VERSION` in `$JAVA_HOME/release`.
+func mavenJDKVersion() (string, bool) {
+	if version := os.Getenv(MavenJDKVersionEnvName); version != "" {
+		return version, true
+	}
+	javaHome := os.Getenv("JAVA_HOME")
+	if javaHome == "" {
+		return "", false
+	}
+	file, err := os.Open(filepath.Join(javaHome, "release"))
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "JAVA_VERSION=") {
+			return strings.Trim(strings.TrimPrefix(line, "JAVA_VERSION="), `"`), true
+		}
+	}
+	return "", false
+}
+
+func matchOSActivation(node *xmlquery.Node) bool {
+	fields := []struct {
+		xpath   string
+		matcher func(string) bool
+	}{
+		{"./name", func(name string) bool { return strings.EqualFold(name, mavenOSNames[runtime.GOOS]) }},
+		{"./family", matchOSFamily},
+		{"./arch", func(arch string) bool { return strings.EqualFold(arch, mavenOSArchs[runtime.GOARCH]) }},
+		{"./version", func(version string) bool { return false }},
+	}
+	for _, field := range fields {
+		if value, ok := getStringByXPath(field.xpath, node); ok && !matchNegatable(value, field.matcher) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchOSFamily(family string) bool {
+	switch strings.ToLower(family) {
+	case "windows", "dos":
+		return runtime.GOOS == "windows"
+	case "mac":
+		return runtime.GOOS == "darwin"
+	case "unix":
+		return runtime.GOOS != "windows"
+	}
+	return false
+}
+
+// matchPropertyActivation matches the property activation with the environment variables (`env.*`), since purplecat has no system properties.
+func matchPropertyActivation(node *xmlquery.Node) bool {
+	name, _ := getStringByXPath("./name", node)
+	expected, hasValue := getStringByXPath("./value", node)
+	lookup := func(name string) (string, bool) {
+		if strings.HasPrefix(name, "env.") {
+			return os.LookupEnv(strings.TrimPrefix(name, "env."))
+		}
+		return "", false
+	}
+	if strings.HasPrefix(name, "!") {
+		_, ok := lookup(strings.TrimPrefix(name, "!"))
+		return !ok
+	}
+	value, ok := lookup(name)
+	if !hasValue {
+		return ok
+	}
+	return matchNegatable(expected, func(expected string) bool { return ok && value == expected })
+}
+
+// matchFileActivation matches the file activation in the local project. `${basedir}` in the paths is the directory of the pom.
+// The poms without the local directory (e.g., the remote poms, and the posted ones to REST API) never match.
+func matchFileActivation(node *xmlquery.Node, dir *Path, artifact *artifact, context *Context) bool {
+	if dir == nil || dir.url != nil || dir.Path == "" {
+		return false
+	}
+	basedir, err := filepath.Abs(dir.Path)
+	if err != nil {
+		return false
+	}
+	props := map[string]string{"basedir": basedir, "project.basedir": basedir}
+	exists := func(path string) bool {
+		path = updateByProps(updateByProps(path, props, nil), artifact.properties, context)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(basedir, path)
+		}
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	if path, ok := getStringByXPath("./exists", node); ok && path != "" {
+		return exists(path)
+	}
+	if path, ok := getStringByXPath("./missing", node); ok && path != "" {
+		return !exists(path)
+	}
+	return false
+}
+
+// readProfileProperties overrides the properties of the given artifact by the ones of the given active profiles.
+func readProfileProperties(profiles []*xmlquery.Node, artifact *artifact) {
+	for _, profile := range profiles {
+		list, _ := xmlquery.QueryAll(profile, "./properties/*")
+		for _, property := range list {
+			artifact.properties[property.Data] = property.InnerText()
+		}
+	}
+}
+
+// queryProfiles returns the nodes of the given xpath in the active profiles of the receiver model.
+func (model *mavenModel) queryProfiles(xpath string) []*xmlquery.Node {
+	nodes := []*xmlquery.Node{}
+	for _, profile := range model.profiles {
+		list, _ := xmlquery.QueryAll(profile, xpath)
+		nodes = append(nodes, list...)
+	}
+	return nodes
+}
+
+// profileLicenses returns the licenses declared in the active profiles of the receiver model.
+func (model *mavenModel) profileLicenses() (Licenses, bool) {
+	licenses := []*License{}
+	for _, license := range model.queryProfiles("./licenses/license") {
+		licenses = append(licenses, buildLicense(license))
+	}
+	return licenses, len(licenses) > 0
+}
@@ -0,0 +1,145 @@
+package purplecat
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestParseMavenProfiles(t *testing.T) {
+	testdata := []struct {
+		giveProfiles []string
+		giveEnv      map[string]string
+		wontDeps     []string
+		wontLicense  string
+	}{
+		{[]string{}, map[string]string{}, []string{"args4j/args4j/2.33"}, "MIT License"},
+		{[]string{}, map[string]string{"PURPLECAT_TEST_PROFILE": "on"}, []string{"args4j/args4j/2.33", "com.example/lib-a/1.3.0"}, "MIT License"},
+		{[]string{}, map[string]string{"PURPLECAT_TEST_PROFILE": "off"}, []string{"args4j/args4j/2.33"}, "MIT License"},
+		{[]string{}, map[string]string{MavenJDKVersionEnvName: "17.0.2"}, []string{"args4j/args4j/2.33", "com.example/mediated-b/1.5.0"}, "MIT License"},
+		{[]string{}, map[string]string{MavenJDKVersionEnvName: "1.8.0_292"}, []string{"args4j/args4j/2.33"}, "MIT License"},
+		{[]string{"explicit", "!file"}, map[string]string{}, []string{"args4j/args4j/", "com.example/scoped/1.0.0"}, ""},
+		{[]string{"-file", "-missing"}, map[string]string{}, []string{"args4j/args4j/", "junit/junit/4.13.1"}, ""},
+		{[]string{"env"}, map[string]string{}, []string{"args4j/args4j/2.33", "com.example/lib-a/1.3.0"}, "MIT License"},
+	}
+	for _, td := range testdata {
+		td.giveEnv[MavenLocalRepositoryEnvName] = "testdata/mavenrepository"
+		reset := setEnv(td.giveEnv)
+		context := NewContext(true, "json", 0)
+		context.Profiles = td.giveProfiles
+		tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenprofileproject"))
+		reset()
+		if err != nil {
+			t.Errorf("testdata/mavenprofileproject: parse failed: %s", err.Error())
+			continue
+		}
+		if strings.Join(tree.Deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("profiles %v, env %v: dependencies did not match, wont %v, got %v", td.giveProfiles, td.giveEnv, td.wontDeps, tree.Deps)
+		}
+		if got := joinLicenseNames(tree); got != td.wontLicense {
+			t.Errorf("profiles %v, env %v: licenses did not match, wont %s, got %s", td.giveProfiles, td.giveEnv, td.wontLicense, got)
+		}
+	}
+}
+
+func TestParseMavenProfilesWithSharedCache(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	cache, _ := NewCacheDB(MemoryCache)
+	testdata := []struct {
+		giveProfiles []string
+		wontDeps     []string
+		wontLicense  string
+	}{
+		{[]string{}, []string{"args4j/args4j/2.33"}, "MIT License"},
+		{[]string{"explicit", "!file"}, []string{"args4j/args4j/", "com.example/scoped/1.0.0"}, ""},
+		{[]string{"env"}, []string{"args4j/args4j/2.33", "com.example/lib-a/1.3.0"}, "MIT License"},
+		{[]string{"-file", "+explicit"}, []string{"args4j/args4j/", "com.example/scoped/1.0.0"}, ""},
+		{[]string{}, []string{"args4j/args4j/2.33"}, "MIT License"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, "json", 0)
+		context.Cache = cache
+		context.Profiles = td.giveProfiles
+		tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenprofileproject"))
+		if err != nil {
+			t.Errorf("testdata/mavenprofileproject: parse failed: %s", err.Error())
+			continue
+		}
+		if strings.Join(tree.Deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("profiles %v: dependencies did not match, wont %v, got %v", td.giveProfiles, td.wontDeps, tree.Deps)
+		}
+		if got := joinLicenseNames(tree); got != td.wontLicense {
+			t.Errorf("profiles %v: licenses did not match, wont %s, got %s", td.giveProfiles, td.wontLicense, got)
+		}
+	}
+}
+
+func TestMavenCacheKey(t *testing.T) {
+	testdata := []struct {
+		giveProfiles []string
+		wontKey      string
+	}{
+		{[]string{}, "com.example/foo/1.0.0"},
+		{[]string{"ci", "-dev"}, "com.example/foo/1.0.0?profiles=!dev,ci"},
+		{[]string{"!dev", "+ci"}, "com.example/foo/1.0.0?profiles=!dev,ci"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, "json", 0)
+		context.Profiles = td.giveProfiles
+		if got := mavenCacheKey(newArtifact("com.example", "foo", "1.0.0"), context); got != td.wontKey {
+			t.Errorf("mavenCacheKey(%v) did not match, wont %s, got %s", td.giveProfiles, td.wontKey, got)
+		}
+	}
+}
+
+func TestMatchOSActivation(t *testing.T) {
+	family := "unix"
+	if runtime.GOOS == "windows" {
+		family = "windows"
+	}
+	testdata := []struct {
+		giveOS string
+		wont   bool
+	}{
+		{"<os><family>" + family + "</family></os>", true},
+		{"<os><family>!" + family + "</family></os>", false},
+		{"<os><name>" + mavenOSNames[runtime.GOOS] + "</name><arch>" + mavenOSArchs[runtime.GOARCH] + "</arch></os>", true},
+		{"<os><name>unknown-os</name></os>", false},
+		{"<os><name>!unknown-os</name></os>", true},
+		{"<os><family>" + family + "</family><arch>unknown-arch</arch></os>", false},
+	}
+	for _, td := range testdata {
+		doc, err := xmlquery.Parse(strings.NewReader(td.giveOS))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.giveOS, err.Error())
+			continue
+		}
+		if got := matchOSActivation(xmlquery.FindOne(doc, "/os")); got != td.wont {
+			t.Errorf("matchOSActivation(%s) did not match, wont %v, got %v", td.giveOS, td.wont, got)
+		}
+	}
+}
+
+func TestMavenProfileSelection(t *testing.T) {
+	context := NewContext(true, "json", 1)
+	context.Profiles = []string{"a", "+b", "!c", "-d"}
+	testdata := []struct {
+		giveID       string
+		wontSelected bool
+		wontOk       bool
+	}{
+		{"a", true, true},
+		{"b", true, true},
+		{"c", false, true},
+		{"d", false, true},
+		{"e", false, false},
+	}
+	for _, td := range testdata {
+		selected, ok := mavenProfileSelection(context, td.giveID)
+		if selected != td.wontSelected || ok != td.wontOk {
+			t.Errorf("mavenProfileSelection(%s) did not match, wont (%v, %v), got (%v, %v)", td.giveID, td.wontSelected, td.wontOk, selected, ok)
+		}
+	}
+}
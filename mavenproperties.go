@@ -0,0 +1,90 @@
+package purplecat
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+var mavenPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// mavenModelFields is the elements of the pom available as `${project.<field>}`, in addition to groupId, artifactId, and version.
+var mavenModelFields = []string{"name", "description", "url", "packaging", "inceptionYear"}
+
+func readProperties(node *xmlquery.Node, artifact *artifact) {
+	list, err := xmlquery.QueryAll(node, "/project/properties/*")
+	if err == nil {
+		for _, property := range list {
+			artifact.properties[property.Data] = property.InnerText()
+		}
+	}
+	for _, field := range mavenModelFields {
+		if value, ok := getStringByXPath("/project/"+field, node); ok {
+			artifact.properties["project."+field] = value
+		}
+	}
+}
+
+// inheritProperties copies the properties of the parent into the receiver artifact, if absent.
+// The properties of the model of the parent (`project.*`) are not inherited.
+func (artifact *artifact) inheritProperties(parent *artifact) {
+	for key, value := range parent.properties {
+		if _, ok := artifact.properties[key]; !ok && !strings.HasPrefix(key, "project.") {
+			artifact.properties[key] = value
+		}
+	}
+}
+
+// interpolate expands the properties in the coordinates of the receiver artifact, e.g., `${revision}` of CI-friendly versions.
+func (artifact *artifact) interpolate(context *Context) {
+	artifact.groupID = updateByProps(artifact.groupID, artifact.properties, context)
+	artifact.artifactID = updateByProps(artifact.artifactID, artifact.properties, context)
+	artifact.version = updateByProps(artifact.version, artifact.properties, context)
+	artifact.properties["project.groupId"] = artifact.groupID
+	artifact.properties["project.artifactId"] = artifact.artifactID
+	artifact.properties["project.version"] = artifact.version
+}
+
+// updateByProps expands the properties in the given string recursively. The references to the undefined properties and
+// the cyclic references are left as they are. `${settings.*}` is looked up in the settings of the given context, if it is not nil.
+func updateByProps(target string, props map[string]string, context *Context) string {
+	return expandMavenProperties(target, props, context, map[string]bool{})
+}
+
+func expandMavenProperties(target string, props map[string]string, context *Context, expanding map[string]bool) string {
+	return mavenPropertyPattern.ReplaceAllStringFunc(target, func(reference string) string {
+		key := reference[2 : len(reference)-1]
+		if expanding[key] {
+			logger.Debugf("%s: cyclic property reference", key)
+			return reference
+		}
+		value, ok := lookupMavenProperty(key, props, context)
+		if !ok {
+			return reference
+		}
+		expanding[key] = true
+		defer delete(expanding, key)
+		return expandMavenProperties(value, props, context, expanding)
+	})
+}
+
+// lookupMavenProperty returns the value of the given property from the properties of the pom, the environment variables (`env.*`),
+// and settings.xml (`settings.*`). The deprecated prefix `pom.` is treated as `project.`.
+func lookupMavenProperty(key string, props map[string]string, context *Context) (string, bool) {
+	if value, ok := props[key]; ok {
+		return value, true
+	}
+	switch {
+	case strings.HasPrefix(key, "env."):
+		return os.LookupEnv(strings.TrimPrefix(key, "env."))
+	case strings.HasPrefix(key, "settings.") && context != nil:
+		return context.loadMavenSettings().value(strings.TrimPrefix(key, "settings."))
+	case strings.HasPrefix(key, "pom."):
+		value, ok := props["project."+strings.TrimPrefix(key, "pom.")]
+		return value, ok
+	}
+	return "", false
+}
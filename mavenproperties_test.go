@@ -0,0 +1,79 @@
+package purplecat
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseMavenInheritedProperties(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository", "PURPLECAT_TEST_VERSION": "3.1.4"})()
+	parser := &mavenParser{context: NewContext(true, "json", 1)}
+	tree, err := parser.Parse(NewPath("testdata/mavenpropertiesproject"))
+	if err != nil {
+		t.Errorf("testdata/mavenpropertiesproject: parse failed: %s", err.Error())
+		return
+	}
+	if tree.Name() != "com.example/properties4test/2.0.0" {
+		t.Errorf("project name did not match, wont com.example/properties4test/2.0.0, got %s", tree.Name())
+	}
+	wontDeps := []string{"com.example/lib-a/1.3.0", "com.example/sibling/1.0.0", "com.example/from-env/3.1.4", "com.example/cyclic/${cyclic.a}"}
+	if strings.Join(tree.Deps, ",") != strings.Join(wontDeps, ",") {
+		t.Errorf("dependencies did not match, wont %v, got %v", wontDeps, tree.Deps)
+	}
+}
+
+func TestUpdateByProps(t *testing.T) {
+	defer setEnv(map[string]string{MavenSettingsEnvName: "testdata/mavensettings/settings.xml", "PURPLECAT_TEST_VERSION": "3.1.4"})()
+	props := map[string]string{
+		"project.version": "1.0.0",
+		"major":           "2",
+		"minor":           "${major}.1",
+		"nested":          "${minor}.0",
+		"self":            "${self}",
+		"a":               "${b}",
+		"b":               "x${a}",
+	}
+	testdata := []struct {
+		giveTarget string
+		wont       string
+	}{
+		{"1.0.0", "1.0.0"},
+		{"${project.version}", "1.0.0"},
+		{"${pom.version}", "1.0.0"},
+		{"${nested}", "2.1.0"},
+		{"${major}-${minor}", "2-2.1"},
+		{"${undefined}", "${undefined}"},
+		{"${self}", "${self}"},
+		{"${a}", "x${a}"},
+		{"${env.PURPLECAT_TEST_VERSION}", "3.1.4"},
+		{"${env.PURPLECAT_UNDEFINED_ENV}", "${env.PURPLECAT_UNDEFINED_ENV}"},
+		{"${settings.localRepository}", "/opt/maven/repository"},
+		{"${settings.offline}", "true"},
+	}
+	context := NewContext(true, "json", 1)
+	for _, td := range testdata {
+		if got := updateByProps(td.giveTarget, props, context); got != td.wont {
+			t.Errorf("updateByProps(%s) did not match, wont %s, got %s", td.giveTarget, td.wont, got)
+		}
+	}
+}
+
+func TestUpdateByPropsWithLoadedSettings(t *testing.T) {
+	defer setEnv(map[string]string{MavenSettingsEnvName: "testdata/mavensettings/settings.xml"})()
+	context := NewContext(true, "json", 1)
+	context.loadMavenSettings()
+	os.Setenv(MavenSettingsEnvName, "testdata/mavensettings/repositories.xml")
+	testdata := []struct {
+		giveContext *Context
+		wont        string
+	}{
+		{context, "/opt/maven/repository"},
+		{nil, "${settings.localRepository}"},
+	}
+	for _, td := range testdata {
+		if got := updateByProps("${settings.localRepository}", map[string]string{}, td.giveContext); got != td.wont {
+			t.Errorf("updateByProps(${settings.localRepository}) did not match, wont %s, got %s", td.wont, got)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package purplecat
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// mavenModule is the module of the multi-module project (reactor).
+type mavenModule struct {
+	artifact *artifact
+	path     *Path
+	// modules is the submodules declared in `<modules>` of the module.
+	modules []*mavenModule
+	// dependencies is the names of the dependencies of the module.
+	dependencies []string
+	project      *Project
+	// resolved is the projects resolved from the module as the root.
+	resolved *overlayCacheDB
+}
+
+// mavenReactor is the modules collected from the aggregator pom recursively.
+type mavenReactor struct {
+	root *mavenModule
+	// modules is the modules keyed by the names of the artifacts.
+	modules map[string]*mavenModule
+	// order is the modules in the declared order.
+	order []*mavenModule
+	// projects is the projects of the modules, which are found from the projects resolved from each module.
+	projects *overlayCacheDB
+}
+
+// collectMavenReactor collects the modules from the given aggregator pom recursively. The modules are collected only from
+// the local files and the urls, since the other poms (e.g., the posted ones to REST API) cannot be read twice, and have no locations of modules.
+func collectMavenReactor(pomPath *Path, context *Context) *mavenReactor {
+	reactor := &mavenReactor{modules: map[string]*mavenModule{}, order: []*mavenModule{}}
+	if _, local := pomPath.supporter.(*localFilePathSupporter); local || pomPath.url != nil {
+		reactor.root = reactor.collect(pomPath, context, map[string]bool{})
+	}
+	return reactor
+}
+
+func (reactor *mavenReactor) collect(pomPath *Path, context *Context, visited map[string]bool) *mavenModule {
+	if visited[pomPath.Path] {
+		return nil
+	}
+	visited[pomPath.Path] = true
+	doc, err := readXML(pomPath, context)
+	if err != nil {
+		logger.Warnf("%s: %s", pomPath.Path, err.Error())
+		return nil
+	}
+	artifact := parseProjectInfo(doc)
+	if artifact == nil {
+		logger.Warnf("%s: not maven project", pomPath.Path)
+		return nil
+	}
+	readProperties(doc, artifact)
+	model := buildMavenModel(artifact, doc, pomPath.Dir(), context, map[string]bool{})
+	module := &mavenModule{artifact: artifact, path: pomPath, modules: []*mavenModule{}, dependencies: []string{}}
+	for _, dependency := range model.dependencies() {
+		module.dependencies = append(module.dependencies, dependency.artifact().Name())
+	}
+	reactor.modules[artifact.Name()] = module
+	reactor.order = append(reactor.order, module)
+	list, _ := xmlquery.QueryAll(doc, "/project/modules/module")
+	for _, node := range append(list, model.queryProfiles("./modules/module")...) {
+		if submodule := reactor.collect(modulePomPath(pomPath.Dir(), strings.TrimSpace(node.InnerText())), context, visited); submodule != nil {
+			module.modules = append(module.modules, submodule)
+		}
+	}
+	return module
+}
+
+// modulePomPath returns the path of the pom of the given module, which is the directory or the pom file relative to the aggregator.
+func modulePomPath(dir *Path, module string) *Path {
+	path := dir.Join(module)
+	if ext := filepath.Ext(path.Path); ext != ".xml" && ext != ".pom" {
+		path = path.Join("pom.xml")
+	}
+	return path
+}
+
+func (reactor *mavenReactor) isMultiModule() bool {
+	return len(reactor.order) > 1
+}
+
+// findModule returns the module of the given artifact name, for resolving the internal dependencies without the repositories.
+func (reactor *mavenReactor) findModule(name string) (*mavenModule, bool) {
+	if reactor == nil {
+		return nil, false
+	}
+	module, ok := reactor.modules[name]
+	return module, ok
+}
+
+// sortedModules returns the modules in the order of the build, the modules depended by other modules come first.
+func (reactor *mavenReactor) sortedModules() []*mavenModule {
+	sorted := []*mavenModule{}
+	visited := map[*mavenModule]bool{}
+	var visit func(module *mavenModule)
+	visit = func(module *mavenModule) {
+		if visited[module] {
+			return
+		}
+		visited[module] = true
+		for _, dependency := range module.dependencies {
+			if depended, ok := reactor.modules[dependency]; ok {
+				visit(depended)
+			}
+		}
+		sorted = append(sorted, module)
+	}
+	for _, module := range reactor.order {
+		visit(module)
+	}
+	return sorted
+}
+
+// parse parses each module as the root of its dependency graph, and returns the project of the aggregator pom,
+// whose modules are the projects of the submodules.
+// Each module is resolved with its own projects, which are found with the projects of the other modules (e.g., from the aggregator).
+func (reactor *mavenReactor) parse(context *Context) (*Project, error) {
+	reactor.projects = newOverlayCacheDB(context.Cache)
+	for _, module := range reactor.sortedModules() {
+		resolver := newMavenResolver(context, reactor.projects)
+		resolver.reactor = reactor
+		project, err := resolver.resolve(module.path, rootMavenNode(), 0)
+		if err != nil {
+			if module == reactor.root {
+				return nil, err
+			}
+			logger.Warnf("%s: %s", module.path.Path, err.Error())
+			continue
+		}
+		module.project = project
+		module.resolved = resolver.projects
+		reactor.projects.Register(project)
+	}
+	for _, module := range reactor.order {
+		if module.project == nil {
+			continue
+		}
+		names := []string{}
+		for _, submodule := range module.modules {
+			if submodule.project != nil {
+				names = append(names, submodule.project.Name())
+			}
+		}
+		module.project.ModuleNames = names
+	}
+	if context.MergeModules {
+		return reactor.merge(context), nil
+	}
+	return reactor.root.project, nil
+}
+
+// merge returns the merged view of the reactor, whose dependencies are the third-party dependencies of all modules.
+// The merged project is not stored in the cache, since it has the same name as the aggregator.
+func (reactor *mavenReactor) merge(context *Context) *Project {
+	root := reactor.root.project
+	projects := newOverlayCacheDB(reactor.projects)
+	merged := &Project{PName: root.Name(), LicenseList: root.Licenses(), Deps: []string{}, context: projects}
+	found := map[string]bool{}
+	for _, module := range reactor.order {
+		if module.project == nil {
+			continue
+		}
+		for name, project := range module.resolved.projects {
+			if _, ok := projects.findInOverlay(name); !ok {
+				projects.Register(project)
+			}
+		}
+		for _, dep := range module.project.Deps {
+			if _, ok := reactor.findModule(dep); ok || found[dep] {
+				continue
+			}
+			found[dep] = true
+			merged.Deps = append(merged.Deps, dep)
+			if attribute := module.project.Attribute(dep); attribute != nil {
+				merged.SetAttribute(dep, attribute)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,109 @@
+package purplecat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseMavenReactor(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(true, "json", 1)
+	tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenreactorproject"))
+	if err != nil {
+		t.Errorf("testdata/mavenreactorproject: parse failed: %s", err.Error())
+		return
+	}
+	if tree.Name() != "com.example/reactor4test/1.0.0" {
+		t.Errorf("project name did not match, wont com.example/reactor4test/1.0.0, got %s", tree.Name())
+	}
+	testdata := []struct {
+		giveName     string
+		wontModules  []string
+		wontDeps     []string
+		wontInternal []string
+		wontLicense  string
+	}{
+		{"com.example/reactor4test/1.0.0", []string{"com.example/reactor-app/1.0.0", "com.example/reactor-core/1.0.0", "com.example/reactor-nested/1.0.0"}, []string{}, []string{}, "MIT License"},
+		{"com.example/reactor-app/1.0.0", []string{}, []string{"com.example/reactor-core/1.0.0", "com.example/lib-a/1.3.0"}, []string{"com.example/reactor-core/1.0.0"}, "MIT License"},
+		{"com.example/reactor-core/1.0.0", []string{}, []string{"args4j/args4j/2.33", "junit/junit/4.13.1"}, []string{}, "MIT License"},
+		{"com.example/reactor-nested/1.0.0", []string{"com.example/reactor-sub/1.0.0"}, []string{}, []string{}, "MIT License"},
+		{"com.example/reactor-sub/1.0.0", []string{}, []string{"com.example/reactor-app/1.0.0", "args4j/args4j/2.33"}, []string{"com.example/reactor-app/1.0.0"}, "MIT License"},
+	}
+	for _, td := range testdata {
+		project, ok := findResolvedProject(tree, td.giveName)
+		if !ok {
+			t.Errorf("%s: not found", td.giveName)
+			continue
+		}
+		if strings.Join(project.ModuleNames, ",") != strings.Join(td.wontModules, ",") {
+			t.Errorf("%s: modules did not match, wont %v, got %v", td.giveName, td.wontModules, project.ModuleNames)
+		}
+		if strings.Join(project.Deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("%s: dependencies did not match, wont %v, got %v", td.giveName, td.wontDeps, project.Deps)
+		}
+		internals := []string{}
+		for _, dep := range project.Deps {
+			if attribute := project.Attribute(dep); attribute != nil && attribute.Internal {
+				internals = append(internals, dep)
+			}
+		}
+		if strings.Join(internals, ",") != strings.Join(td.wontInternal, ",") {
+			t.Errorf("%s: internal dependencies did not match, wont %v, got %v", td.giveName, td.wontInternal, internals)
+		}
+		if got := joinLicenseNames(project); got != td.wontLicense {
+			t.Errorf("%s: licenses did not match, wont %s, got %s", td.giveName, td.wontLicense, got)
+		}
+	}
+}
+
+func TestParseMavenReactorMerged(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(true, "json", 1)
+	context.MergeModules = true
+	tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenreactorproject/pom.xml"))
+	if err != nil {
+		t.Errorf("testdata/mavenreactorproject: parse failed: %s", err.Error())
+		return
+	}
+	validateDependencyTree(t, tree, "com.example/reactor4test/1.0.0", "MIT License", 3)
+	wontDeps := []string{"com.example/lib-a/1.3.0", "args4j/args4j/2.33", "junit/junit/4.13.1"}
+	if strings.Join(tree.Deps, ",") != strings.Join(wontDeps, ",") {
+		t.Errorf("dependencies did not match, wont %v, got %v", wontDeps, tree.Deps)
+	}
+	if len(tree.ModuleNames) != 0 {
+		t.Errorf("merged project wont have modules, got %v", tree.ModuleNames)
+	}
+	if root, ok := findResolvedProject(tree, "com.example/reactor4test/1.0.0"); !ok || len(root.ModuleNames) != 3 {
+		t.Errorf("the project of the aggregator pom wont be replaced by the merged one")
+	}
+}
+
+func TestWriteModules(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	testdata := []struct {
+		format string
+		wont   string
+	}{
+		{"markdown", "    * com.example/reactor-app/1.0.0 (module): [MIT License]\n        * com.example/reactor-core/1.0.0 (module): [MIT License]"},
+		{"csv", "com.example/reactor-sub/1.0.0 (module),MIT License,com.example/reactor-nested/1.0.0"},
+		{"json", `"modules":[{"project-name":"com.example/reactor-app/1.0.0"`},
+		{"json", `{"project-name":"com.example/reactor-core/1.0.0","license-names":["MIT License"],"scope":"compile","internal":true`},
+		{"yaml", "modules:"},
+		{"xml", "<internal>true</internal>"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, td.format, 1)
+		tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenreactorproject"))
+		if err != nil {
+			t.Errorf("testdata/mavenreactorproject: parse failed: %s", err.Error())
+			continue
+		}
+		out := &bytes.Buffer{}
+		writer, _ := context.NewWriter(out)
+		writer.Write(tree)
+		if !strings.Contains(out.String(), td.wont) {
+			t.Errorf("%s: output wont contain %s, got %s", td.format, td.wont, out.String())
+		}
+	}
+}
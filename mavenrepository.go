@@ -0,0 +1,120 @@
+package purplecat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// mavenRepository is the remote repository for looking up the artifacts, declared in the pom, the settings, or the context.
+type mavenRepository struct {
+	id  string
+	url string
+	// releases and snapshots show the repository serves the release versions and the SNAPSHOT versions, respectively.
+	releases  bool
+	snapshots bool
+	username  string
+	password  string
+}
+
+// centralMavenRepository is the default repository of Maven, which is looked up after the other repositories.
+var centralMavenRepository = &mavenRepository{id: "central", url: "https://" + mavenCentralRepository, releases: true, snapshots: false}
+
+// newMavenRepositoryXPath creates the repository from the repository element of the pom or the settings.
+func newMavenRepositoryXPath(node *xmlquery.Node, properties map[string]string, context *Context) *mavenRepository {
+	value := func(xpath string) string {
+		value, _ := getStringByXPath(xpath, node)
+		return updateByProps(value, properties, context)
+	}
+	return &mavenRepository{
+		id:        value("./id"),
+		url:       value("./url"),
+		releases:  value("./releases/enabled") != "false",
+		snapshots: value("./snapshots/enabled") != "false",
+	}
+}
+
+// parseMavenRepository parses the repository given by the context in the form of `[id::]url`.
+// The id is generated from the given index, if it is omitted.
+func parseMavenRepository(spec string, index int) *mavenRepository {
+	id := fmt.Sprintf("repository%d", index+1)
+	url := spec
+	if items := strings.SplitN(spec, "::", 2); len(items) == 2 {
+		id, url = items[0], items[1]
+	}
+	return &mavenRepository{id: id, url: url, releases: true, snapshots: true}
+}
+
+func (repository *mavenRepository) copy() *mavenRepository {
+	copied := *repository
+	return &copied
+}
+
+// isLocal returns true if the receiver repository is located in the local host, which is not matched by `external:*` of the mirrors.
+func (repository *mavenRepository) isLocal() bool {
+	path := NewPath(repository.url)
+	if path.url == nil {
+		return true
+	}
+	host := path.url.Hostname()
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// accepts checks the receiver repository serves the given version, by the release and snapshot policies.
+func (repository *mavenRepository) accepts(version string) bool {
+	if isMavenSnapshot(version) {
+		return repository.snapshots
+	}
+	return repository.releases
+}
+
+// path returns the path of the given relative path in the receiver repository, which is accessed with the credentials of the repository.
+func (repository *mavenRepository) path(relative string) *Path {
+	return NewPathWithCredentials(strings.TrimSuffix(repository.url, "/")+"/"+strings.TrimPrefix(relative, "/"), repository.username, repository.password)
+}
+
+// mavenRepositories returns the repositories to be looked up in order, the repositories of the context, the active profiles of the settings,
+// the given declared ones (in the pom and its ancestors), and the central repository. The repositories are replaced by their mirrors,
+// and the credentials are filled by the servers of the settings. The repositories of the same id (e.g., served by the same mirror) are looked up once.
+// The credentials are not filled for the declared repositories unless they are mirrored, since the poms may be given by the clients of REST API,
+// and the declared urls are not always the servers of the same ids in the settings.
+func (context *Context) mavenRepositories(declared []*mavenRepository) []*mavenRepository {
+	settings := context.loadMavenSettings()
+	untrusted := map[*mavenRepository]bool{}
+	for _, repository := range declared {
+		untrusted[repository] = true
+	}
+	candidates := []*mavenRepository{}
+	for index, spec := range context.Repositories {
+		candidates = append(candidates, parseMavenRepository(spec, index))
+	}
+	candidates = append(candidates, settings.repositories...)
+	candidates = append(candidates, declared...)
+	candidates = append(candidates, centralMavenRepository)
+	repositories := []*mavenRepository{}
+	found := map[string]*mavenRepository{}
+	for _, candidate := range candidates {
+		repository := candidate.copy()
+		mirror, mirrored := settings.findMirror(candidate)
+		if mirrored {
+			repository.id, repository.url = mirror.id, mirror.url
+		}
+		if repository.url == "" {
+			continue
+		}
+		if same, ok := found[repository.id]; ok {
+			if mirrored {
+				same.releases = same.releases || repository.releases
+				same.snapshots = same.snapshots || repository.snapshots
+			}
+			continue
+		}
+		found[repository.id] = repository
+		if server, ok := settings.servers[repository.id]; ok && (mirrored || !untrusted[candidate]) {
+			repository.username, repository.password = server.username, server.password
+		}
+		repositories = append(repositories, repository)
+	}
+	return repositories
+}
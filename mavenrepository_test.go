@@ -0,0 +1,146 @@
+package purplecat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newMavenRepositoryServer serves testdata/mavenremoterepository under `/maven2/`, with the basic authentication.
+func newMavenRepositoryServer(username, password string) *httptest.Server {
+	files := http.StripPrefix("/maven2/", http.FileServer(http.Dir("testdata/mavenremoterepository")))
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+}
+
+func TestParseMavenRepository(t *testing.T) {
+	testdata := []struct {
+		giveSpec string
+		wontID   string
+		wontURL  string
+	}{
+		{"private::https://repo.example.com/maven2", "private", "https://repo.example.com/maven2"},
+		{"https://repo.example.com/maven2", "repository1", "https://repo.example.com/maven2"},
+	}
+	for _, td := range testdata {
+		got := parseMavenRepository(td.giveSpec, 0)
+		if got.id != td.wontID || got.url != td.wontURL {
+			t.Errorf("parseMavenRepository(%s) did not match, wont (%s, %s), got (%s, %s)", td.giveSpec, td.wontID, td.wontURL, got.id, got.url)
+		}
+	}
+}
+
+func TestMavenRepositories(t *testing.T) {
+	defer setEnv(map[string]string{MavenSettingsEnvName: "testdata/mavensettings/repositories.xml", "PURPLECAT_TEST_MIRROR_URL": "https://mirror.example.com/maven2", "PURPLECAT_TEST_PASSWORD": "secret"})()
+	context := NewContext(true, "json", 1)
+	context.Repositories = []string{"unmirrored::https://cli.example.com/maven2", "https://other.example.com/maven2"}
+	repositories := context.mavenRepositories([]*mavenRepository{{id: "declared", url: "https://declared.example.com/maven2", releases: true}})
+	testdata := []struct {
+		wontID        string
+		wontURL       string
+		wontUsername  string
+		wontPassword  string
+		wontSnapshots bool
+	}{
+		{"unmirrored", "https://cli.example.com/maven2", "", "", true},
+		{"private", "https://mirror.example.com/maven2", "purplecat", "secret", true},
+	}
+	if len(repositories) != len(testdata) {
+		t.Errorf("the length of repositories did not match, wont %d, got %d", len(testdata), len(repositories))
+		return
+	}
+	for i, td := range testdata {
+		got := repositories[i]
+		if got.id != td.wontID || got.url != td.wontURL || got.username != td.wontUsername || got.password != td.wontPassword || got.snapshots != td.wontSnapshots {
+			t.Errorf("repositories[%d] did not match, wont %v, got %v", i, td, got)
+		}
+	}
+}
+
+func TestMavenRepositoriesWithDeclaredServerID(t *testing.T) {
+	defer setEnv(map[string]string{MavenSettingsEnvName: "testdata/mavensettings/servers.xml"})()
+	testdata := []struct {
+		giveRepositories []string
+		giveDeclared     []*mavenRepository
+		wontURL          string
+		wontUsername     string
+	}{
+		{[]string{"internal-nexus::https://nexus.example.com/maven2"}, []*mavenRepository{}, "https://nexus.example.com/maven2", "purplecat"},
+		{[]string{}, []*mavenRepository{{id: "internal-nexus", url: "http://attacker.example.com/maven2", releases: true}}, "http://attacker.example.com/maven2", ""},
+		{[]string{"internal-nexus::https://nexus.example.com/maven2"}, []*mavenRepository{{id: "internal-nexus", url: "http://attacker.example.com/maven2", releases: true}}, "https://nexus.example.com/maven2", "purplecat"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, "json", 1)
+		context.Repositories = td.giveRepositories
+		got := context.mavenRepositories(td.giveDeclared)[0]
+		if got.url != td.wontURL || got.username != td.wontUsername {
+			t.Errorf("repositories[0] of %v and %v did not match, wont (%s, %s), got (%s, %s)", td.giveRepositories, td.giveDeclared, td.wontURL, td.wontUsername, got.url, got.username)
+		}
+		if got.url != "https://nexus.example.com/maven2" && got.password != "" {
+			t.Errorf("%s: the password of the settings wont be sent to the declared repository", got.url)
+		}
+	}
+}
+
+func TestAcceptsVersion(t *testing.T) {
+	testdata := []struct {
+		giveVersion string
+		releases    bool
+		snapshots   bool
+		wont        bool
+	}{
+		{"1.0.0", true, false, true},
+		{"1.0.0-SNAPSHOT", true, false, false},
+		{"1.0.0", false, true, false},
+		{"1.0.0-SNAPSHOT", false, true, true},
+	}
+	for _, td := range testdata {
+		repository := &mavenRepository{id: "test", url: "https://repo.example.com", releases: td.releases, snapshots: td.snapshots}
+		if got := repository.accepts(td.giveVersion); got != td.wont {
+			t.Errorf("accepts(%s) (releases: %v, snapshots: %v) did not match, wont %v, got %v", td.giveVersion, td.releases, td.snapshots, td.wont, got)
+		}
+	}
+}
+
+func TestParseMavenWithRepositories(t *testing.T) {
+	server := newMavenRepositoryServer("purplecat", "secret")
+	defer server.Close()
+	testdata := []struct {
+		givePassword string
+		wontFound    bool
+	}{
+		{"secret", true},
+		{"wrong", false},
+	}
+	for _, td := range testdata {
+		reset := setEnv(map[string]string{
+			MavenLocalRepositoryEnvName: "testdata/mavenrepository",
+			MavenSettingsEnvName:        "testdata/mavensettings/repositories.xml",
+			"PURPLECAT_TEST_MIRROR_URL": server.URL + "/maven2",
+			"PURPLECAT_TEST_PASSWORD":   td.givePassword,
+		})
+		context := NewContext(false, "json", 1)
+		tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenrepositoryproject"))
+		reset()
+		if err != nil {
+			t.Errorf("testdata/mavenrepositoryproject: parse failed: %s", err.Error())
+			continue
+		}
+		if strings.Join(tree.Deps, ",") != "com.example/remote/1.0.0" {
+			t.Errorf("dependencies did not match, wont [com.example/remote/1.0.0], got %v", tree.Deps)
+		}
+		remote, ok := context.SearchCache("com.example/remote/1.0.0")
+		if ok != td.wontFound {
+			t.Errorf("password %s: com.example/remote/1.0.0 found wont %v, got %v", td.givePassword, td.wontFound, ok)
+		}
+		if ok && joinLicenseNames(remote) != "Apache License 2.0" {
+			t.Errorf("licenses did not match, wont Apache License 2.0, got %s", joinLicenseNames(remote))
+		}
+	}
+}
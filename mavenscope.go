@@ -0,0 +1,62 @@
+package purplecat
+
+// MavenScopes is the available scopes of the Maven dependencies.
+var MavenScopes = []string{"compile", "provided", "runtime", "test", "system"}
+
+// mavenNode is the state of the path from the root project to the artifact in the dependency graph.
+type mavenNode struct {
+	// dependency is the dependency element of the artifact, and nil for the root project.
+	dependency *mavenDependency
+	// scope is the effective scope of the artifact, and empty for the root project.
+	scope string
+	// exclusions is the exclusions declared in the path, which are applied to the descendants.
+	exclusions []*mavenExclusion
+	// repositories is the repositories declared in the poms of the path, which are looked up for the artifact.
+	repositories []*mavenRepository
+}
+
+func rootMavenNode() *mavenNode {
+	return &mavenNode{scope: ""}
+}
+
+func (node *mavenNode) isRoot() bool {
+	return node.scope == ""
+}
+
+func (node *mavenNode) excludes(dependency *mavenDependency) bool {
+	for _, exclusion := range node.exclusions {
+		if exclusion.matches(dependency) {
+			return true
+		}
+	}
+	return false
+}
+
+// child returns the node of the given dependency of the receiver node, and false if the dependency is omitted by the exclusions,
+// the optional flag, or the scope, in the manner of the transitive dependencies of Maven.
+func (node *mavenNode) child(dependency *mavenDependency) (*mavenNode, bool) {
+	if node.excludes(dependency) {
+		return nil, false
+	}
+	scope, ok := node.transitiveScope(dependency)
+	if !ok {
+		return nil, false
+	}
+	exclusions := append([]*mavenExclusion{}, node.exclusions...)
+	return &mavenNode{dependency: dependency, scope: scope, exclusions: append(exclusions, dependency.exclusions...)}, true
+}
+
+// transitiveScope returns the effective scope of the given dependency of the receiver node.
+// The optional dependencies, and the dependencies of provided, test, and system scopes are not transitive.
+func (node *mavenNode) transitiveScope(dependency *mavenDependency) (string, bool) {
+	if node.isRoot() {
+		return dependency.scope, true
+	}
+	if dependency.optional || (dependency.scope != "compile" && dependency.scope != "runtime") {
+		return "", false
+	}
+	if node.scope == "compile" {
+		return dependency.scope, true
+	}
+	return node.scope, true
+}
@@ -0,0 +1,168 @@
+package purplecat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseMavenScopes(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	testdata := []struct {
+		giveScopes     []string
+		wontDeps       []string
+		wontScopedDeps []string
+	}{
+		{[]string{}, []string{"com.example/scoped/1.0.0", "args4j/args4j/2.33", "com.example/tools/1.0.0", "junit/junit/4.13.1"}, []string{"com.example/lib-a/1.3.0"}},
+		{[]string{"compile", "runtime"}, []string{"com.example/scoped/1.0.0", "args4j/args4j/2.33"}, []string{"com.example/lib-a/1.3.0"}},
+		{[]string{"compile"}, []string{"com.example/scoped/1.0.0", "args4j/args4j/2.33"}, []string{}},
+		{[]string{"test"}, []string{"junit/junit/4.13.1"}, nil},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, "json", 2)
+		context.Scopes = td.giveScopes
+		tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenscopeproject"))
+		if err != nil {
+			t.Errorf("testdata/mavenscopeproject: parse failed: %s", err.Error())
+			continue
+		}
+		if strings.Join(tree.Deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("scopes %v: dependencies did not match, wont %v, got %v", td.giveScopes, td.wontDeps, tree.Deps)
+		}
+		if td.wontScopedDeps == nil {
+			continue
+		}
+		scoped, ok := findResolvedProject(tree, "com.example/scoped/1.0.0")
+		if !ok {
+			t.Errorf("scopes %v: com.example/scoped/1.0.0 not found", td.giveScopes)
+			continue
+		}
+		if strings.Join(scoped.Deps, ",") != strings.Join(td.wontScopedDeps, ",") {
+			t.Errorf("scopes %v: dependencies of com.example/scoped/1.0.0 did not match, wont %v, got %v", td.giveScopes, td.wontScopedDeps, scoped.Deps)
+		}
+	}
+}
+
+func TestParseMavenScopesWithSharedCache(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	cache, _ := NewCacheDB(MemoryCache)
+	testdata := []struct {
+		giveScopes []string
+		wontDeps   []string
+	}{
+		{[]string{}, []string{"com.example/scoped/1.0.0", "args4j/args4j/2.33", "com.example/tools/1.0.0", "junit/junit/4.13.1"}},
+		{[]string{"compile"}, []string{"com.example/scoped/1.0.0", "args4j/args4j/2.33"}},
+		{[]string{"test"}, []string{"junit/junit/4.13.1"}},
+		{[]string{}, []string{"com.example/scoped/1.0.0", "args4j/args4j/2.33", "com.example/tools/1.0.0", "junit/junit/4.13.1"}},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, "json", 2)
+		context.Cache = cache
+		context.Scopes = td.giveScopes
+		tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenscopeproject"))
+		if err != nil {
+			t.Errorf("testdata/mavenscopeproject: parse failed: %s", err.Error())
+			continue
+		}
+		if strings.Join(tree.Deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("scopes %v: dependencies did not match, wont %v, got %v", td.giveScopes, td.wontDeps, tree.Deps)
+		}
+	}
+}
+
+func TestMavenDependencyAttributes(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	context := NewContext(true, "json", 2)
+	tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenscopeproject"))
+	if err != nil {
+		t.Errorf("testdata/mavenscopeproject: parse failed: %s", err.Error())
+		return
+	}
+	testdata := []struct {
+		giveName     string
+		wontScope    string
+		wontOptional bool
+	}{
+		{"com.example/scoped/1.0.0", "compile", false},
+		{"args4j/args4j/2.33", "compile", true},
+		{"com.example/tools/1.0.0", "provided", false},
+		{"junit/junit/4.13.1", "test", false},
+	}
+	for _, td := range testdata {
+		attribute := tree.Attribute(td.giveName)
+		if attribute == nil {
+			t.Errorf("%s: attribute not found", td.giveName)
+			continue
+		}
+		if attribute.Scope != td.wontScope || attribute.Optional != td.wontOptional {
+			t.Errorf("%s: attribute did not match, wont (%s, %v), got (%s, %v)", td.giveName, td.wontScope, td.wontOptional, attribute.Scope, attribute.Optional)
+		}
+	}
+	scoped := tree.Dependencies()[0]
+	if attribute := scoped.Attribute("com.example/lib-a/1.3.0"); attribute == nil || attribute.Scope != "runtime" {
+		t.Errorf("com.example/lib-a/1.3.0: scope wont be runtime, got %v", attribute)
+	}
+}
+
+func TestMavenNodeChild(t *testing.T) {
+	testdata := []struct {
+		giveNode       *mavenNode
+		giveDependency *mavenDependency
+		wontScope      string
+		wontOk         bool
+	}{
+		{rootMavenNode(), &mavenDependency{groupID: "g", artifactID: "a", scope: "test"}, "test", true},
+		{rootMavenNode(), &mavenDependency{groupID: "g", artifactID: "a", scope: "compile", optional: true}, "compile", true},
+		{&mavenNode{scope: "compile"}, &mavenDependency{groupID: "g", artifactID: "a", scope: "compile"}, "compile", true},
+		{&mavenNode{scope: "compile"}, &mavenDependency{groupID: "g", artifactID: "a", scope: "runtime"}, "runtime", true},
+		{&mavenNode{scope: "compile"}, &mavenDependency{groupID: "g", artifactID: "a", scope: "compile", optional: true}, "", false},
+		{&mavenNode{scope: "compile"}, &mavenDependency{groupID: "g", artifactID: "a", scope: "test"}, "", false},
+		{&mavenNode{scope: "compile"}, &mavenDependency{groupID: "g", artifactID: "a", scope: "provided"}, "", false},
+		{&mavenNode{scope: "provided"}, &mavenDependency{groupID: "g", artifactID: "a", scope: "compile"}, "provided", true},
+		{&mavenNode{scope: "runtime"}, &mavenDependency{groupID: "g", artifactID: "a", scope: "compile"}, "runtime", true},
+		{&mavenNode{scope: "test"}, &mavenDependency{groupID: "g", artifactID: "a", scope: "runtime"}, "test", true},
+		{&mavenNode{scope: "compile", exclusions: []*mavenExclusion{{"g", "a"}}}, &mavenDependency{groupID: "g", artifactID: "a", scope: "compile"}, "", false},
+		{&mavenNode{scope: "compile", exclusions: []*mavenExclusion{{"g", "*"}}}, &mavenDependency{groupID: "g", artifactID: "b", scope: "compile"}, "", false},
+		{&mavenNode{scope: "compile", exclusions: []*mavenExclusion{{"*", "*"}}}, &mavenDependency{groupID: "h", artifactID: "b", scope: "compile"}, "", false},
+		{&mavenNode{scope: "compile", exclusions: []*mavenExclusion{{"g", "a"}}}, &mavenDependency{groupID: "g", artifactID: "b", scope: "compile"}, "compile", true},
+	}
+	for _, td := range testdata {
+		child, ok := td.giveNode.child(td.giveDependency)
+		if ok != td.wontOk {
+			t.Errorf("child(%s:%s, %s) did not match, wont %v, got %v", td.giveDependency.groupID, td.giveDependency.artifactID, td.giveNode.scope, td.wontOk, ok)
+			continue
+		}
+		if ok && child.scope != td.wontScope {
+			t.Errorf("child(%s:%s, %s): scope did not match, wont %s, got %s", td.giveDependency.groupID, td.giveDependency.artifactID, td.giveNode.scope, td.wontScope, child.scope)
+		}
+	}
+}
+
+func TestWriteDependencyAttributes(t *testing.T) {
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	testdata := []struct {
+		format string
+		wont   string
+	}{
+		{"markdown", "    * junit/junit/4.13.1 (test): [Eclipse Public License 1.0]"},
+		{"markdown", "    * args4j/args4j/2.33 (optional): ["},
+		{"csv", "com.example/lib-a/1.3.0 (runtime),"},
+		{"json", `{"project-name":"junit/junit/4.13.1","license-names":["Eclipse Public License 1.0"],"scope":"test"}`},
+		{"yaml", "scope:test"},
+		{"xml", "<optional>true</optional>"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, td.format, 2)
+		tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavenscopeproject"))
+		if err != nil {
+			t.Errorf("testdata/mavenscopeproject: parse failed: %s", err.Error())
+			continue
+		}
+		out := &bytes.Buffer{}
+		writer, _ := context.NewWriter(out)
+		writer.Write(tree)
+		if !strings.Contains(out.String(), td.wont) {
+			t.Errorf("%s: output wont contain %s, got %s", td.format, td.wont, out.String())
+		}
+	}
+}
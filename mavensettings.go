@@ -0,0 +1,170 @@
+package purplecat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// MavenSettingsEnvName is the environment name for the path of settings.xml, which overrides `~/.m2/settings.xml`.
+const MavenSettingsEnvName = "PURPLECAT_MAVEN_SETTINGS"
+
+// mavenSettings is the user settings of Maven used for looking up the artifacts.
+type mavenSettings struct {
+	// localRepository is the directory of the local repository, and empty if the settings do not specify it.
+	localRepository string
+	mirrors         []*mavenMirror
+	// servers is the credentials of the repositories and the mirrors, keyed by their ids.
+	servers map[string]*mavenServer
+	// repositories is the repositories declared in the active profiles of the settings.
+	repositories []*mavenRepository
+	// doc is the document of settings.xml for looking up `${settings.*}`, and nil if settings.xml is absent.
+	doc *xmlquery.Node
+}
+
+// mavenMirror is the mirror element of the settings, which serves the repositories matched by mirrorOf instead of them.
+type mavenMirror struct {
+	id       string
+	url      string
+	mirrorOf string
+}
+
+// mavenServer is the server element of the settings, which holds the credentials of the repository of the same id.
+type mavenServer struct {
+	username string
+	password string
+}
+
+// mavenSettingsPath returns the path of the user settings of Maven.
+func mavenSettingsPath() string {
+	if path := os.Getenv(MavenSettingsEnvName); path != "" {
+		return path
+	}
+	home, _ := homedir.Dir()
+	return filepath.Join(home, ".m2", "settings.xml")
+}
+
+// readMavenSettings reads the user settings of Maven; settings.xml is always located in the local file system.
+func readMavenSettings() (*xmlquery.Node, error) {
+	file, err := os.Open(mavenSettingsPath())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return xmlquery.Parse(file)
+}
+
+// value returns the value of `${settings.<key>}`, e.g., `${settings.localRepository}`, from the receiver settings.
+func (settings *mavenSettings) value(key string) (string, bool) {
+	if settings.doc == nil {
+		return "", false
+	}
+	return getStringByXPath("/settings/"+strings.ReplaceAll(key, ".", "/"), settings.doc)
+}
+
+// loadMavenSettings returns the user settings of Maven, which are read once for the receiver context.
+// The settings are empty if settings.xml is absent.
+func (context *Context) loadMavenSettings() *mavenSettings {
+	if context.mavenSettings == nil {
+		context.mavenSettings = parseMavenSettings(context)
+	}
+	return context.mavenSettings
+}
+
+func parseMavenSettings(context *Context) *mavenSettings {
+	settings := &mavenSettings{mirrors: []*mavenMirror{}, servers: map[string]*mavenServer{}, repositories: []*mavenRepository{}}
+	doc, err := readMavenSettings()
+	if err != nil {
+		logger.Debugf("%s: %s", mavenSettingsPath(), err.Error())
+		return settings
+	}
+	settings.doc = doc
+	props := map[string]string{}
+	if home, err := homedir.Dir(); err == nil {
+		props["user.home"] = home
+	}
+	// `${settings.*}` in settings.xml is not expanded, since the settings are being parsed.
+	value := func(xpath string, node *xmlquery.Node) string {
+		value, _ := getStringByXPath(xpath, node)
+		return updateByProps(value, props, nil)
+	}
+	settings.localRepository = value("/settings/localRepository", doc)
+	mirrors, _ := xmlquery.QueryAll(doc, "/settings/mirrors/mirror")
+	for _, mirror := range mirrors {
+		settings.mirrors = append(settings.mirrors, &mavenMirror{id: value("./id", mirror), url: value("./url", mirror), mirrorOf: value("./mirrorOf", mirror)})
+	}
+	servers, _ := xmlquery.QueryAll(doc, "/settings/servers/server")
+	for _, server := range servers {
+		settings.servers[value("./id", server)] = &mavenServer{username: value("./username", server), password: value("./password", server)}
+	}
+	for _, profile := range activeSettingsProfiles(doc, context) {
+		list, _ := xmlquery.QueryAll(profile, "./repositories/repository")
+		for _, node := range list {
+			settings.repositories = append(settings.repositories, newMavenRepositoryXPath(node, props, nil))
+		}
+	}
+	return settings
+}
+
+// activeSettingsProfiles returns the profiles of the settings listed in activeProfiles, or selected by the context.
+// The profiles activated by default are active only if no other profiles are active.
+func activeSettingsProfiles(doc *xmlquery.Node, context *Context) []*xmlquery.Node {
+	actives := map[string]bool{}
+	list, _ := xmlquery.QueryAll(doc, "/settings/activeProfiles/activeProfile")
+	for _, node := range list {
+		actives[strings.TrimSpace(node.InnerText())] = true
+	}
+	profiles, _ := xmlquery.QueryAll(doc, "/settings/profiles/profile")
+	active := []*xmlquery.Node{}
+	defaults := []*xmlquery.Node{}
+	for _, profile := range profiles {
+		id, _ := getStringByXPath("./id", profile)
+		if selected, ok := mavenProfileSelection(context, id); ok {
+			if selected {
+				active = append(active, profile)
+			}
+			continue
+		}
+		if actives[id] {
+			active = append(active, profile)
+		} else if value, _ := getStringByXPath("./activation/activeByDefault", profile); value == "true" {
+			defaults = append(defaults, profile)
+		}
+	}
+	if len(active) == 0 {
+		return defaults
+	}
+	return active
+}
+
+// findMirror returns the mirror of the given repository, the first mirror matched with the repository takes precedence.
+func (settings *mavenSettings) findMirror(repository *mavenRepository) (*mavenMirror, bool) {
+	for _, mirror := range settings.mirrors {
+		if mirror.matches(repository) {
+			return mirror, true
+		}
+	}
+	return nil, false
+}
+
+// matches checks the receiver mirror serves the given repository. mirrorOf is the comma separated list of the repository ids,
+// `*` (all repositories), `external:*` (all repositories except the local ones), and `!id` (excluding the repository).
+func (mirror *mavenMirror) matches(repository *mavenRepository) bool {
+	matched := false
+	for _, pattern := range strings.Split(mirror.mirrorOf, ",") {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "!"+repository.id:
+			return false
+		case pattern == repository.id || pattern == "*":
+			matched = true
+		case pattern == "external:*":
+			matched = matched || !repository.isLocal()
+		}
+	}
+	return matched
+}
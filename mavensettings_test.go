@@ -0,0 +1,76 @@
+package purplecat
+
+import "testing"
+
+func TestMirrorMatches(t *testing.T) {
+	testdata := []struct {
+		giveMirrorOf string
+		giveID       string
+		giveURL      string
+		wont         bool
+	}{
+		{"central", "central", "https://repo.maven.apache.org/maven2", true},
+		{"central", "private", "https://repo.example.com/maven2", false},
+		{"*", "private", "https://repo.example.com/maven2", true},
+		{"*,!private", "private", "https://repo.example.com/maven2", false},
+		{"external:*", "private", "https://repo.example.com/maven2", true},
+		{"external:*", "local", "http://localhost:8081/maven2", false},
+		{"central, private", "private", "https://repo.example.com/maven2", true},
+	}
+	for _, td := range testdata {
+		mirror := &mavenMirror{id: "mirror", url: "https://mirror.example.com", mirrorOf: td.giveMirrorOf}
+		if got := mirror.matches(&mavenRepository{id: td.giveID, url: td.giveURL}); got != td.wont {
+			t.Errorf("mirrorOf %s matches %s (%s) did not match, wont %v, got %v", td.giveMirrorOf, td.giveID, td.giveURL, td.wont, got)
+		}
+	}
+}
+
+func TestParseMavenSettings(t *testing.T) {
+	testdata := []struct {
+		giveProfiles []string
+		wontIDs      []string
+	}{
+		{[]string{}, []string{"settings-snapshots"}},
+		{[]string{"inactive"}, []string{"settings-snapshots", "inactive"}},
+		{[]string{"!snapshots"}, []string{}},
+	}
+	defer setEnv(map[string]string{MavenSettingsEnvName: "testdata/mavensettings/repositories.xml", "PURPLECAT_TEST_PASSWORD": "secret"})()
+	for _, td := range testdata {
+		context := NewContext(true, "json", 1)
+		context.Profiles = td.giveProfiles
+		settings := context.loadMavenSettings()
+		ids := []string{}
+		for _, repository := range settings.repositories {
+			ids = append(ids, repository.id)
+		}
+		if len(ids) != len(td.wontIDs) {
+			t.Errorf("profiles %v: repositories did not match, wont %v, got %v", td.giveProfiles, td.wontIDs, ids)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != td.wontIDs[i] {
+				t.Errorf("profiles %v: repositories did not match, wont %v, got %v", td.giveProfiles, td.wontIDs, ids)
+			}
+		}
+		if server, ok := settings.servers["private"]; !ok || server.username != "purplecat" || server.password != "secret" {
+			t.Errorf("profiles %v: the credentials of private did not match", td.giveProfiles)
+		}
+	}
+}
+
+func TestLocalMavenRepositoryDir(t *testing.T) {
+	reset := setEnv(map[string]string{MavenSettingsEnvName: "testdata/mavensettings/settings.xml", MavenLocalRepositoryEnvName: ""})
+	context := NewContext(true, "json", 1)
+	if dir := localMavenRepositoryDir(context); dir != "/opt/maven/repository" {
+		t.Errorf("local repository did not match, wont /opt/maven/repository, got %s", dir)
+	}
+	reset()
+	defer setEnv(map[string]string{MavenSettingsEnvName: "testdata/mavensettings/repositories.xml"})()
+	if dir := localMavenRepositoryDir(context); dir != "/opt/maven/repository" {
+		t.Errorf("the settings wont be read again for the same context, got %s", dir)
+	}
+	defer setEnv(map[string]string{MavenLocalRepositoryEnvName: "testdata/mavenrepository"})()
+	if dir := localMavenRepositoryDir(context); dir != "testdata/mavenrepository" {
+		t.Errorf("local repository did not match, wont testdata/mavenrepository, got %s", dir)
+	}
+}
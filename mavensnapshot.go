@@ -0,0 +1,83 @@
+package purplecat
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+func isMavenSnapshot(version string) bool {
+	return strings.HasSuffix(version, "-SNAPSHOT")
+}
+
+// snapshotPomName returns the file name of the pom of the given SNAPSHOT artifact listed in maven-metadata.xml of the version directory,
+// and lastUpdated of the metadata. The pom is timestamped (e.g., `foo-1.0-20240101.123456-3.pom`), unless the metadata shows
+// the local copy installed by `mvn install` (maven-metadata-local.xml).
+func snapshotPomName(artifact *artifact, doc *xmlquery.Node) (string, string, bool) {
+	updated, _ := getStringByXPath("/metadata/versioning/lastUpdated", doc)
+	if localCopy, _ := getStringByXPath("/metadata/versioning/snapshot/localCopy", doc); localCopy == "true" {
+		return artifact.artifactID + "-" + artifact.version + ".pom", updated, true
+	}
+	list, _ := xmlquery.QueryAll(doc, "/metadata/versioning/snapshotVersions/snapshotVersion")
+	for _, node := range list {
+		extension, _ := getStringByXPath("./extension", node)
+		classifier, _ := getStringByXPath("./classifier", node)
+		if value, ok := getStringByXPath("./value", node); ok && extension == "pom" && classifier == "" {
+			return artifact.artifactID + "-" + value + ".pom", updated, true
+		}
+	}
+	timestamp, ok1 := getStringByXPath("/metadata/versioning/snapshot/timestamp", doc)
+	buildNumber, ok2 := getStringByXPath("/metadata/versioning/snapshot/buildNumber", doc)
+	if !ok1 || !ok2 {
+		return "", "", false
+	}
+	return artifact.artifactID + "-" + strings.TrimSuffix(artifact.version, "SNAPSHOT") + timestamp + "-" + buildNumber + ".pom", updated, true
+}
+
+// findLocalSnapshotPomPath returns the latest pom of the given SNAPSHOT artifact in the local repository. The SNAPSHOT artifacts downloaded
+// from the remote repositories are stored as the timestamped files listed in `maven-metadata-<repository id>.xml`.
+func findLocalSnapshotPomPath(artifact *artifact, context *Context) (*Path, bool) {
+	dir := filepath.Join(localMavenRepositoryDir(context), artifact.repoPath())
+	metadataPaths, _ := filepath.Glob(filepath.Join(dir, "maven-metadata*.xml"))
+	paths := []*Path{}
+	for _, metadataPath := range metadataPaths {
+		paths = append(paths, NewPath(metadataPath))
+	}
+	return findLatestSnapshotPomPath(artifact, paths, context)
+}
+
+// findRemoteSnapshotPomPath returns the latest pom of the given SNAPSHOT artifact among the given repositories serving the SNAPSHOT versions.
+// The pom of each repository is listed in maven-metadata.xml of the version directory.
+func findRemoteSnapshotPomPath(artifact *artifact, repositories []*mavenRepository, context *Context) (*Path, bool) {
+	paths := []*Path{}
+	for _, repository := range repositories {
+		if repository.accepts(artifact.version) {
+			paths = append(paths, repository.path(artifact.repoPath()+"/maven-metadata.xml"))
+		}
+	}
+	return findLatestSnapshotPomPath(artifact, paths, context)
+}
+
+// findLatestSnapshotPomPath returns the existing pom listed in the given metadata, whose lastUpdated is the latest.
+// The pom is located in the same directory as its metadata.
+func findLatestSnapshotPomPath(artifact *artifact, metadataPaths []*Path, context *Context) (*Path, bool) {
+	var found *Path
+	latest := ""
+	for _, metadataPath := range metadataPaths {
+		doc, err := readXML(metadataPath, context)
+		if err != nil {
+			logger.Debugf("%s: %s", metadataPath.Path, err.Error())
+			continue
+		}
+		name, updated, ok := snapshotPomName(artifact, doc)
+		if !ok || (found != nil && updated <= latest) {
+			continue
+		}
+		if pomPath := metadataPath.Dir().Join(name); pomPath.Exists(context) {
+			found, latest = pomPath, updated
+		}
+	}
+	return found, found != nil
+}
@@ -0,0 +1,95 @@
+package purplecat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestSnapshotPomName(t *testing.T) {
+	testdata := []struct {
+		giveMetadata string
+		wontName     string
+		wontOk       bool
+	}{
+		{"<metadata><versioning><snapshot><localCopy>true</localCopy></snapshot></versioning></metadata>", "foo-1.0-SNAPSHOT.pom", true},
+		{"<metadata><versioning><snapshot><timestamp>20260101.120000</timestamp><buildNumber>2</buildNumber></snapshot></versioning></metadata>", "foo-1.0-20260101.120000-2.pom", true},
+		{"<metadata><versioning><snapshotVersions><snapshotVersion><extension>pom</extension><value>1.0-20260102.000000-3</value></snapshotVersion></snapshotVersions></versioning></metadata>", "foo-1.0-20260102.000000-3.pom", true},
+		{"<metadata><versioning><lastUpdated>20260101120000</lastUpdated></versioning></metadata>", "", false},
+	}
+	artifact := newArtifact("com.example", "foo", "1.0-SNAPSHOT")
+	for _, td := range testdata {
+		doc, err := xmlquery.Parse(strings.NewReader(td.giveMetadata))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.giveMetadata, err.Error())
+			continue
+		}
+		name, _, ok := snapshotPomName(artifact, doc)
+		if name != td.wontName || ok != td.wontOk {
+			t.Errorf("snapshotPomName(%s) did not match, wont (%s, %v), got (%s, %v)", td.giveMetadata, td.wontName, td.wontOk, name, ok)
+		}
+	}
+}
+
+func TestParseMavenSnapshots(t *testing.T) {
+	server := newMavenRepositoryServer("purplecat", "secret")
+	defer server.Close()
+	defer setEnv(map[string]string{
+		MavenLocalRepositoryEnvName: "testdata/mavenrepository",
+		MavenSettingsEnvName:        "testdata/mavensettings/repositories.xml",
+		"PURPLECAT_TEST_MIRROR_URL": server.URL + "/maven2",
+		"PURPLECAT_TEST_PASSWORD":   "secret",
+	})()
+	context := NewContext(false, "json", 1)
+	tree, err := (&mavenParser{context: context}).Parse(NewPath("testdata/mavensnapshotproject"))
+	if err != nil {
+		t.Errorf("testdata/mavensnapshotproject: parse failed: %s", err.Error())
+		return
+	}
+	validateDependencyTree(t, tree, "com.example/snapshot4test/1.0.0-SNAPSHOT", "MIT License", 2)
+	testdata := []struct {
+		giveName    string
+		wontLicense string
+	}{
+		{"com.example/snapshot-cached/1.1.0-SNAPSHOT", "Apache License 2.0"},
+		{"com.example/snapshot-remote/2.0.0-SNAPSHOT", "Apache License 2.0"},
+	}
+	for _, td := range testdata {
+		project, ok := context.SearchCache(td.giveName)
+		if !ok {
+			t.Errorf("%s: not found", td.giveName)
+			continue
+		}
+		if got := joinLicenseNames(project); got != td.wontLicense {
+			t.Errorf("%s: licenses did not match, wont %s, got %s", td.giveName, td.wontLicense, got)
+		}
+	}
+}
+
+func TestFindRemoteSnapshotPomPath(t *testing.T) {
+	older := &mavenRepository{id: "remote", url: "testdata/mavenremoterepository", snapshots: true}
+	newer := &mavenRepository{id: "staging", url: "testdata/mavenstagingrepository", snapshots: true}
+	releasesOnly := &mavenRepository{id: "releases", url: "testdata/mavenstagingrepository", releases: true}
+	testdata := []struct {
+		giveRepositories []*mavenRepository
+		wontPath         string
+	}{
+		{[]*mavenRepository{older, newer}, "testdata/mavenstagingrepository/com/example/snapshot-remote/2.0.0-SNAPSHOT/snapshot-remote-2.0.0-20260315.080000-6.pom"},
+		{[]*mavenRepository{newer, older}, "testdata/mavenstagingrepository/com/example/snapshot-remote/2.0.0-SNAPSHOT/snapshot-remote-2.0.0-20260315.080000-6.pom"},
+		{[]*mavenRepository{older, releasesOnly}, "testdata/mavenremoterepository/com/example/snapshot-remote/2.0.0-SNAPSHOT/snapshot-remote-2.0.0-20260301.093000-5.pom"},
+		{[]*mavenRepository{releasesOnly}, ""},
+	}
+	context := NewContext(true, "json", 1)
+	artifact := newArtifact("com.example", "snapshot-remote", "2.0.0-SNAPSHOT")
+	for _, td := range testdata {
+		path, ok := findRemoteSnapshotPomPath(artifact, td.giveRepositories, context)
+		got := ""
+		if ok {
+			got = path.Path
+		}
+		if got != td.wontPath {
+			t.Errorf("findRemoteSnapshotPomPath(%v) did not match, wont %s, got %s", td.giveRepositories, td.wontPath, got)
+		}
+	}
+}
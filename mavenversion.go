@@ -0,0 +1,221 @@
+package purplecat
+
+import (
+	"strings"
+	"unicode"
+)
+
+// mavenQualifiers is the well-known qualifiers of Maven versions in ascending order, the release version is ranked as the empty qualifier.
+var mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var mavenQualifierAliases = map[string]string{
+	"a": "alpha", "b": "beta", "m": "milestone", "cr": "rc", "ga": "", "final": "", "release": "",
+}
+
+// mavenVersionItem is the item of the version, which is the number or the qualifier.
+type mavenVersionItem struct {
+	number    string
+	qualifier string
+	isNumber  bool
+}
+
+// parseMavenVersion splits the given version into the items, in the manner of ComparableVersion of Maven.
+// The items are separated by `.`, `-`, and the transitions between digits and letters. The trailing null items (`0` and the release qualifiers) are trimmed.
+func parseMavenVersion(version string) []*mavenVersionItem {
+	items := []*mavenVersionItem{}
+	token := []rune{}
+	flush := func() {
+		if len(token) > 0 {
+			items = append(items, newMavenVersionItem(string(token)))
+		}
+		token = []rune{}
+	}
+	for _, r := range strings.ToLower(version) {
+		if r == '.' || r == '-' || r == '_' {
+			flush()
+			continue
+		}
+		if len(token) > 0 && unicode.IsDigit(r) != unicode.IsDigit(token[len(token)-1]) {
+			flush()
+		}
+		token = append(token, r)
+	}
+	flush()
+	for len(items) > 0 && items[len(items)-1].isNull() {
+		items = items[:len(items)-1]
+	}
+	return items
+}
+
+func newMavenVersionItem(token string) *mavenVersionItem {
+	if unicode.IsDigit(rune(token[0])) {
+		number := strings.TrimLeft(token, "0")
+		return &mavenVersionItem{number: number, isNumber: true}
+	}
+	if alias, ok := mavenQualifierAliases[token]; ok {
+		token = alias
+	}
+	return &mavenVersionItem{qualifier: token}
+}
+
+func (item *mavenVersionItem) isNull() bool {
+	if item.isNumber {
+		return item.number == ""
+	}
+	return item.qualifier == ""
+}
+
+func (item *mavenVersionItem) qualifierRank() int {
+	for i, qualifier := range mavenQualifiers {
+		if qualifier == item.qualifier {
+			return i
+		}
+	}
+	return len(mavenQualifiers)
+}
+
+// compare compares the receiver item with the given item, nil means the missing item.
+func (item *mavenVersionItem) compare(other *mavenVersionItem) int {
+	if other == nil {
+		if item.isNumber {
+			return compareInts(len(item.number), 0)
+		}
+		return compareInts(item.qualifierRank(), mavenQualifierRank(""))
+	}
+	switch {
+	case item.isNumber && other.isNumber:
+		if len(item.number) != len(other.number) {
+			return compareInts(len(item.number), len(other.number))
+		}
+		return strings.Compare(item.number, other.number)
+	case item.isNumber:
+		return 1
+	case other.isNumber:
+		return -1
+	}
+	rank1, rank2 := item.qualifierRank(), other.qualifierRank()
+	if rank1 != rank2 || rank1 < len(mavenQualifiers) {
+		return compareInts(rank1, rank2)
+	}
+	return strings.Compare(item.qualifier, other.qualifier)
+}
+
+func mavenQualifierRank(qualifier string) int {
+	return (&mavenVersionItem{qualifier: qualifier}).qualifierRank()
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareMavenVersions compares the given versions, and returns a negative number, zero, or a positive number
+// if version1 is less than, equal to, or greater than version2.
+func compareMavenVersions(version1, version2 string) int {
+	items1, items2 := parseMavenVersion(version1), parseMavenVersion(version2)
+	for i := 0; i < len(items1) || i < len(items2); i++ {
+		var result int
+		switch {
+		case i >= len(items1):
+			result = -items2[i].compare(nil)
+		case i >= len(items2):
+			result = items1[i].compare(nil)
+		default:
+			result = items1[i].compare(items2[i])
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// mavenRestriction is the restriction of the version range, e.g., `[1.0,2.0)`. The empty bound means unbounded.
+type mavenRestriction struct {
+	lower          string
+	upper          string
+	lowerInclusive bool
+	upperInclusive bool
+}
+
+func (restriction *mavenRestriction) contains(version string) bool {
+	if restriction.lower != "" {
+		result := compareMavenVersions(version, restriction.lower)
+		if result < 0 || (result == 0 && !restriction.lowerInclusive) {
+			return false
+		}
+	}
+	if restriction.upper != "" {
+		result := compareMavenVersions(version, restriction.upper)
+		if result > 0 || (result == 0 && !restriction.upperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// mavenVersionRange is the version range of Maven, which is the union of the restrictions, e.g., `[1.0,1.2),(1.2,)`.
+type mavenVersionRange []*mavenRestriction
+
+func isMavenVersionRange(spec string) bool {
+	return strings.HasPrefix(spec, "[") || strings.HasPrefix(spec, "(")
+}
+
+// parseMavenVersionRange parses the given version range, and returns false if the given spec is not the valid range.
+func parseMavenVersionRange(spec string) (mavenVersionRange, bool) {
+	versionRange := mavenVersionRange{}
+	rest := strings.TrimSpace(spec)
+	for rest != "" {
+		end := strings.IndexAny(rest, "])")
+		if !isMavenVersionRange(rest) || end < 0 {
+			return nil, false
+		}
+		restriction, ok := parseMavenRestriction(rest[:end+1])
+		if !ok {
+			return nil, false
+		}
+		versionRange = append(versionRange, restriction)
+		rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest[end+1:]), ","))
+	}
+	return versionRange, len(versionRange) > 0
+}
+
+func parseMavenRestriction(spec string) (*mavenRestriction, bool) {
+	restriction := &mavenRestriction{lowerInclusive: spec[0] == '[', upperInclusive: spec[len(spec)-1] == ']'}
+	bounds := strings.Split(spec[1:len(spec)-1], ",")
+	switch len(bounds) {
+	case 1:
+		version := strings.TrimSpace(bounds[0])
+		restriction.lower, restriction.upper = version, version
+		return restriction, version != "" && restriction.lowerInclusive && restriction.upperInclusive
+	case 2:
+		restriction.lower, restriction.upper = strings.TrimSpace(bounds[0]), strings.TrimSpace(bounds[1])
+		return restriction, true
+	}
+	return nil, false
+}
+
+func (versionRange mavenVersionRange) contains(version string) bool {
+	for _, restriction := range versionRange {
+		if restriction.contains(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectVersion returns the highest version in the given versions contained in the receiver range.
+func (versionRange mavenVersionRange) selectVersion(versions []string) (string, bool) {
+	selected := ""
+	for _, version := range versions {
+		if versionRange.contains(version) && (selected == "" || compareMavenVersions(version, selected) > 0) {
+			selected = version
+		}
+	}
+	return selected, selected != ""
+}
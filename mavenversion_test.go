@@ -0,0 +1,68 @@
+package purplecat
+
+import "testing"
+
+func TestCompareMavenVersions(t *testing.T) {
+	testdata := []struct {
+		giveVersion1 string
+		giveVersion2 string
+		wont         int
+	}{
+		{"1.0", "1.0.0", 0},
+		{"1.0", "1", 0},
+		{"1.0-ga", "1.0", 0},
+		{"1.0-final", "1.0", 0},
+		{"1.2", "1.10", -1},
+		{"1.0.1", "1.0", 1},
+		{"1.0-alpha-1", "1.0-beta-1", -1},
+		{"1.0-a1", "1.0-alpha1", 0},
+		{"1.0-rc1", "1.0-cr1", 0},
+		{"1.0-milestone", "1.0-rc", -1},
+		{"1.0-SNAPSHOT", "1.0", -1},
+		{"1.0-rc2", "1.0-SNAPSHOT", -1},
+		{"1.0-sp1", "1.0", 1},
+		{"1.0.1", "1.0-alpha", 1},
+		{"1.0-foo", "1.0-bar", 1},
+		{"2.0.0", "10.0.0", -1},
+	}
+	for _, td := range testdata {
+		got := compareMavenVersions(td.giveVersion1, td.giveVersion2)
+		if got != td.wont {
+			t.Errorf("compareMavenVersions(%s, %s) did not match, wont %d, got %d", td.giveVersion1, td.giveVersion2, td.wont, got)
+		}
+	}
+}
+
+func TestMavenVersionRange(t *testing.T) {
+	versions := []string{"0.9", "1.0", "1.1", "1.2", "1.5", "2.0", "2.1"}
+	testdata := []struct {
+		giveRange   string
+		wontOk      bool
+		wontVersion string
+	}{
+		{"[1.0,2.0)", true, "1.5"},
+		{"[1.0,2.0]", true, "2.0"},
+		{"(,1.0]", true, "1.0"},
+		{"(,1.0)", true, "0.9"},
+		{"[1.5,)", true, "2.1"},
+		{"[1.1]", true, "1.1"},
+		{"[1.0,1.2),(1.2,1.5)", true, "1.1"},
+		{"[3.0,)", true, ""},
+		{"1.0", false, ""},
+		{"[1.0", false, ""},
+		{"(1.0)", false, ""},
+	}
+	for _, td := range testdata {
+		versionRange, ok := parseMavenVersionRange(td.giveRange)
+		if ok != td.wontOk {
+			t.Errorf("parseMavenVersionRange(%s) did not match, wont %v, got %v", td.giveRange, td.wontOk, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got, _ := versionRange.selectVersion(versions); got != td.wontVersion {
+			t.Errorf("%s: selected version did not match, wont %s, got %s", td.giveRange, td.wontVersion, got)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package purplecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// npmParser is the instance of Parser for parsing package-lock.json (or package.json) of npm.
+type npmParser struct {
+	context *Context
+}
+
+// npmLockFileNames is the lockfiles of npm in the order of precedence.
+var npmLockFileNames = []string{"npm-shrinkwrap.json", "package-lock.json"}
+
+// npmManifest represents package.json, and the fields of the packages in package-lock.json.
+type npmManifest struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	License              json.RawMessage   `json:"license"`
+	Licenses             json.RawMessage   `json:"licenses"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// npmPackage represents the package in the dependency graph, which is resolved from the lockfile, node_modules, or the registry.
+type npmPackage struct {
+	name    string
+	version string
+	// dir is the relative location of the package from the project root, e.g., `node_modules/a/node_modules/b`.
+	dir string
+	// registry shows the package was resolved from the registry, that is, the package has no location in the project.
+	registry bool
+	licenses Licenses
+	// requires is the dependencies of the package, the keys are the package names, and the values are the version ranges.
+	requires     map[string]string
+	dependencies []*npmPackage
+}
+
+func (pkg *npmPackage) Name() string {
+	return pkg.name + "@" + pkg.version
+}
+
+func isNpmTargetFile(name string) bool {
+	return name == "package.json" || name == "package-lock.json" || name == "npm-shrinkwrap.json"
+}
+
+// IsTarget returns true if the project located on the given path is npm project.
+func (np *npmParser) IsTarget(path *Path, context *Context) bool {
+	if isNpmTargetFile(path.Base()) {
+		return path.Exists(context)
+	}
+	return path.Join("package.json").Exists(context) || path.Join("package-lock.json").Exists(context)
+}
+
+// Parse parses the given path as package-lock.json (or package.json) and returns the instance of Project.
+// If no lockfiles exist, the dependencies are resolved from node_modules, and then, the registry.
+func (np *npmParser) Parse(path *Path) (*Project, error) {
+	dir := path
+	if isNpmTargetFile(path.Base()) {
+		dir = path.Dir()
+	}
+	if np.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", np.context.Depth, 0)
+	}
+	for _, name := range npmLockFileNames {
+		if lockPath := dir.Join(name); lockPath.Exists(np.context) {
+			tree, err := readPackageLock(lockPath, np.context)
+			if err != nil {
+				return nil, err
+			}
+			return tree.constructProject(tree.rootPackage(), 0)
+		}
+	}
+	if !dir.Join("package.json").Exists(np.context) {
+		return nil, fmt.Errorf("%s: not npm project (package.json not found)", dir.Path)
+	}
+	tree := newNpmTree(dir, np.context, func(location string) (*npmManifest, bool) {
+		manifest, err := readNpmManifest(dir.Join(location).Join("package.json"), np.context)
+		return manifest, err == nil
+	})
+	tree.registryFallback = true
+	return tree.constructProject(tree.rootPackage(), 0)
+}
+
+// npmTree resolves the dependency graph of the packages located in the project (node_modules layout).
+type npmTree struct {
+	root    *Path
+	context *Context
+	// lookup returns the package located on the given relative location from the root.
+	lookup func(location string) (*npmManifest, bool)
+	// links maps the location of the symbolic link (e.g., workspaces) to the location of the target.
+	links map[string]string
+	// registryFallback shows the packages not found in the project are resolved from the registry.
+	registryFallback bool
+	located          map[string]*npmPackage
+}
+
+func newNpmTree(root *Path, context *Context, lookup func(location string) (*npmManifest, bool)) *npmTree {
+	return &npmTree{root: root, context: context, lookup: lookup, links: map[string]string{}, located: map[string]*npmPackage{}}
+}
+
+func (tree *npmTree) rootPackage() *npmPackage {
+	pkg, ok := tree.packageAt("", true)
+	if !ok {
+		return &npmPackage{name: tree.root.Path, requires: map[string]string{}}
+	}
+	if pkg.name == "" {
+		pkg.name = tree.root.Base()
+	}
+	return pkg
+}
+
+func (tree *npmTree) packageAt(location string, root bool) (*npmPackage, bool) {
+	if target, ok := tree.links[location]; ok {
+		location = target
+	}
+	if pkg, ok := tree.located[location]; ok {
+		return pkg, true
+	}
+	manifest, ok := tree.lookup(location)
+	if !ok {
+		return nil, false
+	}
+	pkg := newNpmPackage(manifest, root)
+	pkg.dir = location
+	if pkg.name == "" {
+		pkg.name = npmPackageNameOf(location)
+	}
+	tree.located[location] = pkg
+	return pkg, true
+}
+
+func newNpmPackage(manifest *npmManifest, root bool) *npmPackage {
+	pkg := &npmPackage{name: manifest.Name, version: manifest.Version, licenses: manifest.licenseList(nil, nil), requires: map[string]string{}}
+	dependencies := []map[string]string{manifest.PeerDependencies, manifest.OptionalDependencies, manifest.Dependencies}
+	if root {
+		dependencies = append(dependencies, manifest.DevDependencies)
+	}
+	for _, deps := range dependencies {
+		for name, spec := range deps {
+			pkg.requires[name] = spec
+		}
+	}
+	return pkg
+}
+
+// npmPackageNameOf returns the package name from the given location, e.g., `@scope/b` of `node_modules/a/node_modules/@scope/b`.
+func npmPackageNameOf(location string) string {
+	index := strings.LastIndex(location, "node_modules/")
+	if index < 0 {
+		return path.Base(location)
+	}
+	return location[index+len("node_modules/"):]
+}
+
+// parentNpmLocation returns the location whose node_modules contains the given location, as the node.js module resolution.
+func parentNpmLocation(location string) string {
+	index := strings.LastIndex(location, "node_modules/")
+	if index < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(location[:index], "/")
+}
+
+// resolve finds the package of the given name required by the given package.
+// The package is searched from node_modules of the given package to the ones of its ancestors.
+func (tree *npmTree) resolve(from *npmPackage, name string) (*npmPackage, bool) {
+	if !from.registry {
+		location := from.dir
+		for {
+			if pkg, ok := tree.packageAt(path.Join(location, "node_modules", name), false); ok {
+				return pkg, true
+			}
+			if location == "" {
+				break
+			}
+			location = parentNpmLocation(location)
+		}
+	}
+	if tree.registryFallback {
+		return resolveNpmPackageViaRegistry(name, from.requires[name], tree.context)
+	}
+	return nil, false
+}
+
+func (tree *npmTree) dependencies(pkg *npmPackage) []*npmPackage {
+	if pkg.dependencies != nil {
+		return pkg.dependencies
+	}
+	pkg.dependencies = []*npmPackage{}
+	for _, name := range sortedNpmNames(pkg.requires) {
+		if dependency, ok := tree.resolve(pkg, name); ok {
+			pkg.dependencies = append(pkg.dependencies, dependency)
+		} else {
+			logger.Debugf("%s: dependency %s@%s not found", pkg.Name(), name, pkg.requires[name])
+		}
+	}
+	return pkg.dependencies
+}
+
+func sortedNpmNames(requires map[string]string) []string {
+	names := []string{}
+	for name := range requires {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (tree *npmTree) constructProject(pkg *npmPackage, currentDepth int) (*Project, error) {
+	if tree.context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", tree.context.Depth, currentDepth)
+	}
+	logger.Infof("constructNpmProject(%s, %d)", pkg.Name(), currentDepth)
+	project := tree.context.NewProject(pkg.Name(), tree.findLicenses(pkg))
+	// the versions of the dependencies of the package from the registry are unknown without accessing the registry,
+	// therefore, they are not listed at the depth limit.
+	if currentDepth >= tree.context.Depth && pkg.registry {
+		return project, nil
+	}
+	dependencies := tree.dependencies(pkg)
+	for _, dependency := range dependencies {
+		project.Deps = append(project.Deps, dependency.Name())
+	}
+	for _, dependency := range dependencies {
+		if _, ok := tree.context.SearchCache(dependency.Name()); ok {
+			continue
+		}
+		tree.constructProject(dependency, currentDepth+1)
+	}
+	return project, nil
+}
+
+// findLicenses finds the licenses of the given package from package.json in node_modules, the lockfile, and the registry in this order.
+func (tree *npmTree) findLicenses(pkg *npmPackage) Licenses {
+	if !pkg.registry {
+		dir := tree.root.Join(pkg.dir)
+		if manifest, err := readNpmManifest(dir.Join("package.json"), tree.context); err == nil && manifest.Version == pkg.version {
+			if licenses := manifest.licenseList(dir, tree.context); len(licenses) > 0 {
+				return licenses
+			}
+			return findLicensesInDir(dir, tree.context)
+		}
+	}
+	if len(pkg.licenses) > 0 {
+		return pkg.licenses
+	}
+	if manifest, err := findNpmManifestViaRegistry(pkg.name, pkg.version, tree.context); err == nil {
+		return manifest.licenseList(nil, tree.context)
+	}
+	return Licenses{}
+}
+
+func readNpmManifest(path *Path, context *Context) (*npmManifest, error) {
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &npmManifest{}
+	if err := json.Unmarshal([]byte(content), manifest); err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return manifest, nil
+}
+
+type npmLicenseObject struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// licenseList returns the licenses declared in the `license` (or deprecated `licenses`) field.
+// The value `SEE LICENSE IN <file>` refers the license file in the given package directory.
+func (manifest *npmManifest) licenseList(dir *Path, context *Context) Licenses {
+	licenses := Licenses{}
+	for _, raw := range []json.RawMessage{manifest.License, manifest.Licenses} {
+		for _, value := range parseNpmLicenseValues(raw) {
+			for _, license := range npmLicenses(value, dir, context) {
+				licenses = appendLicenseIfAbsent(licenses, license)
+			}
+		}
+	}
+	return licenses
+}
+
+// parseNpmLicenseValues parses the license field, which is the string, the object (`{"type": ..., "url": ...}`), or the array of them.
+func parseNpmLicenseValues(raw json.RawMessage) []*npmLicenseObject {
+	if len(raw) == 0 {
+		return []*npmLicenseObject{}
+	}
+	var array []json.RawMessage
+	if err := json.Unmarshal(raw, &array); err == nil {
+		values := []*npmLicenseObject{}
+		for _, item := range array {
+			values = append(values, parseNpmLicenseValues(item)...)
+		}
+		return values
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return []*npmLicenseObject{{Type: value}}
+	}
+	object := &npmLicenseObject{}
+	if err := json.Unmarshal(raw, object); err == nil && object.Type != "" {
+		return []*npmLicenseObject{object}
+	}
+	return []*npmLicenseObject{}
+}
+
+func npmLicenses(value *npmLicenseObject, dir *Path, context *Context) Licenses {
+	if strings.HasPrefix(value.Type, "SEE LICENSE IN ") {
+		if dir == nil {
+			return Licenses{UnknownLicense}
+		}
+		license, _ := readLicense(dir.Join(strings.TrimSpace(strings.TrimPrefix(value.Type, "SEE LICENSE IN "))), context)
+		return Licenses{license}
+	}
+	if value.Type == "UNLICENSED" {
+		return Licenses{&License{Name: "UNLICENSED", SpdxID: "", URL: ""}}
+	}
+	licenses := parseSpdxExpression(value.Type)
+	if value.URL != "" && len(licenses) == 1 {
+		licenses[0].URL = value.URL
+	}
+	return licenses
+}
@@ -0,0 +1,143 @@
+package purplecat
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseNpm(t *testing.T) {
+	testdata := []struct {
+		path     string
+		wontName string
+		wontDeps []string
+	}{
+		{"testdata/npmproject", "npmproject4test@1.0.0", []string{"@scope/libb@2.1.3", "liba@1.2.0"}},
+		{"testdata/npmproject/package-lock.json", "npmproject4test@1.0.0", []string{"@scope/libb@2.1.3", "liba@1.2.0"}},
+		{"testdata/npmlockv1project", "npmlockv1project4test@0.1.0", []string{"liba@1.2.0", "libc@0.2.0"}},
+	}
+	for _, td := range testdata {
+		parser := &npmParser{context: NewContext(true, "json", 2)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		validateResolvedTree(t, tree, td.wontName, nil, td.wontDeps)
+	}
+}
+
+func TestWriteNpmCyclicDependencies(t *testing.T) {
+	testdata := []struct {
+		format string
+		wont   string
+	}{
+		{"markdown", "            * liba@1.0.0 (cyclic): [MIT License]\n"},
+		{"csv", "liba@1.0.0 (cyclic),MIT License,libb@1.0.0\n"},
+		{"json", `"dependencies":[{"project-name":"liba@1.0.0","license-names":["MIT License"],"cyclic":true}]`},
+		{"yaml", "cyclic:true"},
+		{"xml", "<cyclic>true</cyclic>"},
+	}
+	for _, td := range testdata {
+		context := NewContext(true, td.format, 5)
+		tree, err := (&npmParser{context: context}).Parse(NewPath("testdata/npmcyclicproject"))
+		if err != nil {
+			t.Errorf("testdata/npmcyclicproject: parse failed: %s", err.Error())
+			continue
+		}
+		out := &bytes.Buffer{}
+		writer, _ := context.NewWriter(out)
+		writer.Write(tree)
+		if !strings.Contains(out.String(), td.wont) || strings.Count(out.String(), "libb@1.0.0") > 2 {
+			t.Errorf("%s: output wont contain %s, and the cycle wont be repeated, got %s", td.format, td.wont, out.String())
+		}
+	}
+}
+
+func TestParseNpmLicenses(t *testing.T) {
+	parser := &npmParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/npmproject"))
+	if err != nil {
+		t.Errorf("testdata/npmproject: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree, "npmproject4test@1.0.0", []string{"MIT"}, []string{"@scope/libb@2.1.3", "liba@1.2.0"})
+	libb, liba := tree.Dependencies()[0], tree.Dependencies()[1]
+	validateResolvedTree(t, libb, "@scope/libb@2.1.3", []string{"MIT", "Apache-2.0"}, []string{"libc@0.2.0"})
+	validateResolvedTree(t, liba, "liba@1.2.0", []string{"Apache-2.0"}, []string{"libc@0.1.5"})
+	validateResolvedTree(t, libb.Dependencies()[0], "libc@0.2.0", []string{"BSD-3-Clause"}, []string{})
+	validateResolvedTree(t, liba.Dependencies()[0], "libc@0.1.5", []string{"ISC"}, []string{})
+}
+
+func TestParseNpmViaRegistry(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata/npmregistry")))
+	defer server.Close()
+	defer setEnv(map[string]string{"NPM_CONFIG_REGISTRY": server.URL})()
+
+	parser := &npmParser{context: NewContext(false, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/npmnolockproject"))
+	if err != nil {
+		t.Errorf("testdata/npmnolockproject: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree, "npmnolockproject4test@1.0.0", nil, []string{"liba@1.2.0", "libd@1.2.0"})
+	liba, libd := tree.Dependencies()[0], tree.Dependencies()[1]
+	validateResolvedTree(t, liba, "liba@1.2.0", []string{"MIT"}, []string{})
+	if liba.Licenses()[0].URL != "https://example.com/liba/LICENSE" {
+		t.Errorf("liba@1.2.0: license url did not match, wont https://example.com/liba/LICENSE, got %s", liba.Licenses()[0].URL)
+	}
+	validateResolvedTree(t, libd, "libd@1.2.0", []string{"Apache-2.0"}, []string{"libe@1.0.1"})
+	validateResolvedTree(t, libd.Dependencies()[0], "libe@1.0.1", []string{"ISC"}, []string{})
+}
+
+func TestParseNpmOffline(t *testing.T) {
+	parser := &npmParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/npmnolockproject/package.json"))
+	if err != nil {
+		t.Errorf("testdata/npmnolockproject: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree, "npmnolockproject4test@1.0.0", nil, []string{"liba@1.2.0"})
+}
+
+func TestParseNpmOverDepth(t *testing.T) {
+	parser := &npmParser{context: NewContext(true, "json", -1)}
+	if _, err := parser.Parse(NewPath("testdata/npmproject")); err == nil {
+		t.Errorf("Parse with depth -1 wont error, but got nil")
+	}
+}
+
+func TestNpmLicenseList(t *testing.T) {
+	testdata := []struct {
+		manifest   string
+		wontSpdxID []string
+	}{
+		{`{"license": "MIT"}`, []string{"MIT"}},
+		{`{"license": "(MIT OR Apache-2.0)"}`, []string{"MIT", "Apache-2.0"}},
+		{`{"license": {"type": "ISC", "url": "https://example.com"}}`, []string{"ISC"}},
+		{`{"licenses": [{"type": "MIT"}, {"type": "GPL-2.0"}]}`, []string{"MIT", "GPL-2.0-only"}},
+		{`{"license": "UNLICENSED"}`, []string{""}},
+		{`{"license": "SEE LICENSE IN LICENSE.txt"}`, []string{"unknown"}},
+		{`{}`, []string{}},
+	}
+	for _, td := range testdata {
+		manifest := &npmManifest{}
+		if err := json.Unmarshal([]byte(td.manifest), manifest); err != nil {
+			t.Errorf("%s: unmarshal failed: %s", td.manifest, err.Error())
+			continue
+		}
+		licenses := manifest.licenseList(nil, nil)
+		if len(licenses) != len(td.wontSpdxID) {
+			t.Errorf("%s: license count did not match, wont %d, got %d", td.manifest, len(td.wontSpdxID), len(licenses))
+			continue
+		}
+		for i, license := range licenses {
+			if license.SpdxID != td.wontSpdxID[i] {
+				t.Errorf("%s: license[%d] did not match, wont %s, got %s", td.manifest, i, td.wontSpdxID[i], license.SpdxID)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package purplecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// npmLockFile represents package-lock.json (and npm-shrinkwrap.json).
+// lockfileVersion 1 has only dependencies, 3 has only packages, and 2 has both of them for the backward compatibility.
+type npmLockFile struct {
+	Name            string                     `json:"name"`
+	Version         string                     `json:"version"`
+	LockfileVersion int                        `json:"lockfileVersion"`
+	Packages        map[string]*npmLockPackage `json:"packages"`
+	Dependencies    map[string]*npmLockEntry   `json:"dependencies"`
+}
+
+// npmLockPackage is the package in `packages` of package-lock.json, whose key is the location (e.g., `node_modules/a`).
+type npmLockPackage struct {
+	npmManifest
+	// Link shows the package is a symbolic link (e.g., workspaces) to Resolved.
+	Link     bool   `json:"link"`
+	Resolved string `json:"resolved"`
+}
+
+// npmLockEntry is the package in `dependencies` of package-lock.json v1, which nests the dependencies installed under its node_modules.
+type npmLockEntry struct {
+	Version      string                   `json:"version"`
+	Requires     map[string]string        `json:"requires"`
+	Dependencies map[string]*npmLockEntry `json:"dependencies"`
+}
+
+func readPackageLock(lockPath *Path, context *Context) (*npmTree, error) {
+	content, err := readText(lockPath, context)
+	if err != nil {
+		return nil, err
+	}
+	lockFile := &npmLockFile{}
+	if err := json.Unmarshal([]byte(content), lockFile); err != nil {
+		return nil, fmt.Errorf("%s: %s", lockPath.Path, err.Error())
+	}
+	packages := lockFile.Packages
+	if lockFile.LockfileVersion < 2 || len(packages) == 0 {
+		packages = convertNpmLockEntries(lockFile, lockPath.Dir(), context)
+	}
+	tree := newNpmTree(lockPath.Dir(), context, func(location string) (*npmManifest, bool) {
+		pkg, ok := packages[location]
+		if !ok {
+			return nil, false
+		}
+		return &pkg.npmManifest, true
+	})
+	for location, pkg := range packages {
+		if pkg.Link {
+			tree.links[location] = pkg.Resolved
+		}
+	}
+	return tree, nil
+}
+
+// convertNpmLockEntries converts the nested dependencies of package-lock.json v1 into the packages keyed by the locations.
+// Since v1 has no root package, the root is read from package.json, or built from all of the top-level dependencies.
+func convertNpmLockEntries(lockFile *npmLockFile, dir *Path, context *Context) map[string]*npmLockPackage {
+	packages := map[string]*npmLockPackage{}
+	root, err := readNpmManifest(dir.Join("package.json"), context)
+	if err != nil {
+		root = &npmManifest{Name: lockFile.Name, Version: lockFile.Version, Dependencies: map[string]string{}}
+		for name, entry := range lockFile.Dependencies {
+			root.Dependencies[name] = entry.Version
+		}
+	}
+	packages[""] = &npmLockPackage{npmManifest: *root}
+	appendNpmLockEntries(packages, "", lockFile.Dependencies)
+	return packages
+}
+
+func appendNpmLockEntries(packages map[string]*npmLockPackage, parent string, entries map[string]*npmLockEntry) {
+	for name, entry := range entries {
+		location := path.Join(parent, "node_modules", name)
+		packages[location] = &npmLockPackage{npmManifest: npmManifest{Name: name, Version: entry.Version, Dependencies: entry.Requires}}
+		appendNpmLockEntries(packages, location, entry.Dependencies)
+	}
+}
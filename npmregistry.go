@@ -0,0 +1,89 @@
+package purplecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// defaultNpmRegistry is the default registry of npm.
+const defaultNpmRegistry = "https://registry.npmjs.org"
+
+// npmPackument represents the package metadata (packument) returned from `$registry/<package name>`.
+type npmPackument struct {
+	DistTags map[string]string       `json:"dist-tags"`
+	Versions map[string]*npmManifest `json:"versions"`
+}
+
+// npmRegistry returns the url of the registry from NPM_CONFIG_REGISTRY environment, or the default registry.
+func npmRegistry() string {
+	for _, name := range []string{"NPM_CONFIG_REGISTRY", "npm_config_registry"} {
+		if value := os.Getenv(name); value != "" {
+			return strings.TrimSuffix(value, "/")
+		}
+	}
+	return defaultNpmRegistry
+}
+
+// escapeNpmPackageName escapes the slash in the scoped package name, e.g., `@scope%2fname`.
+func escapeNpmPackageName(name string) string {
+	return strings.ReplaceAll(name, "/", "%2f")
+}
+
+func readNpmPackument(name string, context *Context) (*npmPackument, error) {
+	if !context.Allow(NetworkAccessFlag) {
+		return nil, fmt.Errorf("%s: network access denied", name)
+	}
+	path := NewPath(fmt.Sprintf("%s/%s", npmRegistry(), escapeNpmPackageName(name)))
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	packument := &npmPackument{}
+	if err := json.Unmarshal([]byte(content), packument); err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return packument, nil
+}
+
+// findNpmManifestViaRegistry returns package.json of the given version of the package in the registry.
+func findNpmManifestViaRegistry(name, version string, context *Context) (*npmManifest, error) {
+	packument, err := readNpmPackument(name, context)
+	if err != nil {
+		return nil, err
+	}
+	manifest, ok := packument.Versions[version]
+	if !ok {
+		return nil, fmt.Errorf("%s@%s: version not found in the registry", name, version)
+	}
+	return manifest, nil
+}
+
+// resolveNpmPackageViaRegistry finds the latest version of the package satisfying the given range (or dist-tag) from the registry.
+func resolveNpmPackageViaRegistry(name, spec string, context *Context) (*npmPackage, bool) {
+	logger.Infof("resolveNpmPackageViaRegistry(%s@%s)", name, spec)
+	packument, err := readNpmPackument(name, context)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+		return nil, false
+	}
+	version, ok := packument.DistTags[spec]
+	if !ok {
+		versions := []string{}
+		for version := range packument.Versions {
+			versions = append(versions, version)
+		}
+		version = maxSatisfyingNpmVersion(versions, spec)
+	}
+	manifest, ok := packument.Versions[version]
+	if !ok {
+		logger.Debugf("%s@%s: no versions satisfying the range", name, spec)
+		return nil, false
+	}
+	pkg := newNpmPackage(manifest, false)
+	pkg.name, pkg.version, pkg.registry = name, version, true
+	return pkg, true
+}
@@ -0,0 +1,217 @@
+package purplecat
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// npmComparator represents the primitive comparator of the version range of npm, e.g., `>=1.2.3`.
+// The version is in the form of Go module version (`v1.2.3`) for comparing by compareGoVersion.
+type npmComparator struct {
+	operator string
+	version  string
+}
+
+// npmPartialVersion represents the version in the version range, which may omit the components, e.g., `1.2`, or `1.x`.
+type npmPartialVersion struct {
+	numbers    [3]int
+	specified  int
+	prerelease string
+}
+
+var npmOperatorSpaces = regexp.MustCompile(`(>=|<=|>|<|=|~>|~|\^)\s+`)
+
+// maxSatisfyingNpmVersion returns the latest version satisfying the given range in the given versions.
+// If no versions satisfy the range, this function returns the empty string.
+func maxSatisfyingNpmVersion(versions []string, spec string) string {
+	max := ""
+	for _, version := range versions {
+		if satisfiesNpmRange(version, spec) && (max == "" || compareGoVersion("v"+version, "v"+max) > 0) {
+			max = version
+		}
+	}
+	return max
+}
+
+// satisfiesNpmRange reports whether the given version satisfies the given version range in the manner of node-semver.
+func satisfiesNpmRange(version, spec string) bool {
+	goVersion := "v" + strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if !isValidGoVersion(goVersion) {
+		return false
+	}
+	for _, set := range strings.Split(spec, "||") {
+		comparators, ok := parseNpmComparatorSet(strings.TrimSpace(set))
+		if ok && satisfiesNpmComparators(goVersion, comparators) {
+			return true
+		}
+	}
+	return false
+}
+
+func satisfiesNpmComparators(version string, comparators []*npmComparator) bool {
+	for _, comparator := range comparators {
+		if !comparator.match(version) {
+			return false
+		}
+	}
+	if goVersionPrerelease(version) == "" {
+		return true
+	}
+	// the pre-release versions satisfy the range only if the comparators contain the pre-release of the same version.
+	for _, comparator := range comparators {
+		if goVersionPrerelease(comparator.version) != "" && compareGoVersion(stripGoVersionPrerelease(comparator.version), stripGoVersionPrerelease(version)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func stripGoVersionPrerelease(version string) string {
+	if index := strings.Index(version, "-"); index >= 0 {
+		return version[:index]
+	}
+	return version
+}
+
+func (comparator *npmComparator) match(version string) bool {
+	result := compareGoVersion(version, comparator.version)
+	switch comparator.operator {
+	case ">=":
+		return result >= 0
+	case ">":
+		return result > 0
+	case "<=":
+		return result <= 0
+	case "<":
+		return result < 0
+	}
+	return result == 0
+}
+
+// parseNpmComparatorSet parses the comparators joined by white spaces (e.g., `>=1.2.3 <2.0.0`) or the hyphen range (e.g., `1.2 - 2`).
+func parseNpmComparatorSet(set string) ([]*npmComparator, bool) {
+	if items := strings.Split(set, " - "); len(items) == 2 {
+		from, ok1 := parseNpmPartialVersion(strings.TrimSpace(items[0]))
+		to, ok2 := parseNpmPartialVersion(strings.TrimSpace(items[1]))
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		return append(expandNpmComparator(">=", from), expandNpmComparator("<=", to)...), true
+	}
+	comparators := []*npmComparator{}
+	for _, token := range strings.Fields(npmOperatorSpaces.ReplaceAllString(set, "$1")) {
+		operator := ""
+		for _, candidate := range []string{">=", "<=", "~>", ">", "<", "=", "~", "^"} {
+			if strings.HasPrefix(token, candidate) {
+				operator = candidate
+				break
+			}
+		}
+		partial, ok := parseNpmPartialVersion(strings.TrimPrefix(token, operator))
+		if !ok {
+			return nil, false
+		}
+		comparators = append(comparators, expandNpmComparator(operator, partial)...)
+	}
+	return comparators, true
+}
+
+// parseNpmPartialVersion parses the version in the range; the omitted components and `x`, `X`, and `*` are the wildcards.
+func parseNpmPartialVersion(value string) (*npmPartialVersion, bool) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "v"), "=")
+	if index := strings.Index(value, "+"); index >= 0 {
+		value = value[:index]
+	}
+	partial := &npmPartialVersion{}
+	if index := strings.Index(value, "-"); index >= 0 {
+		value, partial.prerelease = value[:index], value[index+1:]
+	}
+	if value == "" {
+		return partial, partial.prerelease == ""
+	}
+	for i, item := range strings.Split(value, ".") {
+		if i >= 3 {
+			return nil, false
+		}
+		if item == "x" || item == "X" || item == "*" {
+			break
+		}
+		number, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, false
+		}
+		partial.numbers[i] = number
+		partial.specified = i + 1
+	}
+	if partial.specified < 3 {
+		partial.prerelease = ""
+	}
+	return partial, true
+}
+
+func (partial *npmPartialVersion) goVersion() string {
+	version := fmt.Sprintf("v%d.%d.%d", partial.numbers[0], partial.numbers[1], partial.numbers[2])
+	if partial.prerelease != "" {
+		version = version + "-" + partial.prerelease
+	}
+	return version
+}
+
+// bump returns the version incremented the component at the given index, e.g., bump(1) of `1.2.3` is `v1.3.0`.
+func (partial *npmPartialVersion) bump(index int) string {
+	numbers := partial.numbers
+	numbers[index]++
+	for i := index + 1; i < len(numbers); i++ {
+		numbers[i] = 0
+	}
+	return fmt.Sprintf("v%d.%d.%d", numbers[0], numbers[1], numbers[2])
+}
+
+// caretIndex returns the index of the component which `^` allows to change, that is, the left-most non-zero component.
+func (partial *npmPartialVersion) caretIndex() int {
+	for i := 0; i < 2; i++ {
+		if partial.numbers[i] != 0 || partial.specified <= i+1 {
+			return i
+		}
+	}
+	return 2
+}
+
+// expandNpmComparator converts the given operator and version into the primitive comparators (`>=`, `>`, `<=`, `<`, and `=`).
+func expandNpmComparator(operator string, partial *npmPartialVersion) []*npmComparator {
+	if partial.specified == 0 {
+		if operator == "<" || operator == ">" {
+			return []*npmComparator{{"<", "v0.0.0"}}
+		}
+		return []*npmComparator{}
+	}
+	lower := &npmComparator{">=", partial.goVersion()}
+	switch operator {
+	case "~", "~>":
+		index := 1
+		if partial.specified == 1 {
+			index = 0
+		}
+		return []*npmComparator{lower, {"<", partial.bump(index)}}
+	case "^":
+		return []*npmComparator{lower, {"<", partial.bump(partial.caretIndex())}}
+	case ">=", "<":
+		return []*npmComparator{{operator, partial.goVersion()}}
+	case ">":
+		if partial.specified == 3 {
+			return []*npmComparator{{">", partial.goVersion()}}
+		}
+		return []*npmComparator{{">=", partial.bump(partial.specified - 1)}}
+	case "<=":
+		if partial.specified == 3 {
+			return []*npmComparator{{"<=", partial.goVersion()}}
+		}
+		return []*npmComparator{{"<", partial.bump(partial.specified - 1)}}
+	}
+	if partial.specified == 3 {
+		return []*npmComparator{{"=", partial.goVersion()}}
+	}
+	return []*npmComparator{lower, {"<", partial.bump(partial.specified - 1)}}
+}
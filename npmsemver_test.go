@@ -0,0 +1,60 @@
+package purplecat
+
+import "testing"
+
+func TestSatisfiesNpmRange(t *testing.T) {
+	testdata := []struct {
+		version string
+		spec    string
+		wont    bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"1.2.4", "=1.2.3", false},
+		{"1.9.0", "^1.2.3", true},
+		{"2.0.0", "^1.2.3", false},
+		{"0.2.9", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.4", "^0.0.3", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.9.0", "~1", true},
+		{"1.5.0", "1.x", true},
+		{"2.0.0", "1.x", false},
+		{"3.0.0", "*", true},
+		{"3.0.0", "", true},
+		{"1.5.0", ">=1.2.3 <2.0.0", true},
+		{"2.0.0", ">= 1.2.3 < 2.0.0", false},
+		{"1.3.0", ">1.2", true},
+		{"1.2.9", ">1.2", false},
+		{"1.2.9", "<=1.2", true},
+		{"2.3.4", "1.2 - 2.3.4", true},
+		{"2.4.0", "1.2.3 - 2.3", false},
+		{"3.1.0", "^1.0.0 || ^3.0.0", true},
+		{"1.3.0-beta.1", "^1.2.0", false},
+		{"1.3.0-beta.2", "^1.3.0-beta.1", true},
+		{"1.2.3", "latest", false},
+	}
+	for _, td := range testdata {
+		if got := satisfiesNpmRange(td.version, td.spec); got != td.wont {
+			t.Errorf("satisfiesNpmRange(%s, %s) did not match, wont %v, got %v", td.version, td.spec, td.wont, got)
+		}
+	}
+}
+
+func TestMaxSatisfyingNpmVersion(t *testing.T) {
+	versions := []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0-beta.1", "2.0.0"}
+	testdata := []struct {
+		spec string
+		wont string
+	}{
+		{"^1.1.0", "1.2.0"},
+		{"~1.1.0", "1.1.0"},
+		{">=1.0.0", "2.0.0"},
+		{"^3.0.0", ""},
+	}
+	for _, td := range testdata {
+		if got := maxSatisfyingNpmVersion(versions, td.spec); got != td.wont {
+			t.Errorf("maxSatisfyingNpmVersion(%s) did not match, wont %s, got %s", td.spec, td.wont, got)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package purplecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// nugetParser is the instance of Parser for parsing the SDK-style project file (*.csproj) and packages.lock.json of NuGet.
+type nugetParser struct {
+	context *Context
+}
+
+// nugetPackage represents the package in the dependency graph of NuGet.
+type nugetPackage struct {
+	id      string
+	version string
+	// dependencies is resolved from packages.lock.json; nil shows the dependencies are read from the nuspec.
+	dependencies []*nugetPackage
+}
+
+func (pkg *nugetPackage) Name() string {
+	if pkg.version == "" {
+		return pkg.id
+	}
+	return pkg.id + "@" + pkg.version
+}
+
+// nugetProjectFile represents the SDK-style project file.
+type nugetProjectFile struct {
+	name        string
+	version     string
+	license     string
+	licenseFile string
+	references  []*nugetReference
+}
+
+// nugetReference is the PackageReference item in the project file, version is the version range of NuGet (e.g., `13.0.1`, and `[1.0, 2.0)`).
+type nugetReference struct {
+	id      string
+	version string
+}
+
+// nugetLockFile represents packages.lock.json, whose dependencies are keyed by the target frameworks (e.g., `net6.0`).
+type nugetLockFile struct {
+	Dependencies map[string]map[string]*nugetLockEntry `json:"dependencies"`
+}
+
+type nugetLockEntry struct {
+	// Type is `Direct`, `Transitive`, `CentralTransitive`, or `Project`.
+	Type         string            `json:"type"`
+	Resolved     string            `json:"resolved"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+func isNuGetTargetFile(name string) bool {
+	return name == "packages.lock.json" || strings.HasSuffix(name, ".csproj")
+}
+
+// findNuGetProjectFile returns the project file (*.csproj) in the given directory.
+func findNuGetProjectFile(dir *Path) (*Path, bool) {
+	matches, _ := filepath.Glob(filepath.Join(dir.Path, "*.csproj"))
+	if len(matches) == 0 {
+		return nil, false
+	}
+	sort.Strings(matches)
+	return NewPath(matches[0]), true
+}
+
+// IsTarget returns true if the project located on the given path is .NET project.
+func (np *nugetParser) IsTarget(path *Path, context *Context) bool {
+	if isNuGetTargetFile(path.Base()) {
+		return path.Exists(context)
+	}
+	if _, ok := findNuGetProjectFile(path); ok {
+		return true
+	}
+	return path.Join("packages.lock.json").Exists(context)
+}
+
+// Parse parses the given path as the project file (or packages.lock.json) and returns the instance of Project.
+// If packages.lock.json does not exist, the dependencies are resolved from the nuspecs of the packages.
+func (np *nugetParser) Parse(path *Path) (*Project, error) {
+	dir, projectPath := path, path
+	if isNuGetTargetFile(path.Base()) {
+		dir = path.Dir()
+	}
+	if !strings.HasSuffix(projectPath.Base(), ".csproj") {
+		projectPath, _ = findNuGetProjectFile(dir)
+	}
+	if np.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", np.context.Depth, 0)
+	}
+	logger.Infof("parseNuGetProject(%s)", dir.Path)
+	projectFile := &nugetProjectFile{name: dir.Base(), references: []*nugetReference{}}
+	if projectPath != nil {
+		var err error
+		if projectFile, err = readNuGetProjectFile(projectPath, np.context); err != nil {
+			return nil, err
+		}
+	}
+	root := &nugetPackage{id: projectFile.name, version: projectFile.version}
+	if lockPath := dir.Join("packages.lock.json"); lockPath.Exists(np.context) {
+		dependencies, err := readNuGetLock(lockPath, np.context)
+		if err != nil {
+			return nil, err
+		}
+		root.dependencies = dependencies
+	} else {
+		root.dependencies = []*nugetPackage{}
+		for _, reference := range projectFile.references {
+			root.dependencies = append(root.dependencies, &nugetPackage{id: reference.id, version: nugetMinVersion(reference.version)})
+		}
+	}
+	tree := newNuGetTree(np.context)
+	var licenses Licenses
+	if projectFile.license != "" {
+		licenses = parseSpdxExpression(projectFile.license)
+	} else if projectFile.licenseFile != "" {
+		license, _ := readLicense(dir.Join(projectFile.licenseFile), np.context)
+		licenses = Licenses{license}
+	} else {
+		licenses = findLicensesInDir(dir, np.context)
+	}
+	return tree.constructProject(root, licenses, 0)
+}
+
+// readNuGetProjectFile reads the project file. The versions of PackageReference are taken from the attribute (or the element) Version,
+// VersionOverride, and PackageVersion in Directory.Packages.props of the central package management in this order.
+func readNuGetProjectFile(path *Path, context *Context) (*nugetProjectFile, error) {
+	logger.Infof("readNuGetProjectFile(%s)", path.Path)
+	doc, err := readXML(path, context)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	projectFile := &nugetProjectFile{name: strings.TrimSuffix(path.Base(), filepath.Ext(path.Base())), references: []*nugetReference{}}
+	if id, ok := getStringByXPath("/Project/PropertyGroup/PackageId", doc); ok && id != "" {
+		projectFile.name = id
+	} else if name, ok := getStringByXPath("/Project/PropertyGroup/AssemblyName", doc); ok && name != "" {
+		projectFile.name = name
+	}
+	projectFile.version, _ = getStringByXPath("/Project/PropertyGroup/Version", doc)
+	projectFile.license, _ = getStringByXPath("/Project/PropertyGroup/PackageLicenseExpression", doc)
+	projectFile.licenseFile, _ = getStringByXPath("/Project/PropertyGroup/PackageLicenseFile", doc)
+	centralVersions := readCentralPackageVersions(path.Dir(), context)
+	list, _ := xmlquery.QueryAll(doc, "/Project/ItemGroup/PackageReference")
+	for _, node := range list {
+		reference := &nugetReference{id: node.SelectAttr("Include")}
+		if reference.id == "" {
+			continue
+		}
+		reference.version = node.SelectAttr("Version")
+		if reference.version == "" {
+			reference.version, _ = getStringByXPath("./Version", node)
+		}
+		if override := node.SelectAttr("VersionOverride"); override != "" {
+			reference.version = override
+		}
+		if reference.version == "" {
+			reference.version = centralVersions[strings.ToLower(reference.id)]
+		}
+		projectFile.references = append(projectFile.references, reference)
+	}
+	return projectFile, nil
+}
+
+// readCentralPackageVersions reads PackageVersion items in Directory.Packages.props, which is searched from the given directory
+// to the ancestors in the same manner as MSBuild. The keys of the result are lower-cased package ids.
+func readCentralPackageVersions(dir *Path, context *Context) map[string]string {
+	versions := map[string]string{}
+	for current := filepath.Clean(dir.Path); ; current = filepath.Dir(current) {
+		propsPath := NewPath(filepath.Join(current, "Directory.Packages.props"))
+		if propsPath.Exists(context) {
+			doc, err := readXML(propsPath, context)
+			if err != nil {
+				logger.Debugf("%s: %s", propsPath.Path, err.Error())
+				return versions
+			}
+			list, _ := xmlquery.QueryAll(doc, "/Project/ItemGroup/PackageVersion")
+			for _, node := range list {
+				versions[strings.ToLower(node.SelectAttr("Include"))] = node.SelectAttr("Version")
+			}
+			return versions
+		}
+		if filepath.Dir(current) == current {
+			return versions
+		}
+	}
+}
+
+// readNuGetLock reads packages.lock.json, and returns the direct dependencies of the project.
+// The packages for all target frameworks are merged, and the first one in the order of the framework names is used.
+func readNuGetLock(path *Path, context *Context) ([]*nugetPackage, error) {
+	logger.Infof("readNuGetLock(%s)", path.Path)
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	lockFile := &nugetLockFile{}
+	if err := json.Unmarshal([]byte(content), lockFile); err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	packages := map[string]*nugetPackage{}
+	entries := map[*nugetPackage]*nugetLockEntry{}
+	direct := []*nugetPackage{}
+	for _, framework := range sortedNuGetKeys(lockFile.Dependencies) {
+		for _, id := range sortedNuGetKeys(lockFile.Dependencies[framework]) {
+			if _, ok := packages[strings.ToLower(id)]; ok {
+				continue
+			}
+			entry := lockFile.Dependencies[framework][id]
+			pkg := &nugetPackage{id: id, version: entry.Resolved}
+			packages[strings.ToLower(id)] = pkg
+			entries[pkg] = entry
+			if entry.Type == "Direct" || entry.Type == "Project" {
+				direct = append(direct, pkg)
+			}
+		}
+	}
+	for pkg, entry := range entries {
+		pkg.dependencies = []*nugetPackage{}
+		for _, id := range sortedNuGetKeys(entry.Dependencies) {
+			if dependency, ok := packages[strings.ToLower(id)]; ok {
+				pkg.dependencies = append(pkg.dependencies, dependency)
+			} else {
+				logger.Debugf("%s: dependency %s not found in packages.lock.json", pkg.Name(), id)
+			}
+		}
+	}
+	return direct, nil
+}
+
+func sortedNuGetKeys(value interface{}) []string {
+	keys := []string{}
+	switch item := value.(type) {
+	case map[string]map[string]*nugetLockEntry:
+		for key := range item {
+			keys = append(keys, key)
+		}
+	case map[string]*nugetLockEntry:
+		for key := range item {
+			keys = append(keys, key)
+		}
+	case map[string]string:
+		for key := range item {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nugetMinVersion returns the lower bound of the given version range of NuGet, since NuGet chooses the lowest applicable version.
+// e.g., `13.0.1` (means `>= 13.0.1`), `[1.0, 2.0)`, and `[2.10.0]`.
+func nugetMinVersion(versionRange string) string {
+	versionRange = strings.TrimSpace(versionRange)
+	versionRange = strings.TrimLeft(versionRange, "[(")
+	versionRange = strings.TrimRight(versionRange, "])")
+	return strings.TrimSpace(strings.Split(versionRange, ",")[0])
+}
+
+// normalizeNuGetVersion normalizes the given version in the same manner as NuGet, which is used in the paths of the packages;
+// the build metadata is removed, the version is lower-cased, and the patch (and the revision) is padded (or removed) (e.g., `1.0` to `1.0.0`).
+func normalizeNuGetVersion(version string) string {
+	if version == "" {
+		return ""
+	}
+	version = strings.ToLower(strings.Split(version, "+")[0])
+	release, prerelease := version, ""
+	if index := strings.Index(version, "-"); index >= 0 {
+		release, prerelease = version[:index], version[index:]
+	}
+	parts := strings.Split(release, ".")
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	if len(parts) == 4 && parts[3] == "0" {
+		parts = parts[:3]
+	}
+	return strings.Join(parts, ".") + prerelease
+}
+
+type nugetTree struct {
+	context *Context
+	// packagesDir is the global packages folder of NuGet.
+	packagesDir string
+	nuspecs     map[string]*nugetSpec
+	// baseAddress is the url of PackageBaseAddress resource in the service index of the feed.
+	baseAddress string
+}
+
+func newNuGetTree(context *Context) *nugetTree {
+	return &nugetTree{context: context, packagesDir: nugetPackagesDir(), nuspecs: map[string]*nugetSpec{}}
+}
+
+func (tree *nugetTree) dependencies(pkg *nugetPackage, remote bool) []*nugetPackage {
+	if pkg.dependencies != nil {
+		return pkg.dependencies
+	}
+	spec, ok := tree.findNuspec(pkg, remote)
+	if !ok {
+		return []*nugetPackage{}
+	}
+	return spec.dependencies
+}
+
+// findLicenses finds the licenses of the given package from the nuspec in the global packages folder, and the feed in this order.
+func (tree *nugetTree) findLicenses(pkg *nugetPackage) Licenses {
+	spec, ok := tree.findNuspec(pkg, true)
+	if !ok {
+		return Licenses{}
+	}
+	return spec.licenseList(tree.context)
+}
+
+func (tree *nugetTree) constructProject(pkg *nugetPackage, licenses Licenses, currentDepth int) (*Project, error) {
+	if tree.context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", tree.context.Depth, currentDepth)
+	}
+	logger.Infof("constructNuGetProject(%s, %d)", pkg.Name(), currentDepth)
+	if licenses == nil {
+		licenses = tree.findLicenses(pkg)
+	}
+	project := tree.context.NewProject(pkg.Name(), licenses)
+	// the nuspecs of the packages at the depth limit are read only from the global packages folder.
+	dependencies := tree.dependencies(pkg, currentDepth < tree.context.Depth)
+	for _, dependency := range dependencies {
+		project.Deps = append(project.Deps, dependency.Name())
+	}
+	for _, dependency := range dependencies {
+		if _, ok := tree.context.SearchCache(dependency.Name()); ok {
+			continue
+		}
+		tree.constructProject(dependency, nil, currentDepth+1)
+	}
+	return project, nil
+}
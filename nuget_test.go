@@ -0,0 +1,154 @@
+package purplecat
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func startNuGetFeed() *httptest.Server {
+	var server *httptest.Server
+	files := http.FileServer(http.Dir("testdata/nugetfeed"))
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.json" {
+			fmt.Fprintf(w, `{"version":"3.0.0","resources":[{"@id":"%s/flatcontainer/","@type":"PackageBaseAddress/3.0.0"}]}`, server.URL)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+	return server
+}
+
+func TestParseNuGet(t *testing.T) {
+	defer setEnv(map[string]string{"NUGET_PACKAGES": "testdata/nugetpackages"})()
+	testdata := []struct {
+		path     string
+		wontName string
+		wontDeps []string
+	}{
+		{"testdata/nugetproject", "NuGetTest@1.0.0", []string{"Humanizer.Core@2.14.1", "Newtonsoft.Json@13.0.1", "Serilog.Sinks.Console@4.0.0"}},
+		{"testdata/nugetproject/NuGetTest.csproj", "NuGetTest@1.0.0", []string{"Humanizer.Core@2.14.1", "Newtonsoft.Json@13.0.1", "Serilog.Sinks.Console@4.0.0"}},
+		{"testdata/nugetproject/packages.lock.json", "NuGetTest@1.0.0", []string{"Humanizer.Core@2.14.1", "Newtonsoft.Json@13.0.1", "Serilog.Sinks.Console@4.0.0"}},
+	}
+	for _, td := range testdata {
+		parser := &nugetParser{context: NewContext(true, "json", 2)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		validateResolvedTree(t, tree, td.wontName, []string{"Apache-2.0"}, td.wontDeps)
+		deps := tree.Dependencies()
+		validateResolvedTree(t, deps[0], "Humanizer.Core@2.14.1", []string{}, []string{})
+		validateResolvedTree(t, deps[1], "Newtonsoft.Json@13.0.1", []string{"MIT"}, []string{})
+		validateResolvedTree(t, deps[2], "Serilog.Sinks.Console@4.0.0", []string{"ISC"}, []string{"Serilog@2.10.0"})
+		validateResolvedTree(t, deps[2].Dependencies()[0], "Serilog@2.10.0", []string{"Apache-2.0"}, []string{})
+	}
+}
+
+func TestParseNuGetWithoutLock(t *testing.T) {
+	defer setEnv(map[string]string{"NUGET_PACKAGES": "testdata/nugetpackages"})()
+	parser := &nugetParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/nugetnolockproject"))
+	if err != nil {
+		t.Errorf("testdata/nugetnolockproject: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree, "Tamadalab.NoLock@0.1.0", []string{"ISC"}, []string{"Legacy.Lib@1.0", "Serilog.Sinks.Console@4.0.0"})
+	deps := tree.Dependencies()
+	validateResolvedTree(t, deps[0], "Legacy.Lib@1.0", []string{}, []string{})
+	validateResolvedTree(t, deps[1], "Serilog.Sinks.Console@4.0.0", []string{"ISC"}, []string{"Serilog@2.10.0"})
+}
+
+func TestParseNuGetViaFeed(t *testing.T) {
+	server := startNuGetFeed()
+	defer server.Close()
+	defer setEnv(map[string]string{"NUGET_PACKAGES": "testdata/nugetpackages", NuGetFeedEnvName: server.URL + "/index.json"})()
+
+	testdata := []struct {
+		path        string
+		depth       int
+		wontName    string
+		wontSpdxIDs []string
+		wontDeps    []string
+	}{
+		{"testdata/nugetproject", 2, "Humanizer.Core@2.14.1", []string{"MIT"}, []string{}},
+		{"testdata/nugetnolockproject", 2, "Legacy.Lib@1.0", []string{""}, []string{"Newtonsoft.Json@13.0.1"}},
+		{"testdata/nugetnolockproject", 1, "Legacy.Lib@1.0", []string{""}, []string{"Newtonsoft.Json@13.0.1"}},
+	}
+	for _, td := range testdata {
+		parser := &nugetParser{context: NewContext(false, "json", td.depth)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		dep := tree.Dependencies()[0]
+		validateResolvedTree(t, dep, td.wontName, td.wontSpdxIDs, td.wontDeps)
+		if license := dep.Licenses()[0]; td.wontName == "Legacy.Lib@1.0" && license.URL != "https://example.com/legacy/LICENSE.txt" {
+			t.Errorf("%s: license url did not match, wont https://example.com/legacy/LICENSE.txt, got %s", td.wontName, license.URL)
+		}
+	}
+}
+
+func TestParseNuGetOverDepth(t *testing.T) {
+	parser := &nugetParser{context: NewContext(true, "json", -1)}
+	if _, err := parser.Parse(NewPath("testdata/nugetproject")); err == nil {
+		t.Errorf("Parse with depth -1 wont error, but got nil")
+	}
+}
+
+func TestReadNuGetProjectFile(t *testing.T) {
+	testdata := []struct {
+		path           string
+		wontName       string
+		wontReferences []string
+	}{
+		{"testdata/nugetproject/NuGetTest.csproj", "NuGetTest", []string{"Humanizer.Core 2.14.1", "Newtonsoft.Json 13.0.1", "Serilog.Sinks.Console 4.0.0"}},
+		{"testdata/nugetnolockproject/NoLock.csproj", "Tamadalab.NoLock", []string{"Legacy.Lib [1.0, 2.0)", "Serilog.Sinks.Console 4.0.0"}},
+	}
+	for _, td := range testdata {
+		projectFile, err := readNuGetProjectFile(NewPath(td.path), NewContext(true, "json", 1))
+		if err != nil {
+			t.Errorf("%s: read failed: %s", td.path, err.Error())
+			continue
+		}
+		if projectFile.name != td.wontName {
+			t.Errorf("%s: name did not match, wont %s, got %s", td.path, td.wontName, projectFile.name)
+		}
+		if len(projectFile.references) != len(td.wontReferences) {
+			t.Errorf("%s: references length did not match, wont %d, got %d", td.path, len(td.wontReferences), len(projectFile.references))
+			continue
+		}
+		for i, reference := range projectFile.references {
+			if got := reference.id + " " + reference.version; got != td.wontReferences[i] {
+				t.Errorf("%s: references[%d] did not match, wont %s, got %s", td.path, i, td.wontReferences[i], got)
+			}
+		}
+	}
+}
+
+func TestNuGetVersions(t *testing.T) {
+	testdata := []struct {
+		giveVersion    string
+		wontMinVersion string
+		wontNormalized string
+	}{
+		{"13.0.1", "13.0.1", "13.0.1"},
+		{"[1.0, 2.0)", "1.0", "1.0.0"},
+		{"[2.10.0]", "2.10.0", "2.10.0"},
+		{"(, 3.0]", "", ""},
+		{"1.0.0.0", "1.0.0.0", "1.0.0"},
+		{"1.2.3.4", "1.2.3.4", "1.2.3.4"},
+		{"1.0-Beta+build.1", "1.0-Beta+build.1", "1.0.0-beta"},
+	}
+	for _, td := range testdata {
+		if got := nugetMinVersion(td.giveVersion); got != td.wontMinVersion {
+			t.Errorf("nugetMinVersion(%s) did not match, wont %s, got %s", td.giveVersion, td.wontMinVersion, got)
+		}
+		if got := normalizeNuGetVersion(nugetMinVersion(td.giveVersion)); got != td.wontNormalized {
+			t.Errorf("normalizeNuGetVersion(%s) did not match, wont %s, got %s", td.giveVersion, td.wontNormalized, got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package purplecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// defaultNuGetFeedURL is the service index of nuget.org.
+const defaultNuGetFeedURL = "https://api.nuget.org/v3/index.json"
+
+// NuGetFeedEnvName is the environment name for the url of the service index of NuGet v3 compatible feed.
+const NuGetFeedEnvName = "PURPLECAT_NUGET_FEED_URL"
+
+// nugetLicenseURLPrefix is the prefix of licenseUrl generated from the license expression by nuget.org.
+const nugetLicenseURLPrefix = "https://licenses.nuget.org/"
+
+// nugetServiceIndex represents the service index of NuGet v3 feed.
+type nugetServiceIndex struct {
+	Resources []struct {
+		ID   string `json:"@id"`
+		Type string `json:"@type"`
+	} `json:"resources"`
+}
+
+// nugetSpec represents the metadata in the nuspec of the package.
+type nugetSpec struct {
+	license string
+	// licenseType is `expression`, or `file` of the license element.
+	licenseType string
+	// licenseURL is the deprecated licenseUrl element.
+	licenseURL string
+	// dir is the extracted package in the global packages folder, and nil for the nuspec from the feed.
+	dir          *Path
+	dependencies []*nugetPackage
+}
+
+// nugetPackagesDir returns the global packages folder of NuGet, which is $NUGET_PACKAGES, or $HOME/.nuget/packages.
+func nugetPackagesDir() string {
+	if dir := os.Getenv("NUGET_PACKAGES"); dir != "" {
+		return dir
+	}
+	home, _ := homedir.Dir()
+	return filepath.Join(home, ".nuget", "packages")
+}
+
+func nugetFeedURL() string {
+	if value := os.Getenv(NuGetFeedEnvName); value != "" {
+		return value
+	}
+	return defaultNuGetFeedURL
+}
+
+// parseNuspec parses the nuspec, the dependencies of all target frameworks are merged.
+func parseNuspec(doc *xmlquery.Node, dir *Path) *nugetSpec {
+	spec := &nugetSpec{dir: dir, dependencies: []*nugetPackage{}}
+	if node, err := xmlquery.Query(doc, "/package/metadata/license"); err == nil && node != nil {
+		spec.license, spec.licenseType = strings.TrimSpace(node.InnerText()), node.SelectAttr("type")
+	}
+	spec.licenseURL, _ = getStringByXPath("/package/metadata/licenseUrl", doc)
+	list, _ := xmlquery.QueryAll(doc, "/package/metadata/dependencies//dependency")
+	found := map[string]bool{}
+	for _, node := range list {
+		id := node.SelectAttr("id")
+		if id == "" || found[strings.ToLower(id)] {
+			continue
+		}
+		found[strings.ToLower(id)] = true
+		spec.dependencies = append(spec.dependencies, &nugetPackage{id: id, version: nugetMinVersion(node.SelectAttr("version"))})
+	}
+	return spec
+}
+
+// licenseList returns the licenses declared in the nuspec. The license file is read only from the global packages folder.
+func (spec *nugetSpec) licenseList(context *Context) Licenses {
+	switch {
+	case spec.licenseType == "expression" && spec.license != "":
+		return parseSpdxExpression(spec.license)
+	case spec.licenseType == "file" && spec.dir != nil:
+		license, _ := readLicense(spec.dir.Join(spec.license), context)
+		return Licenses{license}
+	case strings.HasPrefix(spec.licenseURL, nugetLicenseURLPrefix):
+		return parseSpdxExpression(strings.TrimPrefix(spec.licenseURL, nugetLicenseURLPrefix))
+	case spec.licenseURL != "":
+		return Licenses{&License{Name: spec.licenseURL, URL: spec.licenseURL}}
+	}
+	return Licenses{}
+}
+
+// findNuspec finds the nuspec of the given package from the global packages folder, and the feed in this order.
+// The feed is not accessed unless remote is true.
+func (tree *nugetTree) findNuspec(pkg *nugetPackage, remote bool) (*nugetSpec, bool) {
+	if spec, ok := tree.nuspecs[pkg.Name()]; ok {
+		return spec, spec != nil
+	}
+	if pkg.version == "" {
+		return nil, false
+	}
+	id, version := strings.ToLower(pkg.id), normalizeNuGetVersion(pkg.version)
+	dir := NewPath(filepath.Join(tree.packagesDir, id, version))
+	if doc, err := readXML(dir.Join(id+".nuspec"), tree.context); err == nil {
+		tree.nuspecs[pkg.Name()] = parseNuspec(doc, dir)
+		return tree.nuspecs[pkg.Name()], true
+	}
+	if !remote {
+		return nil, false
+	}
+	spec, err := tree.readNuspecViaFeed(id, version)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+	}
+	tree.nuspecs[pkg.Name()] = spec
+	return spec, spec != nil
+}
+
+// packageBaseAddress returns the url of PackageBaseAddress resource, which is declared in the service index of the feed.
+func (tree *nugetTree) packageBaseAddress() (string, error) {
+	if tree.baseAddress != "" {
+		return tree.baseAddress, nil
+	}
+	indexPath := NewPath(nugetFeedURL())
+	content, err := readText(indexPath, tree.context)
+	if err != nil {
+		return "", err
+	}
+	index := &nugetServiceIndex{}
+	if err := json.Unmarshal([]byte(content), index); err != nil {
+		return "", fmt.Errorf("%s: %s", indexPath.Path, err.Error())
+	}
+	for _, resource := range index.Resources {
+		if strings.HasPrefix(resource.Type, "PackageBaseAddress/3.0.0") {
+			tree.baseAddress = strings.TrimSuffix(resource.ID, "/")
+			return tree.baseAddress, nil
+		}
+	}
+	return "", fmt.Errorf("%s: PackageBaseAddress not found", indexPath.Path)
+}
+
+// readNuspecViaFeed reads the nuspec from `$baseAddress/<id>/<version>/<id>.nuspec` of the feed.
+func (tree *nugetTree) readNuspecViaFeed(id, version string) (*nugetSpec, error) {
+	if !tree.context.Allow(NetworkAccessFlag) {
+		return nil, fmt.Errorf("%s@%s: network access denied", id, version)
+	}
+	logger.Infof("readNuspecViaFeed(%s@%s)", id, version)
+	baseAddress, err := tree.packageBaseAddress()
+	if err != nil {
+		return nil, err
+	}
+	path := NewPath(fmt.Sprintf("%s/%s/%s/%s.nuspec", baseAddress, id, version, id))
+	doc, err := readXML(path, tree.context)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return parseNuspec(doc, nil), nil
+}
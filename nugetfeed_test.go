@@ -0,0 +1,44 @@
+package purplecat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antchfx/xmlquery"
+)
+
+func TestParseNuspec(t *testing.T) {
+	testdata := []struct {
+		giveMetadata string
+		wontSpdxIDs  []string
+		wontDeps     []string
+	}{
+		{`<license type="expression">MIT OR Apache-2.0</license>`, []string{"MIT", "Apache-2.0"}, []string{}},
+		{`<licenseUrl>https://licenses.nuget.org/BSD-3-Clause</licenseUrl>`, []string{"BSD-3-Clause"}, []string{}},
+		{`<license type="file">LICENSE.txt</license>`, []string{}, []string{}},
+		{`<dependencies><dependency id="A" version="1.0.0" /><dependency id="B" version="[2.0, 3.0)" /></dependencies>`, []string{}, []string{"A@1.0.0", "B@2.0"}},
+		{`<dependencies><group targetFramework="net6.0"><dependency id="A" version="1.0.0" /></group><group targetFramework="netstandard2.0"><dependency id="a" version="0.9.0" /></group></dependencies>`, []string{}, []string{"A@1.0.0"}},
+	}
+	for _, td := range testdata {
+		doc, err := xmlquery.Parse(strings.NewReader(`<?xml version="1.0"?><package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd"><metadata>` + td.giveMetadata + `</metadata></package>`))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.giveMetadata, err.Error())
+			continue
+		}
+		spec := parseNuspec(doc, nil)
+		spdxIDs := []string{}
+		for _, license := range spec.licenseList(NewContext(true, "json", 1)) {
+			spdxIDs = append(spdxIDs, license.SpdxID)
+		}
+		if strings.Join(spdxIDs, ",") != strings.Join(td.wontSpdxIDs, ",") {
+			t.Errorf("%s: licenses did not match, wont %v, got %v", td.giveMetadata, td.wontSpdxIDs, spdxIDs)
+		}
+		deps := []string{}
+		for _, dependency := range spec.dependencies {
+			deps = append(deps, dependency.Name())
+		}
+		if strings.Join(deps, ",") != strings.Join(td.wontDeps, ",") {
+			t.Errorf("%s: dependencies did not match, wont %v, got %v", td.giveMetadata, td.wontDeps, deps)
+		}
+	}
+}
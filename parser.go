@@ -12,16 +12,20 @@ type Parser interface {
 	IsTarget(path *Path, context *Context) bool
 }
 
-// GradleParser is the instance of Parser for parsing build.gradle (not yet).
-type gradleParser struct {
-	context *Context
-}
-
 func (context *Context) GenerateParser(path *Path) (Parser, error) {
 	parsers := []Parser{
 		&mavenParser{context: context},
 		&goModParser{context: context},
-		// {"build.gradle", &GradleParser{context: context}},
+		&gradleParser{context: context},
+		&pnpmParser{context: context},
+		&yarnParser{context: context},
+		&npmParser{context: context},
+		&pythonParser{context: context},
+		&cargoParser{context: context},
+		&bundlerParser{context: context},
+		&composerParser{context: context},
+		&nugetParser{context: context},
+		&jarParser{context: context},
 	}
 	for _, parser := range parsers {
 		if parser.IsTarget(path, context) {
@@ -15,6 +15,30 @@ func TestGenerateParser(t *testing.T) {
 		{"./testdata/mavenproject/pom.xml", "mavenParser", true},
 		{"./testdata/goproject", "goModParser", true},
 		{"./testdata/goproject/go.mod", "goModParser", true},
+		{"./testdata/gradleproject", "gradleParser", true},
+		{"./testdata/gradleproject/build.gradle", "gradleParser", true},
+		{"./testdata/gradlektsproject", "gradleParser", true},
+		{"./testdata/npmproject", "npmParser", true},
+		{"./testdata/npmproject/package-lock.json", "npmParser", true},
+		{"./testdata/npmnolockproject/package.json", "npmParser", true},
+		{"./testdata/yarnproject", "yarnParser", true},
+		{"./testdata/yarnberryproject/yarn.lock", "yarnParser", true},
+		{"./testdata/pnpmproject", "pnpmParser", true},
+		{"./testdata/pnpmv9project/pnpm-lock.yaml", "pnpmParser", true},
+		{"./testdata/pythonproject", "pythonParser", true},
+		{"./testdata/pyprojectproject/pyproject.toml", "pythonParser", true},
+		{"./testdata/poetryproject", "pythonParser", true},
+		{"./testdata/pipenvproject/Pipfile.lock", "pythonParser", true},
+		{"./testdata/cargoproject", "cargoParser", true},
+		{"./testdata/cargoproject/Cargo.lock", "cargoParser", true},
+		{"./testdata/cargonolockproject/Cargo.toml", "cargoParser", true},
+		{"./testdata/bundlerproject", "bundlerParser", true},
+		{"./testdata/bundlerproject/Gemfile.lock", "bundlerParser", true},
+		{"./testdata/composerproject", "composerParser", true},
+		{"./testdata/composerproject/composer.json", "composerParser", true},
+		{"./testdata/nugetproject", "nugetParser", true},
+		{"./testdata/nugetproject/NuGetTest.csproj", "nugetParser", true},
+		{"./testdata/nugetproject/packages.lock.json", "nugetParser", true},
 		{"./testdata/unknownproject", "", false},
 		{"./testdata/unknownproject/Makefile", "", false},
 		{"./testdata/missingproject", "", false},
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/go-resty/resty/v2"
@@ -35,6 +36,34 @@ func NewPathWithSupporter(path string, supporter PathSupporter) *Path {
 	return &Path{Path: path, supporter: supporter}
 }
 
+// NewPathWithCredentials creates an pointer of Path represents the given url, which is accessed with the basic authentication.
+// The paths derived from the returned path by Join and Dir are accessed with the same credentials.
+func NewPathWithCredentials(path, username, password string) *Path {
+	newPath := NewPath(path)
+	if newPath.url != nil && username != "" {
+		newPath.supporter = &urlPathSupporter{username: username, password: password}
+	}
+	return newPath
+}
+
+// derive creates the path derived from the receiver by Join or Dir, and the derived path inherits the supporter of the receiver.
+// The supporters given by NewPathWithSupporter (e.g., the request body of the REST API) serve the derived local paths, too.
+func (path *Path) derive(newPath string) *Path {
+	derived := NewPath(newPath)
+	switch supporter := path.supporter.(type) {
+	case *urlPathSupporter:
+		if derived.url != nil {
+			derived.supporter = supporter
+		}
+	case *localFilePathSupporter:
+	default:
+		if derived.url == nil {
+			derived.supporter = supporter
+		}
+	}
+	return derived
+}
+
 // Exists checks existtence of the receiver path.
 //
 // If the receiver path shows url, and context denies the network access,
@@ -58,7 +87,7 @@ func (path *Path) Base() string {
 //     path := NewPath("/some/location/of/local/file")
 //     path2 := path.Join("subfile") // --> base is `/some/location/of/local/file/subfile`
 func (path *Path) Join(append string) *Path {
-	return NewPath(path.supporter.Join(path, append))
+	return path.derive(path.supporter.Join(path, append))
 }
 
 // Open returns ReadCloser for reading the content of the receiver path.
@@ -72,7 +101,7 @@ func (path *Path) Open(context *Context) (io.ReadCloser, error) {
 //     path := NewPath("/some/location/of/local/file")
 //     dir := path.Dir() // --> dir is `/some/location/of/local`
 func (path *Path) Dir() *Path {
-	return NewPath(path.supporter.Dir(path))
+	return path.derive(path.supporter.Dir(path))
 }
 
 type PathSupporter interface {
@@ -84,6 +113,8 @@ type PathSupporter interface {
 }
 
 type urlPathSupporter struct {
+	username string
+	password string
 }
 
 func (ups *urlPathSupporter) Base(urlPath *Path) string {
@@ -108,9 +139,13 @@ func (ups *urlPathSupporter) ExistFile(path *Path, context *Context) bool {
 	}
 	client := resty.New()
 	request := client.NewRequest()
+	if ups.username != "" {
+		request.SetBasicAuth(ups.username, ups.password)
+	}
 	response, err := request.Get(path.Path)
-	result := (err != nil || response.StatusCode() != 404)
-	logger.Debugf("Exist(%s): %v (%d)", path.url.String(), result, response.StatusCode())
+	status := response.StatusCode()
+	result := (err != nil || (status != 404 && status != 401 && status != 403))
+	logger.Debugf("Exist(%s): %v (%d)", path.url.String(), result, status)
 	return result
 }
 
@@ -119,17 +154,39 @@ func (ups *urlPathSupporter) Open(path *Path, context *Context) (io.ReadCloser,
 		return nil, fmt.Errorf("network access denied")
 	}
 	logger.Debugf("Open(%s)", path.url.String())
-	resp, err := http.Get(path.Path)
+	request, err := http.NewRequest(http.MethodGet, path.Path, nil)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode == 404 {
+	if ups.username != "" {
+		request.SetBasicAuth(ups.username, ups.password)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
 		defer resp.Body.Close()
-		return nil, fmt.Errorf("%s: 404 not found", path.Path)
+		return nil, &HTTPStatusError{URL: path.Path, StatusCode: resp.StatusCode}
 	}
 	return resp.Body, nil
 }
 
+// HTTPStatusError is the error returned from Path.Open, when the server responds an error status.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (err *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", err.URL, err.StatusCode, strings.ToLower(http.StatusText(err.StatusCode)))
+}
+
+// IsNotFound returns true if the status code of the receiver error is 404 (Not Found) or 410 (Gone).
+func (err *HTTPStatusError) IsNotFound() bool {
+	return err.StatusCode == http.StatusNotFound || err.StatusCode == http.StatusGone
+}
+
 type localFilePathSupporter struct {
 }
 
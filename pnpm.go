@@ -0,0 +1,189 @@
+package purplecat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// pnpmParser is the instance of Parser for parsing pnpm-lock.yaml of pnpm.
+type pnpmParser struct {
+	context *Context
+}
+
+// pnpmLockFile represents pnpm-lock.yaml.
+// The dependencies of the root project are in the top level (lockfileVersion 5 and 6), or in the importer `.` (6 and later).
+// The dependencies of the packages are in packages (5 and 6), or in snapshots (9).
+type pnpmLockFile struct {
+	pnpmImporter `yaml:",inline"`
+	Importers    map[string]*pnpmImporter `yaml:"importers"`
+	Packages     map[string]*pnpmPackage  `yaml:"packages"`
+	Snapshots    map[string]*pnpmPackage  `yaml:"snapshots"`
+}
+
+// pnpmImporter is the project in the workspace, the values of the dependencies are the version strings (lockfileVersion 5),
+// or the objects with the specifier and the version (6 and later).
+type pnpmImporter struct {
+	Dependencies         map[string]interface{} `yaml:"dependencies"`
+	DevDependencies      map[string]interface{} `yaml:"devDependencies"`
+	OptionalDependencies map[string]interface{} `yaml:"optionalDependencies"`
+}
+
+type pnpmPackage struct {
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// IsTarget returns true if the project located on the given path is pnpm project.
+func (pp *pnpmParser) IsTarget(path *Path, context *Context) bool {
+	if path.Base() == "pnpm-lock.yaml" {
+		return path.Exists(context)
+	}
+	return path.Join("pnpm-lock.yaml").Exists(context)
+}
+
+// Parse parses the given path as pnpm-lock.yaml and returns the instance of Project.
+func (pp *pnpmParser) Parse(path *Path) (*Project, error) {
+	if path.Base() != "pnpm-lock.yaml" {
+		path = path.Join("pnpm-lock.yaml")
+	}
+	if pp.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", pp.context.Depth, 0)
+	}
+	logger.Infof("parsePnpmLock(%s)", path.Path)
+	content, err := readText(path, pp.context)
+	if err != nil {
+		return nil, err
+	}
+	lockFile := &pnpmLockFile{}
+	if err := yaml.Unmarshal([]byte(content), lockFile); err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	tree, root, err := newLockedNpmTree(path.Dir(), pp.context)
+	if err != nil {
+		return nil, err
+	}
+	resolver := newPnpmResolver(lockFile)
+	importer := &lockFile.pnpmImporter
+	if rootImporter, ok := lockFile.Importers["."]; ok {
+		importer = rootImporter
+	}
+	root.dependencies = resolver.dependencies(importer.references())
+	return tree.constructProject(root, 0)
+}
+
+// references returns the dependencies of the importer, the keys are the names, and the values are the references (versions).
+func (importer *pnpmImporter) references() map[string]string {
+	references := map[string]string{}
+	for _, deps := range []map[string]interface{}{importer.DevDependencies, importer.OptionalDependencies, importer.Dependencies} {
+		for name, value := range deps {
+			if version, ok := value.(string); ok {
+				references[name] = version
+			} else if object, ok := value.(map[interface{}]interface{}); ok {
+				version, _ := object["version"].(string)
+				references[name] = version
+			}
+		}
+	}
+	return references
+}
+
+type pnpmResolver struct {
+	snapshots map[string]*pnpmPackage
+	packages  map[string]*npmPackage
+}
+
+// newPnpmResolver normalizes the keys of the packages into `name@version(peers)` form.
+// The keys are `/name/version_peers` in lockfileVersion 5, `/name@version(peers)` in 6, and `name@version(peers)` in 9.
+func newPnpmResolver(lockFile *pnpmLockFile) *pnpmResolver {
+	snapshots := lockFile.Snapshots
+	if len(snapshots) == 0 {
+		snapshots = lockFile.Packages
+	}
+	resolver := &pnpmResolver{snapshots: map[string]*pnpmPackage{}, packages: map[string]*npmPackage{}}
+	for key, snapshot := range snapshots {
+		if snapshot == nil {
+			snapshot = &pnpmPackage{}
+		}
+		resolver.snapshots[normalizePnpmKey(key)] = snapshot
+	}
+	return resolver
+}
+
+func normalizePnpmKey(key string) string {
+	key = strings.TrimPrefix(key, "/")
+	start := 0
+	if strings.HasPrefix(key, "@") {
+		start = strings.Index(key, "/") + 1
+	}
+	end := strings.IndexAny(key[start:], "/@")
+	if end < 0 || key[start+end] != '/' {
+		return key
+	}
+	return key[:start+end] + "@" + key[start+end+1:]
+}
+
+// pnpmKeyOf returns the normalized key of the given dependency.
+// The reference is the version (with the peers), `link:` to the local directory, or the key of the aliased package.
+func pnpmKeyOf(name, reference string) (string, bool) {
+	switch {
+	case strings.HasPrefix(reference, "link:"), strings.HasPrefix(reference, "file:"), reference == "":
+		return "", false
+	case strings.HasPrefix(reference, "/"):
+		return normalizePnpmKey(reference), true
+	case strings.Contains(reference[1:], "@") && !strings.ContainsAny(reference[:1], "0123456789"):
+		return reference, true
+	}
+	return name + "@" + reference, true
+}
+
+// splitPnpmKey splits the normalized key into the name and the version without the peers.
+func splitPnpmKey(key string) (string, string) {
+	name := yarnDescriptorName(key)
+	version := strings.TrimPrefix(key, name+"@")
+	if index := strings.IndexAny(version, "(_"); index >= 0 {
+		version = version[:index]
+	}
+	return name, version
+}
+
+func (resolver *pnpmResolver) dependencies(references map[string]string) []*npmPackage {
+	dependencies := []*npmPackage{}
+	for _, name := range sortedNpmNames(references) {
+		key, ok := pnpmKeyOf(name, references[name])
+		if !ok {
+			continue
+		}
+		if pkg, ok := resolver.packageOf(key); ok {
+			dependencies = append(dependencies, pkg)
+		} else {
+			logger.Debugf("%s: not found in pnpm-lock.yaml", key)
+		}
+	}
+	return dependencies
+}
+
+func (resolver *pnpmResolver) packageOf(key string) (*npmPackage, bool) {
+	if pkg, ok := resolver.packages[key]; ok {
+		return pkg, true
+	}
+	snapshot, ok := resolver.snapshots[key]
+	if !ok {
+		return nil, false
+	}
+	name, version := splitPnpmKey(key)
+	// pnpm installs the packages into node_modules/.pnpm/<name>@<version>/node_modules/<name>.
+	dir := fmt.Sprintf("node_modules/.pnpm/%s@%s/node_modules/%s", strings.ReplaceAll(name, "/", "+"), version, name)
+	pkg := &npmPackage{name: name, version: version, dir: dir}
+	resolver.packages[key] = pkg
+	references := map[string]string{}
+	for _, deps := range []map[string]string{snapshot.OptionalDependencies, snapshot.Dependencies} {
+		for name, reference := range deps {
+			references[name] = reference
+		}
+	}
+	pkg.dependencies = resolver.dependencies(references)
+	return pkg, true
+}
@@ -0,0 +1,57 @@
+package purplecat
+
+import "testing"
+
+func TestParsePnpm(t *testing.T) {
+	testdata := []struct {
+		path         string
+		wontName     string
+		wontLibaDeps []string
+	}{
+		{"testdata/pnpmproject", "pnpmproject4test@1.0.0", []string{"libc@0.1.5"}},
+		{"testdata/pnpmv9project/pnpm-lock.yaml", "pnpmv9project4test@1.0.0", []string{"libc@0.1.5", "libd@1.0.0"}},
+	}
+	for _, td := range testdata {
+		parser := &pnpmParser{context: NewContext(true, "json", 2)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		validateResolvedTree(t, tree, td.wontName, []string{"MIT"}, []string{"@scope/libb@2.1.3", "liba@1.2.0"})
+		if len(tree.Dependencies()) != 2 {
+			continue
+		}
+		validateResolvedTree(t, tree.Dependencies()[0], "@scope/libb@2.1.3", nil, []string{"libc@0.2.1"})
+		validateResolvedTree(t, tree.Dependencies()[1], "liba@1.2.0", nil, td.wontLibaDeps)
+	}
+}
+
+func TestParsePnpmInstalledLicense(t *testing.T) {
+	parser := &pnpmParser{context: NewContext(true, "json", 1)}
+	tree, err := parser.Parse(NewPath("testdata/pnpmv9project"))
+	if err != nil {
+		t.Errorf("testdata/pnpmv9project: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree.Dependencies()[1], "liba@1.2.0", []string{"ISC"}, []string{"libc@0.1.5", "libd@1.0.0"})
+}
+
+func TestNormalizePnpmKey(t *testing.T) {
+	testdata := []struct {
+		key  string
+		wont string
+	}{
+		{"/lodash/4.17.20", "lodash@4.17.20"},
+		{"/@babel/core/7.12.0", "@babel/core@7.12.0"},
+		{"/react-dom/17.0.1_react@17.0.1", "react-dom@17.0.1_react@17.0.1"},
+		{"/string_decoder@1.3.0", "string_decoder@1.3.0"},
+		{"/@scope/my_pkg@1.0.0(@types/node@14.0.0)", "@scope/my_pkg@1.0.0(@types/node@14.0.0)"},
+		{"lodash@4.17.20", "lodash@4.17.20"},
+	}
+	for _, td := range testdata {
+		if got := normalizePnpmKey(td.key); got != td.wont {
+			t.Errorf("normalizePnpmKey(%s) did not match, wont %s, got %s", td.key, td.wont, got)
+		}
+	}
+}
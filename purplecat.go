@@ -23,7 +23,29 @@ type Project struct {
 	PName       string     `json:"name"`
 	LicenseList []*License `json:"licenses"`
 	Deps        []string   `json:"dependencies"`
-	context     CacheDB    `json:"-"`
+	// DevDeps is the dependencies only for the development of the project (e.g., require-dev of Composer).
+	DevDeps []string `json:"dev-dependencies,omitempty"`
+	// OmittedDeps is the dependencies omitted by the version mediation (e.g., the nearest-wins strategy of Maven).
+	// Their reasons are shown in the attributes.
+	OmittedDeps []string `json:"omitted-dependencies,omitempty"`
+	// UnreachedDeps is the dependencies listed in the lock files (e.g., go.sum), but not reached in the dependency graph.
+	// Their requirers are unknown, since the go.mod (or the like) of the requirers were not available.
+	UnreachedDeps []string `json:"unreached-dependencies,omitempty"`
+	// ModuleNames is the names of the modules aggregated by the project (e.g., `<modules>` of Maven).
+	ModuleNames []string `json:"modules,omitempty"`
+	// Attributes is the attributes of the dependency edges (e.g., the scope of Maven), keyed by the names of the dependencies.
+	Attributes map[string]*DependencyAttribute `json:"attributes,omitempty"`
+	context    CacheDB                         `json:"-"`
+}
+
+// DependencyAttribute shows the attributes of the dependency edge from the project to its dependency.
+type DependencyAttribute struct {
+	Scope    string `json:"scope,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	// Omitted is the reason for omitting the dependency, e.g., `omitted for conflict with 1.3.0`.
+	Omitted string `json:"omitted,omitempty"`
+	// Internal shows the dependency is the module of the same multi-module project, which is not looked up in the repositories.
+	Internal bool `json:"internal,omitempty"`
 }
 
 // NewProject creates an instance of Project.
@@ -45,8 +67,41 @@ func (project *Project) Licenses() Licenses {
 
 // Dependencies returns the dependency list of the receiver project.
 func (project *Project) Dependencies() Projects {
+	return project.findProjects(project.Deps)
+}
+
+// DevDependencies returns the development dependency list of the receiver project.
+func (project *Project) DevDependencies() Projects {
+	return project.findProjects(project.DevDeps)
+}
+
+// UnreachedDependencies returns the dependencies listed in the lock files, but not reached in the dependency graph.
+func (project *Project) UnreachedDependencies() Projects {
+	return project.findProjects(project.UnreachedDeps)
+}
+
+// Modules returns the module list of the receiver project.
+func (project *Project) Modules() Projects {
+	return project.findProjects(project.ModuleNames)
+}
+
+// OmittedDependencies returns the dependencies omitted by the version mediation, without their dependencies.
+// The licenses of the dependencies not found in the cache are empty.
+func (project *Project) OmittedDependencies() Projects {
+	projects := []*Project{}
+	for _, name := range project.OmittedDeps {
+		omitted := &Project{PName: name, LicenseList: Licenses{}, Deps: []string{}, context: project.context}
+		if found, ok := project.context.Find(name); ok {
+			omitted.LicenseList = found.LicenseList
+		}
+		projects = append(projects, omitted)
+	}
+	return projects
+}
+
+func (project *Project) findProjects(names []string) Projects {
 	projects := []*Project{}
-	for _, dep := range project.Deps {
+	for _, dep := range names {
 		depProject, ok := project.context.Find(dep)
 		if ok {
 			projects = append(projects, depProject)
@@ -55,6 +110,22 @@ func (project *Project) Dependencies() Projects {
 	return projects
 }
 
+// Attribute returns the attribute of the dependency edge to the given name, or nil if the edge has no attributes.
+func (project *Project) Attribute(name string) *DependencyAttribute {
+	if project.Attributes == nil {
+		return nil
+	}
+	return project.Attributes[name]
+}
+
+// SetAttribute sets the attribute of the dependency edge to the given name.
+func (project *Project) SetAttribute(name string, attribute *DependencyAttribute) {
+	if project.Attributes == nil {
+		project.Attributes = map[string]*DependencyAttribute{}
+	}
+	project.Attributes[name] = attribute
+}
+
 // AddDependency adds the given project as the dependency for the receiver project.
 func (project *Project) AddDependency(p *Project) {
 	if p == nil {
@@ -81,7 +152,20 @@ type Context struct {
 	DenyNetworkAccess bool
 	Format            string
 	Depth             int
-	Cache             CacheDB
+	// Scopes is the scopes of the dependencies to be collected (e.g., compile and runtime of Maven). Empty means all scopes.
+	// The scopes are applied even to the cached artifacts, since the cache holds no dependencies of the Maven artifacts.
+	Scopes []string
+	// Profiles is the ids of the Maven profiles to be activated, the ids prefixed with `!` or `-` are deactivated.
+	Profiles []string
+	// MergeModules shows the multi-module project is reported as one project with the third-party dependencies of all modules.
+	MergeModules bool
+	// Repositories is the Maven repositories in the form of `[id::]url`, which are looked up before the other repositories.
+	Repositories []string
+	// SitePackages is the site-packages directories of Python, which are looked up before $PURPLECAT_SITE_PACKAGES and the virtual environments.
+	SitePackages []string
+	Cache        CacheDB
+	// mavenSettings is the user settings of Maven, read at the first lookup of the repositories.
+	mavenSettings *mavenSettings
 }
 
 // NewContext creates the instance of Context by given arguments.
@@ -115,6 +199,19 @@ func (context *Context) RegisterCache(project *Project) bool {
 	return context.Cache.Register(project)
 }
 
+// AcceptScope checks the dependencies of the given scope are collected in the current context.
+func (context *Context) AcceptScope(scope string) bool {
+	if len(context.Scopes) == 0 {
+		return true
+	}
+	for _, accepted := range context.Scopes {
+		if strings.EqualFold(accepted, scope) {
+			return true
+		}
+	}
+	return false
+}
+
 // Allow checks given ActType is allowed in the current context.
 func (context *Context) Allow(actType ActionType) bool {
 	if actType == NetworkAccessFlag {
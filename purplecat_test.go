@@ -0,0 +1,64 @@
+package purplecat
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// setEnv sets the given environment variables, and returns the function restoring their previous values.
+func setEnv(values map[string]string) func() {
+	previous := map[string]*string{}
+	for key, value := range values {
+		previous[key] = nil
+		if old, ok := os.LookupEnv(key); ok {
+			previous[key] = &old
+		}
+		os.Setenv(key, value)
+	}
+	return func() {
+		for key, old := range previous {
+			if old == nil {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, *old)
+			}
+		}
+	}
+}
+
+// validateResolvedTree validates the name, the SPDX IDs of the licenses (skipped if nil), and the dependencies of the given tree.
+func validateResolvedTree(t *testing.T, tree *Project, wontName string, wontSpdxIDs, wontDeps []string) {
+	if tree.Name() != wontName {
+		t.Errorf("project name did not match, wont %s, got %s", wontName, tree.Name())
+	}
+	if strings.Join(tree.Deps, ",") != strings.Join(wontDeps, ",") {
+		t.Errorf("%s: dependencies did not match, wont %v, got %v", wontName, wontDeps, tree.Deps)
+	}
+	if wontSpdxIDs == nil {
+		return
+	}
+	spdxIDs := []string{}
+	for _, license := range tree.Licenses() {
+		spdxIDs = append(spdxIDs, license.SpdxID)
+	}
+	if strings.Join(spdxIDs, ",") != strings.Join(wontSpdxIDs, ",") {
+		t.Errorf("%s: licenses did not match, wont %v, got %v", wontName, wontSpdxIDs, spdxIDs)
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	defer setEnv(map[string]string{"PURPLECAT_TEST_ENV1": "before"})()
+	os.Unsetenv("PURPLECAT_TEST_ENV2")
+	reset := setEnv(map[string]string{"PURPLECAT_TEST_ENV1": "after", "PURPLECAT_TEST_ENV2": "set"})
+	if value := os.Getenv("PURPLECAT_TEST_ENV1"); value != "after" {
+		t.Errorf("PURPLECAT_TEST_ENV1 did not match, wont after, got %s", value)
+	}
+	reset()
+	if value := os.Getenv("PURPLECAT_TEST_ENV1"); value != "before" {
+		t.Errorf("PURPLECAT_TEST_ENV1 wont be restored, got %s", value)
+	}
+	if _, ok := os.LookupEnv("PURPLECAT_TEST_ENV2"); ok {
+		t.Errorf("PURPLECAT_TEST_ENV2 wont be unset")
+	}
+}
@@ -0,0 +1,112 @@
+package purplecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// defaultPyPIURL is the base url of the JSON API of PyPI.
+const defaultPyPIURL = "https://pypi.org/pypi"
+
+// PyPIEnvName is the environment name for the base url of PyPI-JSON-compatible index.
+const PyPIEnvName = "PURPLECAT_PYPI_URL"
+
+// pypiProject represents the response of `$PYPI/<name>/json` and `$PYPI/<name>/<version>/json`.
+type pypiProject struct {
+	Info     *pypiInfo                 `json:"info"`
+	Releases map[string][]*pypiRelease `json:"releases"`
+}
+
+type pypiInfo struct {
+	Name              string   `json:"name"`
+	Version           string   `json:"version"`
+	License           string   `json:"license"`
+	LicenseExpression string   `json:"license_expression"`
+	Classifiers       []string `json:"classifiers"`
+	RequiresDist      []string `json:"requires_dist"`
+}
+
+type pypiRelease struct {
+	Yanked bool `json:"yanked"`
+}
+
+func pypiURL() string {
+	if value := os.Getenv(PyPIEnvName); value != "" {
+		return strings.TrimSuffix(value, "/")
+	}
+	return defaultPyPIURL
+}
+
+func readPyPIProject(location string, context *Context) (*pypiProject, error) {
+	if !context.Allow(NetworkAccessFlag) {
+		return nil, fmt.Errorf("%s: network access denied", location)
+	}
+	path := NewPath(location)
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	project := &pypiProject{}
+	if err := json.Unmarshal([]byte(content), project); err != nil {
+		return nil, fmt.Errorf("%s: %s", location, err.Error())
+	}
+	if project.Info == nil {
+		return nil, fmt.Errorf("%s: info not found", location)
+	}
+	return project, nil
+}
+
+// findPythonMetadataViaPyPI returns the metadata of the given version of the distribution from the index.
+func findPythonMetadataViaPyPI(name, version string, context *Context) (*pythonMetadata, error) {
+	logger.Infof("findPythonMetadataViaPyPI(%s==%s)", name, version)
+	project, err := readPyPIProject(fmt.Sprintf("%s/%s/%s/json", pypiURL(), normalizePythonName(name), version), context)
+	if err != nil {
+		return nil, err
+	}
+	info := project.Info
+	return &pythonMetadata{name: info.Name, version: info.Version, license: info.License, licenseExpression: info.LicenseExpression,
+		classifiers: info.Classifiers, requiresDist: info.RequiresDist}, nil
+}
+
+// resolvePythonVersionViaPyPI returns the latest version satisfying the given specifier in the releases of the distribution.
+// The yanked releases are ignored, and the pre-releases are ignored unless the specifier mentions them.
+func resolvePythonVersionViaPyPI(requirement *pythonRequirement, context *Context) (string, error) {
+	logger.Infof("resolvePythonVersionViaPyPI(%s%s)", requirement.name, requirement.specifier)
+	project, err := readPyPIProject(fmt.Sprintf("%s/%s/json", pypiURL(), normalizePythonName(requirement.name)), context)
+	if err != nil {
+		return "", err
+	}
+	allowPrerelease := allowsPythonPrerelease(requirement.specifier)
+	latest := ""
+	for version, files := range project.Releases {
+		if isYankedPythonRelease(files) || !satisfiesPythonSpecifier(version, requirement.specifier) {
+			continue
+		}
+		if parsed, ok := parsePythonVersion(version); !ok || (parsed.isPrerelease() && !allowPrerelease) {
+			continue
+		}
+		if latest == "" || comparePythonVersion(version, latest) > 0 {
+			latest = version
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("%s%s: no versions satisfying the specifier", requirement.name, requirement.specifier)
+	}
+	return latest, nil
+}
+
+func isYankedPythonRelease(files []*pypiRelease) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, file := range files {
+		if !file.Yanked {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,245 @@
+package purplecat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// pythonParser is the instance of Parser for parsing the Python projects
+// (requirements.txt, pyproject.toml, Pipfile.lock, and poetry.lock).
+type pythonParser struct {
+	context *Context
+}
+
+// pythonTargetFileNames is the project files of Python in the order of precedence.
+var pythonTargetFileNames = []string{"poetry.lock", "Pipfile.lock", "requirements.txt", "pyproject.toml"}
+
+// pythonPackage represents the distribution in the dependency graph.
+type pythonPackage struct {
+	name    string
+	version string
+	// requires is the dependencies taken from the lockfile; nil shows the dependencies are read from the metadata.
+	requires []*pythonRequirement
+}
+
+func (pkg *pythonPackage) Name() string {
+	if pkg.version == "" {
+		return pkg.name
+	}
+	return pkg.name + "==" + pkg.version
+}
+
+func isPythonTargetFile(name string) bool {
+	for _, fileName := range pythonTargetFileNames {
+		if name == fileName {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTarget returns true if the project located on the given path is Python project.
+func (pp *pythonParser) IsTarget(path *Path, context *Context) bool {
+	if isPythonTargetFile(path.Base()) {
+		return path.Exists(context)
+	}
+	_, ok := findPythonTargetFile(path, context)
+	return ok
+}
+
+func findPythonTargetFile(dir *Path, context *Context) (*Path, bool) {
+	for _, name := range pythonTargetFileNames {
+		if path := dir.Join(name); path.Exists(context) {
+			return path, true
+		}
+	}
+	return nil, false
+}
+
+// Parse parses the given path as the project file of Python and returns the instance of Project.
+// The versions of the dependencies are taken from the lockfile, the installed distributions, and PyPI in this order.
+func (pp *pythonParser) Parse(path *Path) (*Project, error) {
+	dir, target := path, path
+	if isPythonTargetFile(path.Base()) {
+		dir = path.Dir()
+	} else if found, ok := findPythonTargetFile(path, pp.context); ok {
+		target = found
+	} else {
+		return nil, fmt.Errorf("%s: not Python project", path.Path)
+	}
+	if pp.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", pp.context.Depth, 0)
+	}
+	logger.Infof("parsePythonProject(%s)", target.Path)
+	projectFile, err := readPythonProjectFile(target, dir, pp.context)
+	if err != nil {
+		return nil, err
+	}
+	root := &pythonPackage{name: projectFile.name, version: projectFile.version, requires: projectFile.requires}
+	if root.name == "" {
+		root.name = dir.Base()
+	}
+	licenses := projectFile.licenses
+	if len(licenses) == 0 {
+		licenses = findLicensesInDir(dir, pp.context)
+	}
+	resolver := newPythonResolver(pp.context, projectFile.locked, readInstalledPythonDistributions(findSitePackages(dir, pp.context), pp.context))
+	return resolver.constructProject(root, licenses, 0)
+}
+
+// readPythonProjectFile reads the given project file. The name, the version, and the licenses of the project are
+// read from pyproject.toml in the same directory, and its dependencies are used for pyproject.toml and poetry.lock.
+func readPythonProjectFile(target, dir *Path, context *Context) (*pythonProjectFile, error) {
+	projectFile := newPythonProjectFile()
+	if pyproject := dir.Join("pyproject.toml"); pyproject.Exists(context) {
+		if err := readPyprojectToml(pyproject, context, projectFile); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	switch target.Base() {
+	case "poetry.lock":
+		err = readPoetryLock(target, context, projectFile)
+		if len(projectFile.requires) == 0 {
+			projectFile.requires = lockedPythonRequirements(projectFile.locked)
+		}
+	case "Pipfile.lock":
+		err = readPipfileLock(target, context, projectFile)
+	case "requirements.txt":
+		projectFile.requires = []*pythonRequirement{}
+		err = readRequirementsTxt(target, context, projectFile, map[string]bool{})
+	}
+	return projectFile, err
+}
+
+func lockedPythonRequirements(locked map[string]*pythonPackage) []*pythonRequirement {
+	keys := []string{}
+	for key := range locked {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	requires := []*pythonRequirement{}
+	for _, key := range keys {
+		requires = append(requires, &pythonRequirement{name: locked[key].name})
+	}
+	return requires
+}
+
+type pythonResolver struct {
+	context *Context
+	// locked and installed are keyed by the normalized names.
+	locked    map[string]*pythonPackage
+	installed map[string]*pythonMetadata
+	// packages and metadata are keyed by the names with the versions (pythonPackage.Name).
+	packages map[string]*pythonPackage
+	metadata map[string]*pythonMetadata
+}
+
+func newPythonResolver(context *Context, locked map[string]*pythonPackage, installed map[string]*pythonMetadata) *pythonResolver {
+	return &pythonResolver{context: context, locked: locked, installed: installed, packages: map[string]*pythonPackage{}, metadata: map[string]*pythonMetadata{}}
+}
+
+// resolve finds the version of the given requirement from the lockfile, the installed distributions, and PyPI in this order.
+// PyPI is not accessed unless remote is true.
+func (resolver *pythonResolver) resolve(requirement *pythonRequirement, remote bool) (*pythonPackage, bool) {
+	key := normalizePythonName(requirement.name)
+	if pkg, ok := resolver.locked[key]; ok {
+		return resolver.packageOf(pkg), true
+	}
+	if metadata, ok := resolver.installed[key]; ok && satisfiesPythonSpecifier(metadata.version, requirement.specifier) {
+		return resolver.packageOf(&pythonPackage{name: metadata.name, version: metadata.version}), true
+	}
+	if !remote || !resolver.context.Allow(NetworkAccessFlag) {
+		return nil, false
+	}
+	version, err := resolvePythonVersionViaPyPI(requirement, resolver.context)
+	if err != nil {
+		logger.Debugf("%s", err.Error())
+		return nil, false
+	}
+	return resolver.packageOf(&pythonPackage{name: requirement.name, version: version}), true
+}
+
+func (resolver *pythonResolver) packageOf(pkg *pythonPackage) *pythonPackage {
+	if found, ok := resolver.packages[pkg.Name()]; ok {
+		return found
+	}
+	resolver.packages[pkg.Name()] = pkg
+	return pkg
+}
+
+// findMetadata returns the metadata of the given package from the installed distributions, or PyPI if remote is true.
+func (resolver *pythonResolver) findMetadata(pkg *pythonPackage, remote bool) (*pythonMetadata, bool) {
+	if metadata, ok := resolver.metadata[pkg.Name()]; ok {
+		return metadata, true
+	}
+	metadata, ok := resolver.installed[normalizePythonName(pkg.name)]
+	if !ok || comparePythonVersion(metadata.version, pkg.version) != 0 {
+		if !remote || !resolver.context.Allow(NetworkAccessFlag) {
+			return nil, false
+		}
+		var err error
+		if metadata, err = findPythonMetadataViaPyPI(pkg.name, pkg.version, resolver.context); err != nil {
+			logger.Debugf("%s", err.Error())
+			return nil, false
+		}
+	}
+	resolver.metadata[pkg.Name()] = metadata
+	return metadata, true
+}
+
+func (resolver *pythonResolver) dependencies(pkg *pythonPackage, remote bool) []*pythonPackage {
+	requires := pkg.requires
+	if requires == nil {
+		requires = []*pythonRequirement{}
+		if metadata, ok := resolver.findMetadata(pkg, remote); ok {
+			requires = metadata.requirements()
+		}
+	}
+	dependencies := []*pythonPackage{}
+	for _, requirement := range requires {
+		if requirement.isExtra() {
+			continue
+		}
+		if dependency, ok := resolver.resolve(requirement, remote); ok {
+			dependencies = append(dependencies, dependency)
+		} else {
+			logger.Debugf("%s: dependency %s%s not resolved", pkg.Name(), requirement.name, requirement.specifier)
+		}
+	}
+	return dependencies
+}
+
+func (resolver *pythonResolver) findLicenses(pkg *pythonPackage) Licenses {
+	if metadata, ok := resolver.findMetadata(pkg, true); ok {
+		return metadata.licenseList(resolver.context)
+	}
+	return Licenses{}
+}
+
+// constructProject constructs the project of the given package, and its dependencies recursively.
+// The licenses of the package are read from its metadata if the given licenses is nil.
+func (resolver *pythonResolver) constructProject(pkg *pythonPackage, licenses Licenses, currentDepth int) (*Project, error) {
+	if resolver.context.Depth < currentDepth {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", resolver.context.Depth, currentDepth)
+	}
+	logger.Infof("constructPythonProject(%s, %d)", pkg.Name(), currentDepth)
+	if licenses == nil {
+		licenses = resolver.findLicenses(pkg)
+	}
+	project := resolver.context.NewProject(pkg.Name(), licenses)
+	// the versions of the dependencies at the depth limit are resolved without accessing PyPI.
+	dependencies := resolver.dependencies(pkg, currentDepth < resolver.context.Depth)
+	for _, dependency := range dependencies {
+		project.Deps = append(project.Deps, dependency.Name())
+	}
+	for _, dependency := range dependencies {
+		if _, ok := resolver.context.SearchCache(dependency.Name()); ok {
+			continue
+		}
+		resolver.constructProject(dependency, nil, currentDepth+1)
+	}
+	return project, nil
+}
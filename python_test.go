@@ -0,0 +1,86 @@
+package purplecat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePython(t *testing.T) {
+	defer setEnv(map[string]string{SitePackagesEnvName: "testdata/pythonsitepackages"})()
+	testdata := []struct {
+		path        string
+		wontName    string
+		wontSpdxIDs []string
+		wontDeps    []string
+	}{
+		{"testdata/pythonproject", "pythonproject", []string{"MIT"}, []string{"six==1.15.0", "requests==2.25.1", "click==7.1.2"}},
+		{"testdata/pythonproject/requirements.txt", "pythonproject", []string{"MIT"}, []string{"six==1.15.0", "requests==2.25.1", "click==7.1.2"}},
+		{"testdata/pyprojectproject", "pyproject4test==1.0.0", []string{"MIT"}, []string{"requests==2.25.1", "six==1.15.0"}},
+		{"testdata/poetryproject", "poetry4test==0.1.0", []string{"Apache-2.0"}, []string{"requests==2.25.1", "six==1.15.0"}},
+		{"testdata/pipenvproject/Pipfile.lock", "pipenvproject", []string{}, []string{"requests==2.25.1", "six==1.15.0"}},
+	}
+	for _, td := range testdata {
+		parser := &pythonParser{context: NewContext(true, "json", 2)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		validateResolvedTree(t, tree, td.wontName, td.wontSpdxIDs, td.wontDeps)
+	}
+}
+
+func TestParsePythonInstalledLicenses(t *testing.T) {
+	defer setEnv(map[string]string{SitePackagesEnvName: "testdata/pythonsitepackages"})()
+	parser := &pythonParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/poetryproject/poetry.lock"))
+	if err != nil {
+		t.Errorf("testdata/poetryproject: parse failed: %s", err.Error())
+		return
+	}
+	requests, six := tree.Dependencies()[0], tree.Dependencies()[1]
+	validateResolvedTree(t, requests, "requests==2.25.1", []string{"Apache-2.0"}, []string{"certifi==2020.12.5", "chardet==4.0.0", "idna==2.10"})
+	validateResolvedTree(t, six, "six==1.15.0", []string{"MIT"}, []string{})
+	certifi, chardet, idna := requests.Dependencies()[0], requests.Dependencies()[1], requests.Dependencies()[2]
+	validateResolvedTree(t, certifi, "certifi==2020.12.5", []string{"MPL-2.0"}, []string{})
+	validateResolvedTree(t, chardet, "chardet==4.0.0", []string{"LGPL-2.1-only"}, []string{})
+	validateResolvedTree(t, idna, "idna==2.10", []string{""}, []string{})
+}
+
+func TestParsePythonViaPyPI(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata/pypi")))
+	defer server.Close()
+	defer setEnv(map[string]string{PyPIEnvName: server.URL})()
+
+	parser := &pythonParser{context: NewContext(false, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/pypiproject"))
+	if err != nil {
+		t.Errorf("testdata/pypiproject: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree, "pypiproject", nil, []string{"itsdangerous==2.0.1", "click==7.1.2"})
+	itsdangerous, click := tree.Dependencies()[0], tree.Dependencies()[1]
+	validateResolvedTree(t, itsdangerous, "itsdangerous==2.0.1", []string{""}, []string{"click==7.1.2"})
+	if itsdangerous.Licenses()[0].Name != "BSD License" {
+		t.Errorf("itsdangerous==2.0.1: license name did not match, wont BSD License, got %s", itsdangerous.Licenses()[0].Name)
+	}
+	validateResolvedTree(t, click, "click==7.1.2", []string{"BSD-3-Clause"}, []string{})
+}
+
+func TestParsePythonOffline(t *testing.T) {
+	parser := &pythonParser{context: NewContext(true, "json", 2)}
+	tree, err := parser.Parse(NewPath("testdata/pypiproject/requirements.txt"))
+	if err != nil {
+		t.Errorf("testdata/pypiproject: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree, "pypiproject", nil, []string{})
+}
+
+func TestParsePythonOverDepth(t *testing.T) {
+	parser := &pythonParser{context: NewContext(true, "json", -1)}
+	if _, err := parser.Parse(NewPath("testdata/pythonproject")); err == nil {
+		t.Errorf("Parse with depth -1 wont error, but got nil")
+	}
+}
@@ -0,0 +1,323 @@
+package purplecat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// pythonRequirement represents the dependency specifier of PEP 508, e.g., `requests[security]>=2.8.1; python_version < "3.8"`.
+type pythonRequirement struct {
+	name      string
+	specifier string
+	marker    string
+}
+
+// pythonProjectFile is the result of reading the project files (requirements.txt, pyproject.toml, Pipfile.lock, and poetry.lock).
+type pythonProjectFile struct {
+	name     string
+	version  string
+	licenses Licenses
+	requires []*pythonRequirement
+	// locked is the packages pinned by the lockfile (or `==` in requirements.txt), keyed by the normalized names.
+	locked map[string]*pythonPackage
+}
+
+func newPythonProjectFile() *pythonProjectFile {
+	return &pythonProjectFile{licenses: Licenses{}, requires: []*pythonRequirement{}, locked: map[string]*pythonPackage{}}
+}
+
+var (
+	pythonRequirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+	pythonNameSeparators     = regexp.MustCompile(`[-_.]+`)
+	pythonExtraMarker        = regexp.MustCompile(`\bextra\s*==`)
+)
+
+// normalizePythonName normalizes the distribution name as PEP 503, e.g., `zope-interface` for `Zope.Interface`.
+func normalizePythonName(name string) string {
+	return pythonNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// parsePythonRequirement parses the given dependency specifier of PEP 508.
+// The direct references (`name @ url`) have no version specifier.
+func parsePythonRequirement(value string) (*pythonRequirement, bool) {
+	value = strings.TrimSpace(value)
+	marker := ""
+	if index := strings.Index(value, ";"); index >= 0 {
+		value, marker = strings.TrimSpace(value[:index]), strings.TrimSpace(value[index+1:])
+	}
+	match := pythonRequirementPattern.FindStringSubmatch(value)
+	if match == nil {
+		return nil, false
+	}
+	specifier := strings.TrimSpace(match[2])
+	if strings.HasPrefix(specifier, "@") {
+		specifier = ""
+	}
+	specifier = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(specifier, "("), ")"))
+	return &pythonRequirement{name: match[1], specifier: specifier, marker: marker}, true
+}
+
+// isExtra reports whether the requirement is required only by the extra (e.g., `extra == "socks"`).
+func (requirement *pythonRequirement) isExtra() bool {
+	return pythonExtraMarker.MatchString(requirement.marker)
+}
+
+// pinnedVersion returns the version if the requirement pins the version by `==` (or `===`).
+func (requirement *pythonRequirement) pinnedVersion() (string, bool) {
+	clauses := parsePythonSpecifier(requirement.specifier)
+	if len(clauses) != 1 || (clauses[0].operator != "==" && clauses[0].operator != "===") || strings.HasSuffix(clauses[0].version, ".*") {
+		return "", false
+	}
+	return clauses[0].version, true
+}
+
+// readRequirementsTxt reads requirements.txt, and the files included by `-r` (and `--requirement`) option.
+// The other options, the editable installs, and the local paths are ignored.
+func readRequirementsTxt(path *Path, context *Context, projectFile *pythonProjectFile, visited map[string]bool) error {
+	if visited[path.Path] {
+		return nil
+	}
+	visited[path.Path] = true
+	content, err := readText(path, context)
+	if err != nil {
+		return err
+	}
+	for _, line := range joinRequirementsLines(content) {
+		if included, ok := requirementsInclusion(line); ok {
+			if err := readRequirementsTxt(path.Dir().Join(included), context, projectFile, visited); err != nil {
+				return err
+			}
+			continue
+		}
+		// the per-requirement options (e.g., `--hash`) follow the requirement.
+		if index := strings.Index(line, " --"); index >= 0 {
+			line = strings.TrimSpace(line[:index])
+		}
+		if strings.HasPrefix(line, "-") || strings.HasPrefix(line, ".") || strings.HasPrefix(line, "/") || (strings.Contains(line, "://") && !strings.Contains(line, "@")) {
+			continue
+		}
+		requirement, ok := parsePythonRequirement(line)
+		if !ok {
+			continue
+		}
+		projectFile.requires = append(projectFile.requires, requirement)
+		if version, ok := requirement.pinnedVersion(); ok {
+			projectFile.locked[normalizePythonName(requirement.name)] = &pythonPackage{name: requirement.name, version: version}
+		}
+	}
+	return nil
+}
+
+// joinRequirementsLines returns the lines of requirements.txt without the comments, and the lines continued by `\`.
+func joinRequirementsLines(content string) []string {
+	lines := []string{}
+	current := ""
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, " #"); index >= 0 {
+			line = line[:index]
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			line = ""
+		}
+		if strings.HasSuffix(line, `\`) {
+			current = current + strings.TrimSuffix(line, `\`)
+			continue
+		}
+		if line = strings.TrimSpace(current + line); line != "" {
+			lines = append(lines, line)
+		}
+		current = ""
+	}
+	return lines
+}
+
+func requirementsInclusion(line string) (string, bool) {
+	for _, option := range []string{"-r", "--requirement"} {
+		if strings.HasPrefix(line, option+" ") || strings.HasPrefix(line, option+"=") {
+			return strings.TrimSpace(strings.TrimLeft(strings.TrimPrefix(line, option), "= ")), true
+		}
+	}
+	return "", false
+}
+
+// readPyprojectToml reads the project metadata of PEP 621 (`[project]`), or the one of Poetry (`[tool.poetry]`).
+func readPyprojectToml(path *Path, context *Context, projectFile *pythonProjectFile) error {
+	tree, err := loadToml(path, context)
+	if err != nil {
+		return err
+	}
+	if project, ok := tree.Get("project").(*toml.Tree); ok {
+		projectFile.name, _ = project.Get("name").(string)
+		projectFile.version, _ = project.Get("version").(string)
+		projectFile.licenses = pyprojectLicenses(project.Get("license"))
+		for _, item := range toStringSlice(project.Get("dependencies")) {
+			if requirement, ok := parsePythonRequirement(item); ok {
+				projectFile.requires = append(projectFile.requires, requirement)
+			}
+		}
+		return nil
+	}
+	if poetry, ok := tree.Get("tool.poetry").(*toml.Tree); ok {
+		projectFile.name, _ = poetry.Get("name").(string)
+		projectFile.version, _ = poetry.Get("version").(string)
+		projectFile.licenses = pyprojectLicenses(poetry.Get("license"))
+		projectFile.requires = append(projectFile.requires, poetryRequirements(poetry)...)
+	}
+	return nil
+}
+
+// poetryRequirements returns the dependencies, dev-dependencies, and the dependencies of the groups declared in `[tool.poetry]`.
+func poetryRequirements(poetry *toml.Tree) []*pythonRequirement {
+	tables := []interface{}{poetry.Get("dependencies"), poetry.Get("dev-dependencies")}
+	if groups, ok := poetry.Get("group").(*toml.Tree); ok {
+		for _, name := range sortedTomlKeys(groups) {
+			if group, ok := groups.GetPath([]string{name}).(*toml.Tree); ok {
+				tables = append(tables, group.Get("dependencies"))
+			}
+		}
+	}
+	requires := []*pythonRequirement{}
+	for _, table := range tables {
+		requires = append(requires, tomlRequirements(table)...)
+	}
+	return requires
+}
+
+// tomlRequirements returns the requirements from the table of Poetry and Pipenv, whose values are the version specifiers,
+// or the tables with the `version` key. The `python` requirement and the optional requirements are ignored.
+func tomlRequirements(value interface{}) []*pythonRequirement {
+	requires := []*pythonRequirement{}
+	table, ok := value.(*toml.Tree)
+	if !ok {
+		return requires
+	}
+	for _, name := range sortedTomlKeys(table) {
+		if strings.ToLower(name) == "python" {
+			continue
+		}
+		specifier := ""
+		switch item := table.GetPath([]string{name}).(type) {
+		case string:
+			specifier = item
+		case *toml.Tree:
+			if optional, _ := item.Get("optional").(bool); optional {
+				continue
+			}
+			specifier, _ = item.Get("version").(string)
+		}
+		requires = append(requires, &pythonRequirement{name: name, specifier: specifier})
+	}
+	return requires
+}
+
+func sortedTomlKeys(tree *toml.Tree) []string {
+	keys := tree.Keys()
+	sort.Strings(keys)
+	return keys
+}
+
+// pyprojectLicenses returns the licenses from the license field, which is the SPDX expression (PEP 639, and Poetry), or
+// the table with `text` or `file` key (PEP 621).
+func pyprojectLicenses(value interface{}) Licenses {
+	switch license := value.(type) {
+	case string:
+		return parseSpdxExpression(license)
+	case *toml.Tree:
+		if text, ok := license.Get("text").(string); ok {
+			return Licenses{pythonLicenseOf(text)}
+		}
+	}
+	return Licenses{}
+}
+
+func toStringSlice(value interface{}) []string {
+	items, _ := value.([]interface{})
+	strs := []string{}
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+func loadToml(path *Path, context *Context) (*toml.Tree, error) {
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := toml.Load(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	return tree, nil
+}
+
+// readPoetryLock reads the packages in poetry.lock, their dependencies are also taken from the lockfile.
+func readPoetryLock(path *Path, context *Context, projectFile *pythonProjectFile) error {
+	tree, err := loadToml(path, context)
+	if err != nil {
+		return err
+	}
+	packages, _ := tree.Get("package").([]*toml.Tree)
+	for _, item := range packages {
+		name, _ := item.Get("name").(string)
+		version, _ := item.Get("version").(string)
+		if name == "" {
+			continue
+		}
+		pkg := &pythonPackage{name: name, version: version, requires: tomlRequirements(item.Get("dependencies"))}
+		projectFile.locked[normalizePythonName(name)] = pkg
+	}
+	return nil
+}
+
+// pipfileLock represents Pipfile.lock, the packages in default and develop sections are pinned by `==`.
+type pipfileLock struct {
+	Default map[string]*pipfileLockEntry `json:"default"`
+	Develop map[string]*pipfileLockEntry `json:"develop"`
+}
+
+type pipfileLockEntry struct {
+	Version string `json:"version"`
+}
+
+// readPipfileLock reads the packages in Pipfile.lock. Since the lockfile has no dependency relations, the dependencies of
+// the project are read from Pipfile, or all of the packages in the lockfile if Pipfile does not exist.
+func readPipfileLock(path *Path, context *Context, projectFile *pythonProjectFile) error {
+	content, err := readText(path, context)
+	if err != nil {
+		return err
+	}
+	lock := &pipfileLock{}
+	if err := json.Unmarshal([]byte(content), lock); err != nil {
+		return fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	names := []string{}
+	for _, section := range []map[string]*pipfileLockEntry{lock.Default, lock.Develop} {
+		for name, entry := range section {
+			if entry == nil || entry.Version == "" {
+				continue
+			}
+			projectFile.locked[normalizePythonName(name)] = &pythonPackage{name: name, version: strings.TrimPrefix(entry.Version, "==")}
+			names = append(names, name)
+		}
+	}
+	if pipfile, err := loadToml(path.Dir().Join("Pipfile"), context); err == nil {
+		projectFile.requires = append(tomlRequirements(pipfile.Get("packages")), tomlRequirements(pipfile.Get("dev-packages"))...)
+		return nil
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		projectFile.requires = append(projectFile.requires, &pythonRequirement{name: name})
+	}
+	return nil
+}
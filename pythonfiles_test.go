@@ -0,0 +1,73 @@
+package purplecat
+
+import "testing"
+
+func TestParsePythonRequirement(t *testing.T) {
+	testdata := []struct {
+		value         string
+		successFlag   bool
+		wontName      string
+		wontSpecifier string
+		wontExtra     bool
+	}{
+		{"requests==2.25.1", true, "requests", "==2.25.1", false},
+		{"requests[security] >= 2.8.1, == 2.8.*", true, "requests", ">= 2.8.1, == 2.8.*", false},
+		{"idna (<3,>=2.5)", true, "idna", "<3,>=2.5", false},
+		{`PySocks (!=1.5.7,>=1.5.6) ; extra == 'socks'`, true, "PySocks", "!=1.5.7,>=1.5.6", true},
+		{`importlib-metadata; python_version < "3.8"`, true, "importlib-metadata", "", false},
+		{"pip @ https://github.com/pypa/pip/archive/1.3.1.zip", true, "pip", "", false},
+		{"", false, "", "", false},
+	}
+	for _, td := range testdata {
+		requirement, ok := parsePythonRequirement(td.value)
+		if ok != td.successFlag {
+			t.Errorf(`parsePythonRequirement("%s") wont success %v, got %v`, td.value, td.successFlag, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if requirement.name != td.wontName || requirement.specifier != td.wontSpecifier || requirement.isExtra() != td.wontExtra {
+			t.Errorf(`parsePythonRequirement("%s") did not match, wont (%s, %s, %v), got (%s, %s, %v)`, td.value,
+				td.wontName, td.wontSpecifier, td.wontExtra, requirement.name, requirement.specifier, requirement.isExtra())
+		}
+	}
+}
+
+func TestNormalizePythonName(t *testing.T) {
+	testdata := []struct {
+		name string
+		wont string
+	}{
+		{"requests", "requests"},
+		{"Zope.Interface", "zope-interface"},
+		{"typing_extensions", "typing-extensions"},
+		{"foo-_.bar", "foo-bar"},
+	}
+	for _, td := range testdata {
+		if got := normalizePythonName(td.name); got != td.wont {
+			t.Errorf("normalizePythonName(%s) did not match, wont %s, got %s", td.name, td.wont, got)
+		}
+	}
+}
+
+func TestReadRequirementsTxt(t *testing.T) {
+	projectFile := newPythonProjectFile()
+	if err := readRequirementsTxt(NewPath("testdata/pythonproject/requirements.txt"), NewContext(true, "json", 1), projectFile, map[string]bool{}); err != nil {
+		t.Errorf("readRequirementsTxt failed: %s", err.Error())
+		return
+	}
+	wontNames := []string{"six", "requests", "click"}
+	if len(projectFile.requires) != len(wontNames) {
+		t.Errorf("requirement count did not match, wont %d, got %d", len(wontNames), len(projectFile.requires))
+		return
+	}
+	for i, wont := range wontNames {
+		if projectFile.requires[i].name != wont {
+			t.Errorf("requirement[%d] did not match, wont %s, got %s", i, wont, projectFile.requires[i].name)
+		}
+	}
+	if len(projectFile.locked) != 2 || projectFile.locked["requests"].version != "2.25.1" {
+		t.Errorf("locked packages did not match, wont six and requests, got %v", projectFile.locked)
+	}
+}
@@ -0,0 +1,226 @@
+package purplecat
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// SitePackagesEnvName is the environment name for the site-packages directories of Python,
+// which is the list separated by os.PathListSeparator.
+const SitePackagesEnvName = "PURPLECAT_SITE_PACKAGES"
+
+// pythonMetadata represents the core metadata of the Python distribution,
+// which is read from METADATA (or PKG-INFO) of the installed distribution, or the JSON API of PyPI.
+type pythonMetadata struct {
+	name              string
+	version           string
+	license           string
+	licenseExpression string
+	classifiers       []string
+	requiresDist      []string
+	// dir is the location of the dist-info (or egg-info) directory, and nil for the metadata from PyPI.
+	dir *Path
+}
+
+// findSitePackages returns the site-packages directories given by the context (`--site-packages` option), $PURPLECAT_SITE_PACKAGES,
+// the virtual environment in $VIRTUAL_ENV, and the virtual environments in the project directory (.venv and venv).
+func findSitePackages(dir *Path, context *Context) []string {
+	dirs := append([]string{}, context.SitePackages...)
+	if value := os.Getenv(SitePackagesEnvName); value != "" {
+		dirs = append(dirs, filepath.SplitList(value)...)
+	}
+	venvs := []string{}
+	if venv := os.Getenv("VIRTUAL_ENV"); venv != "" {
+		venvs = append(venvs, venv)
+	}
+	venvs = append(venvs, filepath.Join(dir.Path, ".venv"), filepath.Join(dir.Path, "venv"))
+	for _, venv := range venvs {
+		patterns := []string{filepath.Join(venv, "lib", "python*", "site-packages"), filepath.Join(venv, "Lib", "site-packages")}
+		for _, pattern := range patterns {
+			matches, _ := filepath.Glob(pattern)
+			dirs = append(dirs, matches...)
+		}
+	}
+	return dirs
+}
+
+// readInstalledPythonDistributions reads the metadata of the distributions installed in the given site-packages directories,
+// and returns them keyed by the normalized names. The distribution found first wins.
+func readInstalledPythonDistributions(sitePackages []string, context *Context) map[string]*pythonMetadata {
+	installed := map[string]*pythonMetadata{}
+	for _, sitePackage := range sitePackages {
+		for _, pattern := range []string{"*.dist-info/METADATA", "*.egg-info/PKG-INFO"} {
+			matches, _ := filepath.Glob(filepath.Join(sitePackage, pattern))
+			for _, match := range matches {
+				metadata, err := readPythonMetadata(NewPath(match), context)
+				if err != nil || metadata.name == "" {
+					logger.Debugf("%s: cannot read metadata", match)
+					continue
+				}
+				key := normalizePythonName(metadata.name)
+				if _, ok := installed[key]; !ok {
+					installed[key] = metadata
+				}
+			}
+		}
+	}
+	return installed
+}
+
+func readPythonMetadata(path *Path, context *Context) (*pythonMetadata, error) {
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	metadata := parsePythonMetadata(content)
+	metadata.dir = path.Dir()
+	return metadata, nil
+}
+
+// parsePythonMetadata parses the headers of the core metadata, which is the format of RFC 822.
+// The headers end with the blank line, and the indented lines continue the previous header.
+func parsePythonMetadata(content string) *pythonMetadata {
+	metadata := &pythonMetadata{classifiers: []string{}, requiresDist: []string{}}
+	key := ""
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if strings.EqualFold(key, "License") {
+				metadata.license = metadata.license + "\n" + strings.TrimSpace(line)
+			}
+			continue
+		}
+		index := strings.Index(line, ":")
+		if index < 0 {
+			continue
+		}
+		key = line[:index]
+		value := strings.TrimSpace(line[index+1:])
+		switch strings.ToLower(key) {
+		case "name":
+			metadata.name = value
+		case "version":
+			metadata.version = value
+		case "license":
+			metadata.license = value
+		case "license-expression":
+			metadata.licenseExpression = value
+		case "classifier":
+			metadata.classifiers = append(metadata.classifiers, value)
+		case "requires-dist":
+			metadata.requiresDist = append(metadata.requiresDist, value)
+		}
+	}
+	return metadata
+}
+
+// requirements returns the requirements in Requires-Dist, except the ones required only by the extras.
+func (metadata *pythonMetadata) requirements() []*pythonRequirement {
+	requires := []*pythonRequirement{}
+	for _, item := range metadata.requiresDist {
+		if requirement, ok := parsePythonRequirement(item); ok && !requirement.isExtra() {
+			requires = append(requires, requirement)
+		}
+	}
+	return requires
+}
+
+// licenseList returns the licenses from License-Expression, the license classifiers, and License field in this order.
+// If the metadata has none of them, the license files in the dist-info directory are classified.
+func (metadata *pythonMetadata) licenseList(context *Context) Licenses {
+	if metadata.licenseExpression != "" {
+		return parseSpdxExpression(metadata.licenseExpression)
+	}
+	licenses := Licenses{}
+	for _, classifier := range metadata.classifiers {
+		if license, ok := pythonClassifierLicense(classifier); ok {
+			licenses = appendLicenseIfAbsent(licenses, license)
+		}
+	}
+	if len(licenses) > 0 {
+		return licenses
+	}
+	if text := strings.TrimSpace(metadata.license); text != "" && !strings.EqualFold(text, "UNKNOWN") {
+		return Licenses{pythonLicenseOf(text)}
+	}
+	if metadata.dir != nil {
+		if licenses := findLicensesInDir(metadata.dir, context); len(licenses) > 0 {
+			return licenses
+		}
+		return findLicensesInDir(metadata.dir.Join("licenses"), context)
+	}
+	return licenses
+}
+
+// pythonClassifierLicenses maps the last part of the license classifiers of PyPI to the SPDX IDs.
+var pythonClassifierLicenses = map[string]string{
+	"Apache Software License":                                 "Apache-2.0",
+	"MIT License":                                             "MIT",
+	"MIT No Attribution License (MIT-0)":                      "MIT-0",
+	"ISC License (ISCL)":                                      "ISC",
+	"Mozilla Public License 2.0 (MPL 2.0)":                    "MPL-2.0",
+	"GNU General Public License v2 (GPLv2)":                   "GPL-2.0-only",
+	"GNU General Public License v3 (GPLv3)":                   "GPL-3.0-only",
+	"GNU Lesser General Public License v2 (LGPLv2)":           "LGPL-2.0-only",
+	"GNU Lesser General Public License v3 (LGPLv3)":           "LGPL-3.0-only",
+	"GNU Affero General Public License v3":                    "AGPL-3.0-only",
+	"Eclipse Public License 1.0 (EPL-1.0)":                    "EPL-1.0",
+	"Eclipse Public License 2.0 (EPL-2.0)":                    "EPL-2.0",
+	"Python Software Foundation License":                      "PSF-2.0",
+	"The Unlicense (Unlicense)":                               "Unlicense",
+	"CC0 1.0 Universal (CC0 1.0) Public Domain Dedication":    "CC0-1.0",
+	"Boost Software License 1.0 (BSL-1.0)":                    "BSL-1.0",
+	"GNU Lesser General Public License v2 or later (LGPLv2+)": "LGPL-2.0-or-later",
+	"GNU General Public License v2 or later (GPLv2+)":         "GPL-2.0-or-later",
+	"GNU General Public License v3 or later (GPLv3+)":         "GPL-3.0-or-later",
+	"GNU Lesser General Public License v3 or later (LGPLv3+)": "LGPL-3.0-or-later",
+	"GNU Affero General Public License v3 or later (AGPLv3+)": "AGPL-3.0-or-later",
+	"Universal Permissive License (UPL)":                      "UPL-1.0",
+}
+
+// pythonClassifierLicense returns the license of the given classifier (e.g., `License :: OSI Approved :: MIT License`).
+// The classifiers unknown to the SPDX IDs are kept as the license names without the SPDX IDs.
+func pythonClassifierLicense(classifier string) (*License, bool) {
+	items := strings.Split(classifier, "::")
+	if len(items) < 2 || strings.TrimSpace(items[0]) != "License" {
+		return nil, false
+	}
+	name := strings.TrimSpace(items[len(items)-1])
+	if name == "OSI Approved" || name == "DFSG approved" || name == "Other/Proprietary License" || name == "Freely Distributable" {
+		return nil, false
+	}
+	if spdxID, ok := pythonClassifierLicenses[name]; ok {
+		return newLicenseBySpdxID(spdxID), true
+	}
+	return &License{Name: name}, true
+}
+
+// pythonLicenseOf returns the license from the value of License field in the metadata, which is the SPDX ID,
+// the name of the license, or the full text of the license.
+func pythonLicenseOf(text string) *License {
+	text = strings.TrimSpace(text)
+	for _, rule := range licenseRules {
+		if strings.EqualFold(rule.spdxID, text) || strings.EqualFold(rule.name, text) {
+			return newSpdxLicense(rule.spdxID, rule.name)
+		}
+	}
+	if spdxID, ok := pythonClassifierLicenses[text]; ok {
+		return newLicenseBySpdxID(spdxID)
+	}
+	if license, ok := classifyLicense(text); ok {
+		return license
+	}
+	if strings.Contains(text, "\n") {
+		return UnknownLicense
+	}
+	return &License{Name: text}
+}
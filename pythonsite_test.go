@@ -0,0 +1,71 @@
+package purplecat
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFindSitePackages(t *testing.T) {
+	defer setEnv(map[string]string{SitePackagesEnvName: "testdata/pythonsitepackages"})()
+	context := NewContext(true, "json", 1)
+	context.SitePackages = []string{"testdata/pythonoptionsitepackages"}
+	dirs := findSitePackages(NewPath("testdata/pyprojectproject"), context)
+	wontDirs := []string{"testdata/pythonoptionsitepackages", "testdata/pythonsitepackages", filepath.Join("testdata", "pyprojectproject", ".venv", "lib", "python3.9", "site-packages")}
+	if len(dirs) != len(wontDirs) {
+		t.Errorf("site-packages count did not match, wont %v, got %v", wontDirs, dirs)
+		return
+	}
+	for i, wont := range wontDirs {
+		if dirs[i] != wont {
+			t.Errorf("site-packages[%d] did not match, wont %s, got %s", i, wont, dirs[i])
+		}
+	}
+}
+
+func TestParsePythonMetadata(t *testing.T) {
+	metadata := parsePythonMetadata(`Metadata-Version: 2.1
+Name: example
+Version: 1.0.0
+License: Copyright (c) Example
+        Permission is hereby granted, free of charge, to any person obtaining a copy
+Classifier: License :: OSI Approved :: MIT License
+Requires-Dist: requests (>=2.0)
+Requires-Dist: pytest ; extra == "test"
+
+Requires-Dist: not-a-header
+`)
+	if metadata.name != "example" || metadata.version != "1.0.0" {
+		t.Errorf("name and version did not match, wont example 1.0.0, got %s %s", metadata.name, metadata.version)
+	}
+	if len(metadata.requiresDist) != 2 || len(metadata.requirements()) != 1 {
+		t.Errorf("requirements did not match, wont 2 (1 without extras), got %v", metadata.requiresDist)
+	}
+	if pythonLicenseOf(metadata.license).SpdxID != "MIT" {
+		t.Errorf("license did not match, wont MIT, got %s", pythonLicenseOf(metadata.license).SpdxID)
+	}
+}
+
+func TestPythonMetadataLicenseList(t *testing.T) {
+	testdata := []struct {
+		metadata    *pythonMetadata
+		wontSpdxIDs []string
+	}{
+		{&pythonMetadata{licenseExpression: "MIT OR Apache-2.0", license: "BSD"}, []string{"MIT", "Apache-2.0"}},
+		{&pythonMetadata{classifiers: []string{"License :: OSI Approved", "License :: OSI Approved :: Apache Software License"}}, []string{"Apache-2.0"}},
+		{&pythonMetadata{classifiers: []string{"License :: OSI Approved :: BSD License"}}, []string{""}},
+		{&pythonMetadata{license: "Apache License 2.0"}, []string{"Apache-2.0"}},
+		{&pythonMetadata{license: "UNKNOWN"}, []string{}},
+	}
+	for _, td := range testdata {
+		licenses := td.metadata.licenseList(NewContext(true, "json", 1))
+		if len(licenses) != len(td.wontSpdxIDs) {
+			t.Errorf("%v: license count did not match, wont %d, got %d", td.metadata, len(td.wontSpdxIDs), len(licenses))
+			continue
+		}
+		for i, license := range licenses {
+			if license.SpdxID != td.wontSpdxIDs[i] {
+				t.Errorf("%v: license[%d] did not match, wont %s, got %s", td.metadata, i, td.wontSpdxIDs[i], license.SpdxID)
+			}
+		}
+	}
+}
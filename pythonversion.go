@@ -0,0 +1,265 @@
+package purplecat
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pythonVersion represents the version of the Python distribution defined in PEP 440, e.g., `1!2.0.1rc1.post2.dev3+local`.
+type pythonVersion struct {
+	epoch   int
+	release []int
+	// preKind is the kind of the pre-release, `a`, `b`, or `rc`, and empty for the final releases.
+	preKind   string
+	preNumber int
+	post      int
+	dev       int
+}
+
+var pythonVersionPattern = regexp.MustCompile(`(?i)^v?(?:(\d+)!)?(\d+(?:\.\d+)*)(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d+)?)?(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?(?:[-_.]?(dev)[-_.]?(\d+)?)?(?:\+[a-z0-9]+(?:[-_.][a-z0-9]+)*)?$`)
+
+func parsePythonVersion(value string) (*pythonVersion, bool) {
+	match := pythonVersionPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return nil, false
+	}
+	version := &pythonVersion{epoch: atoiOr(match[1], 0), release: []int{}, post: -1, dev: -1}
+	for _, item := range strings.Split(match[2], ".") {
+		version.release = append(version.release, atoiOr(item, 0))
+	}
+	if match[3] != "" {
+		version.preKind = normalizePythonPreKind(strings.ToLower(match[3]))
+		version.preNumber = atoiOr(match[4], 0)
+	}
+	if match[5] != "" {
+		version.post = atoiOr(match[5], 0)
+	} else if match[6] != "" {
+		version.post = atoiOr(match[7], 0)
+	}
+	if match[8] != "" {
+		version.dev = atoiOr(match[9], 0)
+	}
+	return version, true
+}
+
+func atoiOr(value string, defaultValue int) int {
+	if number, err := strconv.Atoi(value); err == nil {
+		return number
+	}
+	return defaultValue
+}
+
+func normalizePythonPreKind(kind string) string {
+	switch kind {
+	case "alpha":
+		return "a"
+	case "beta":
+		return "b"
+	case "c", "pre", "preview":
+		return "rc"
+	}
+	return kind
+}
+
+func (version *pythonVersion) isPrerelease() bool {
+	return version.preKind != "" || version.dev >= 0
+}
+
+// preKey returns the order of the pre-release part; the developmental releases of the final release are the earliest, and
+// the final releases are the latest.
+func (version *pythonVersion) preKey() int {
+	switch {
+	case version.preKind == "" && version.post < 0 && version.dev >= 0:
+		return -1
+	case version.preKind == "a":
+		return 0
+	case version.preKind == "b":
+		return 1
+	case version.preKind == "rc":
+		return 2
+	}
+	return 3
+}
+
+func (version *pythonVersion) releaseAt(index int) int {
+	if index < len(version.release) {
+		return version.release[index]
+	}
+	return 0
+}
+
+// compare compares the receiver and the given version, and returns -1, 0, or 1 in the ordering of PEP 440.
+func (version *pythonVersion) compare(other *pythonVersion) int {
+	if result := compareInt(version.epoch, other.epoch); result != 0 {
+		return result
+	}
+	length := len(version.release)
+	if len(other.release) > length {
+		length = len(other.release)
+	}
+	for i := 0; i < length; i++ {
+		if result := compareInt(version.releaseAt(i), other.releaseAt(i)); result != 0 {
+			return result
+		}
+	}
+	if result := compareInt(version.preKey(), other.preKey()); result != 0 {
+		return result
+	}
+	if result := compareInt(version.preNumber, other.preNumber); result != 0 {
+		return result
+	}
+	if result := compareInt(version.post, other.post); result != 0 {
+		return result
+	}
+	devKey1, devKey2 := version.dev, other.dev
+	if devKey1 < 0 {
+		devKey1 = int(^uint(0) >> 1)
+	}
+	if devKey2 < 0 {
+		devKey2 = int(^uint(0) >> 1)
+	}
+	return compareInt(devKey1, devKey2)
+}
+
+func comparePythonVersion(v1, v2 string) int {
+	version1, ok1 := parsePythonVersion(v1)
+	version2, ok2 := parsePythonVersion(v2)
+	if !ok1 || !ok2 {
+		return strings.Compare(v1, v2)
+	}
+	return version1.compare(version2)
+}
+
+// pythonClause is the one of the comma separated clauses in the version specifier, e.g., `>=1.0`.
+type pythonClause struct {
+	operator string
+	version  string
+}
+
+var pythonClausePattern = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>|\^|~)?\s*(.*)$`)
+
+// parsePythonSpecifier parses the version specifier of PEP 440 (e.g., `>=1.0,<2.0`), and
+// the caret and tilde requirements of Poetry (e.g., `^1.2`).
+func parsePythonSpecifier(specifier string) []*pythonClause {
+	clauses := []*pythonClause{}
+	for _, item := range strings.Split(specifier, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || item == "*" {
+			continue
+		}
+		match := pythonClausePattern.FindStringSubmatch(item)
+		operator := match[1]
+		if operator == "" {
+			operator = "=="
+		}
+		clauses = append(clauses, &pythonClause{operator: operator, version: strings.TrimSpace(match[2])})
+	}
+	return clauses
+}
+
+// satisfiesPythonSpecifier reports whether the given version satisfies all clauses of the given specifier.
+func satisfiesPythonSpecifier(version, specifier string) bool {
+	target, ok := parsePythonVersion(version)
+	if !ok {
+		return false
+	}
+	for _, clause := range parsePythonSpecifier(specifier) {
+		if !clause.match(target) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPythonPrerelease reports whether the given specifier mentions the pre-releases explicitly.
+func allowsPythonPrerelease(specifier string) bool {
+	for _, clause := range parsePythonSpecifier(specifier) {
+		if version, ok := parsePythonVersion(strings.TrimSuffix(clause.version, ".*")); ok && version.isPrerelease() {
+			return true
+		}
+	}
+	return false
+}
+
+func (clause *pythonClause) match(target *pythonVersion) bool {
+	if clause.operator == "===" {
+		return true
+	}
+	if strings.HasSuffix(clause.version, ".*") {
+		prefix, ok := parsePythonVersion(strings.TrimSuffix(clause.version, ".*"))
+		if !ok {
+			return false
+		}
+		matched := matchPythonReleasePrefix(target, prefix.release)
+		return matched == (clause.operator == "==")
+	}
+	version, ok := parsePythonVersion(clause.version)
+	if !ok {
+		return false
+	}
+	result := target.compare(version)
+	switch clause.operator {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<=":
+		return result <= 0
+	case ">=":
+		return result >= 0
+	case "<":
+		// `<V` excludes the pre-releases of V, unless V itself is the pre-release.
+		return result < 0 && !(target.isPrerelease() && !version.isPrerelease() && target.sameRelease(version))
+	case ">":
+		// `>V` excludes the post-releases of V, unless V itself is the post-release.
+		return result > 0 && !(target.post >= 0 && version.post < 0 && target.sameRelease(version))
+	case "~=":
+		return result >= 0 && len(version.release) >= 2 && matchPythonReleasePrefix(target, version.release[:len(version.release)-1])
+	case "^":
+		return result >= 0 && target.compare(bumpPythonRelease(version.release, caretPythonIndex(version.release))) < 0
+	case "~":
+		index := len(version.release) - 1
+		if index > 1 {
+			index = 1
+		}
+		return result >= 0 && target.compare(bumpPythonRelease(version.release, index)) < 0
+	}
+	return false
+}
+
+// sameRelease reports whether the receiver and the given version have the same epoch and release segment.
+func (version *pythonVersion) sameRelease(other *pythonVersion) bool {
+	if version.epoch != other.epoch {
+		return false
+	}
+	return matchPythonReleasePrefix(version, other.release) && matchPythonReleasePrefix(other, version.release)
+}
+
+func matchPythonReleasePrefix(target *pythonVersion, prefix []int) bool {
+	for i, number := range prefix {
+		if target.releaseAt(i) != number {
+			return false
+		}
+	}
+	return true
+}
+
+// caretPythonIndex returns the index of the left-most non-zero component in the given release.
+func caretPythonIndex(release []int) int {
+	for i, number := range release {
+		if number != 0 || i == len(release)-1 {
+			return i
+		}
+	}
+	return 0
+}
+
+// bumpPythonRelease returns the version incremented the component at the given index, e.g., `1.3.dev0` for the index 1 of `1.2.3`.
+// The returned version is the developmental release for excluding the pre-releases of the bumped version.
+func bumpPythonRelease(release []int, index int) *pythonVersion {
+	bumped := make([]int, index+1)
+	copy(bumped, release)
+	bumped[index]++
+	return &pythonVersion{release: bumped, post: -1, dev: 0}
+}
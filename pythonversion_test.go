@@ -0,0 +1,78 @@
+package purplecat
+
+import "testing"
+
+func TestComparePythonVersion(t *testing.T) {
+	testdata := []struct {
+		v1   string
+		v2   string
+		wont int
+	}{
+		{"1.0", "1.0.0", 0},
+		{"1.0.post1", "1.0", 1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0a1", "1.0b1", -1},
+		{"1.0.dev1", "1.0a1", -1},
+		{"1.0a1.dev1", "1.0a1", -1},
+		{"1!0.1", "2.0", 1},
+		{"2.0", "10.0", -1},
+		{"1.0-post2", "1.0.post1", 1},
+		{"1.0+local", "1.0", 0},
+	}
+	for _, td := range testdata {
+		if got := comparePythonVersion(td.v1, td.v2); got != td.wont {
+			t.Errorf("comparePythonVersion(%s, %s) did not match, wont %d, got %d", td.v1, td.v2, td.wont, got)
+		}
+	}
+}
+
+func TestSatisfiesPythonSpecifier(t *testing.T) {
+	testdata := []struct {
+		version   string
+		specifier string
+		wont      bool
+	}{
+		{"2.25.1", "", true},
+		{"2.25.1", ">=2.20", true},
+		{"2.25.1", ">=2.5,<3", true},
+		{"3.0", ">=2.5,<3", false},
+		{"2.0.1", "~=2.0", true},
+		{"3.0.0", "~=2.0", false},
+		{"2.2.0", "~=2.1.0", false},
+		{"1.15.0", "==1.15.*", true},
+		{"1.16.0", "!=1.16.*", false},
+		{"2.28.0", "^2.25", true},
+		{"3.0.0", "^2.25", false},
+		{"0.2.5", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"3.0.0rc1", "<3.0.0", false},
+		{"1.0.post1", ">1.0", false},
+		{"1.1", ">1.0", true},
+		{"1.0", "1.0", true},
+		{"invalid", ">=1.0", false},
+	}
+	for _, td := range testdata {
+		if got := satisfiesPythonSpecifier(td.version, td.specifier); got != td.wont {
+			t.Errorf(`satisfiesPythonSpecifier("%s", "%s") did not match, wont %v, got %v`, td.version, td.specifier, td.wont, got)
+		}
+	}
+}
+
+func TestAllowsPythonPrerelease(t *testing.T) {
+	testdata := []struct {
+		specifier string
+		wont      bool
+	}{
+		{">=2.0", false},
+		{">=2.1.0rc1", true},
+		{"==3.0.*", false},
+		{"", false},
+	}
+	for _, td := range testdata {
+		if got := allowsPythonPrerelease(td.specifier); got != td.wont {
+			t.Errorf(`allowsPythonPrerelease("%s") did not match, wont %v, got %v`, td.specifier, td.wont, got)
+		}
+	}
+}
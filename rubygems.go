@@ -0,0 +1,137 @@
+package purplecat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/tamadalab/purplecat/logger"
+)
+
+// defaultRubyGemsURL is the url of RubyGems.org.
+const defaultRubyGemsURL = "https://rubygems.org"
+
+// RubyGemsEnvName is the environment name for the url of RubyGems-compatible server,
+// which overrides the remotes written in Gemfile.lock.
+const RubyGemsEnvName = "PURPLECAT_RUBYGEMS_URL"
+
+var (
+	gemspecLicensePattern = regexp.MustCompile(`\.licenses?\s*=\s*(\[[^\]]*\]|"[^"]*"|'[^']*')`)
+	gemspecQuotedPattern  = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+)
+
+// rubyGemsVersion represents the response of `$remote/api/v2/rubygems/<name>/versions/<version>.json`.
+type rubyGemsVersion struct {
+	Licenses []string `json:"licenses"`
+}
+
+// findGemDirs returns the gem directories (the directories containing specifications and gems) in $GEM_HOME, $GEM_PATH,
+// the bundle path of the project (vendor/bundle, or $BUNDLE_PATH), and the user gem directory ($HOME/.gem).
+func findGemDirs(dir *Path) []string {
+	dirs := []string{}
+	if value := os.Getenv("GEM_HOME"); value != "" {
+		dirs = append(dirs, value)
+	}
+	if value := os.Getenv("GEM_PATH"); value != "" {
+		dirs = append(dirs, filepath.SplitList(value)...)
+	}
+	patterns := []string{filepath.Join(dir.Path, "vendor", "bundle", "ruby", "*")}
+	if value := os.Getenv("BUNDLE_PATH"); value != "" {
+		patterns = append(patterns, filepath.Join(value, "ruby", "*"))
+	}
+	if home, err := homedir.Dir(); err == nil {
+		patterns = append(patterns, filepath.Join(home, ".gem", "ruby", "*"))
+	}
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		dirs = append(dirs, matches...)
+	}
+	return dirs
+}
+
+// parseGemspecLicenses returns the licenses declared in the gemspec (`spec.license = "MIT"`, or `s.licenses = ["MIT".freeze]`).
+func parseGemspecLicenses(content string) (Licenses, bool) {
+	licenses := Licenses{}
+	found := false
+	for _, match := range gemspecLicensePattern.FindAllStringSubmatch(content, -1) {
+		found = true
+		for _, quoted := range gemspecQuotedPattern.FindAllStringSubmatch(match[1], -1) {
+			for _, license := range parseSpdxExpression(quoted[1] + quoted[2]) {
+				licenses = appendLicenseIfAbsent(licenses, license)
+			}
+		}
+	}
+	return licenses, found && len(licenses) > 0
+}
+
+// findLicensesInGemspecs reads the licenses from the gemspecs matched to the given pattern in the given directory.
+func findLicensesInGemspecs(dir *Path, pattern string, context *Context) (Licenses, bool) {
+	matches, _ := filepath.Glob(filepath.Join(dir.Path, pattern))
+	for _, match := range matches {
+		content, err := readText(NewPath(match), context)
+		if err != nil {
+			continue
+		}
+		if licenses, ok := parseGemspecLicenses(content); ok {
+			return licenses, true
+		}
+	}
+	return nil, false
+}
+
+// findInstalledGemLicenses finds the licenses of the given gem from specifications/<name>-<version>[-<platform>].gemspec,
+// or the license files in gems/<name>-<version>[-<platform>] in the gem directories.
+func findInstalledGemLicenses(spec *gemSpec, gemDirs []string, context *Context) (Licenses, bool) {
+	fullName := spec.name + "-" + spec.version
+	if spec.platform != "" {
+		fullName = fullName + "-" + spec.platform
+	}
+	for _, gemDir := range gemDirs {
+		if licenses, ok := findLicensesInGemspecs(NewPath(filepath.Join(gemDir, "specifications")), fullName+".gemspec", context); ok {
+			return licenses, true
+		}
+		dir := NewPath(filepath.Join(gemDir, "gems", fullName))
+		if licenses := findLicensesInDir(dir, context); len(licenses) > 0 {
+			return licenses, true
+		}
+	}
+	return nil, false
+}
+
+func rubyGemsURL(source *gemSource) string {
+	if value := os.Getenv(RubyGemsEnvName); value != "" {
+		return strings.TrimSuffix(value, "/")
+	}
+	if source.remote != "" {
+		return strings.TrimSuffix(source.remote, "/")
+	}
+	return defaultRubyGemsURL
+}
+
+// findGemLicensesViaAPI finds the licenses of the given gem from the RubyGems API of the remote of the gem.
+func findGemLicensesViaAPI(spec *gemSpec, context *Context) (Licenses, error) {
+	if !context.Allow(NetworkAccessFlag) {
+		return nil, fmt.Errorf("%s: network access denied", spec.Name())
+	}
+	logger.Infof("findGemLicensesViaAPI(%s)", spec.Name())
+	path := NewPath(fmt.Sprintf("%s/api/v2/rubygems/%s/versions/%s.json", rubyGemsURL(spec.source), spec.name, spec.version))
+	content, err := readText(path, context)
+	if err != nil {
+		return nil, err
+	}
+	version := &rubyGemsVersion{}
+	if err := json.Unmarshal([]byte(content), version); err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	licenses := Licenses{}
+	for _, item := range version.Licenses {
+		for _, license := range parseSpdxExpression(item) {
+			licenses = appendLicenseIfAbsent(licenses, license)
+		}
+	}
+	return licenses, nil
+}
@@ -0,0 +1,36 @@
+package purplecat
+
+import "testing"
+
+func TestParseGemspecLicenses(t *testing.T) {
+	testdata := []struct {
+		content     string
+		successFlag bool
+		wontSpdxIDs []string
+	}{
+		{`spec.license = "MIT"`, true, []string{"MIT"}},
+		{`s.licenses = ["MIT".freeze, "Ruby".freeze]`, true, []string{"MIT", "Ruby"}},
+		{`spec.licenses = ['BSD-2-Clause']`, true, []string{"BSD-2-Clause"}},
+		{`spec.licenses = []`, false, []string{}},
+		{`spec.name = "example"`, false, []string{}},
+	}
+	for _, td := range testdata {
+		licenses, ok := parseGemspecLicenses(td.content)
+		if ok != td.successFlag {
+			t.Errorf(`parseGemspecLicenses("%s") wont success %v, got %v`, td.content, td.successFlag, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if len(licenses) != len(td.wontSpdxIDs) {
+			t.Errorf(`parseGemspecLicenses("%s") license count did not match, wont %d, got %d`, td.content, len(td.wontSpdxIDs), len(licenses))
+			continue
+		}
+		for i, license := range licenses {
+			if license.SpdxID != td.wontSpdxIDs[i] {
+				t.Errorf(`parseGemspecLicenses("%s") license[%d] did not match, wont %s, got %s`, td.content, i, td.wontSpdxIDs[i], license.SpdxID)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+//go:build ignore
+// +build ignore
+
+// spdxtemplates_gen.go generates spdxtemplates.go from the license templates in assets/spdx.
+// Run `go generate` after updating the templates.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const templateDir = "assets/spdx"
+
+func main() {
+	if err := generate("spdxtemplates.go"); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func generate(dest string) error {
+	index, err := os.Open(filepath.Join(templateDir, "index.tsv"))
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+	buffer := bytes.NewBuffer([]byte{})
+	buffer.WriteString(`// Code generated by spdxtemplates_gen.go from assets/spdx; DO NOT EDIT.
+
+package purplecat
+
+// spdxLicenseTemplateCorpus is the bundled corpus of the SPDX license templates for classifying the license texts.
+// See assets/spdx/README.md for the sources and the attribution of the templates.
+var spdxLicenseTemplateCorpus = []*spdxLicenseTemplate{
+`)
+	scanner := bufio.NewScanner(index)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		items := strings.Split(scanner.Text(), "\t")
+		if len(items) != 3 {
+			return fmt.Errorf("%s/index.tsv: line %d: malformed entry", templateDir, lineNumber)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(templateDir, items[0]))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buffer, "\t{%s, %s, %s},\n", strconv.Quote(items[1]), strconv.Quote(items[2]), quote(string(data)))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	buffer.WriteString("}\n")
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dest, source, 0644)
+}
+
+// quote returns the raw string literal of the given text for the readability of the generated code,
+// or the interpreted string literal if the text cannot be written in the raw string literal.
+func quote(text string) string {
+	if strings.ContainsAny(text, "`\r") {
+		return strconv.Quote(text)
+	}
+	return "`" + text + "`"
+}
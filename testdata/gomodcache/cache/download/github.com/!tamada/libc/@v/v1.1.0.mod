@@ -0,0 +1 @@
+module github.com/Tamada/libc
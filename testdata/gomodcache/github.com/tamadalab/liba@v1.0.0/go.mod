@@ -0,0 +1,8 @@
+module github.com/tamadalab/liba
+
+go 1.15
+
+require (
+	github.com/Tamada/libc v1.1.0
+	github.com/tamadalab/libb v0.2.0
+)
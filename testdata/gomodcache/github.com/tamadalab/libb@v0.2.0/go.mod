@@ -0,0 +1,3 @@
+module github.com/tamadalab/libb
+
+go 1.15
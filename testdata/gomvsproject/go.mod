@@ -0,0 +1,8 @@
+module github.com/tamadalab/mvsproject
+
+go 1.15
+
+require (
+	github.com/tamadalab/liba v1.0.0
+	github.com/tamadalab/libb v0.1.0
+)
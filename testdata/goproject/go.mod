@@ -0,0 +1,11 @@
+module github.com/tamadalab/goproject4test
+
+go 1.15
+
+require (
+	github.com/asaskevich/govalidator v0.0.0-20200907205600-7a23bdc65eef
+	github.com/mitchellh/go-homedir v1.1.0
+	golang.org/x/text v0.3.3 // indirect
+)
+
+require github.com/spf13/pflag v1.0.5
@@ -0,0 +1,5 @@
+module github.com/tamadalab/libd
+
+go 1.15
+
+require github.com/tamadalab/libe v0.1.0
@@ -0,0 +1,3 @@
+module github.com/tamadalab/libe
+
+go 1.15
@@ -0,0 +1,5 @@
+module github.com/tamadalab/sumproject
+
+go 1.15
+
+require github.com/tamadalab/missing v1.0.0
@@ -0,0 +1,11 @@
+module github.com/tamadalab/vendorproject
+
+go 1.15
+
+require (
+	github.com/tamadalab/liba v1.0.0
+	github.com/tamadalab/notbuilt v1.0.0
+	github.com/tamadalab/old v0.1.0
+)
+
+replace github.com/tamadalab/old => ../goworkspace/forked
@@ -0,0 +1,16 @@
+module github.com/tamadalab/app
+
+go 1.18
+
+require (
+	github.com/tamadalab/forked v1.0.0
+	github.com/tamadalab/lib v0.0.0
+	github.com/tamadalab/liba v1.0.0
+	github.com/tamadalab/old v0.1.0
+)
+
+replace github.com/tamadalab/old => github.com/Tamada/libc v1.1.0
+
+replace github.com/tamadalab/forked v1.0.0 => ../forked
+
+exclude github.com/tamadalab/libb v0.2.0
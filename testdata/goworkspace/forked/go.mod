@@ -0,0 +1,3 @@
+module github.com/tamadalab/forked
+
+go 1.18
@@ -0,0 +1,3 @@
+module github.com/tamadalab/lib
+
+go 1.18
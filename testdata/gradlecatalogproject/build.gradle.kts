@@ -0,0 +1,13 @@
+plugins {
+    java
+}
+
+group = "jp.ac.kyoto_su"
+version = "1.0.0"
+
+dependencies {
+    implementation(libs.guava)
+    implementation(libs.commons.lang3)
+    implementation("args4j:args4j:2.+")
+    testImplementation(libs.bundles.testing)
+}
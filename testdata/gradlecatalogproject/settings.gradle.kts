@@ -0,0 +1 @@
+rootProject.name = "catalog4test"
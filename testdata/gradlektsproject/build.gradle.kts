@@ -0,0 +1,14 @@
+plugins {
+    kotlin("jvm") version "1.4.21"
+}
+
+group = "jp.ac.kyoto_su"
+version = "1.0.0"
+
+val hamcrestVersion = "1.3"
+
+dependencies {
+    implementation(kotlin("stdlib", "1.4.21"))
+    implementation("args4j:args4j:2.33")
+    testImplementation(group = "org.hamcrest", name = "hamcrest-all", version = "$hamcrestVersion")
+}
@@ -30,59 +30,212 @@ type xmlWriter struct {
 	Out io.Writer
 }
 
+// devMark is the mark appended to the names of the development dependencies in markdown and csv formats.
+const devMark = " (dev)"
+
+// moduleMark is the mark appended to the names of the modules of the multi-module project in markdown and csv formats.
+const moduleMark = " (module)"
+
+// unreachedMark is the mark appended to the names of the dependencies not reached in the dependency graph in markdown and csv formats.
+const unreachedMark = " (unreached)"
+
+// cyclicMark is the mark appended to the names of the dependencies found in their own ancestors in markdown and csv formats.
+// Such dependencies (e.g., npm packages requiring each other) are written as the leaves to stop the cycles.
+const cyclicMark = " (cyclic)"
+
+// enter adds the given tree into the ancestors of the current path of writing, and returns false if the tree is already in them,
+// which shows the dependency cycle. The returned function removes the tree from the ancestors.
+func enter(tree *Project, ancestors map[string]bool) (func(), bool) {
+	if ancestors[tree.Name()] {
+		return func() {}, false
+	}
+	ancestors[tree.Name()] = true
+	return func() { delete(ancestors, tree.Name()) }, true
+}
+
+// mark returns the mark appended to the name of the dependency in markdown and csv formats, e.g., ` (test)`, ` (optional)`,
+// and ` (omitted for duplicate)`.
+// The compile scope is not marked, since it is the default scope.
+func (attribute *DependencyAttribute) mark() string {
+	if attribute == nil {
+		return ""
+	}
+	items := []string{}
+	if attribute.Scope != "" && attribute.Scope != "compile" {
+		items = append(items, attribute.Scope)
+	}
+	if attribute.Optional {
+		items = append(items, "optional")
+	}
+	if attribute.Internal {
+		items = append(items, "module")
+	}
+	if attribute.Omitted != "" {
+		items = append(items, attribute.Omitted)
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(items, ", ") + ")"
+}
+
+// attributeField is the field of the dependency attribute in json, yaml, and xml formats.
+type attributeField struct {
+	key    string
+	value  string
+	quoted bool
+}
+
+func (attribute *DependencyAttribute) fields() []*attributeField {
+	fields := []*attributeField{}
+	if attribute == nil {
+		return fields
+	}
+	if attribute.Scope != "" {
+		fields = append(fields, &attributeField{key: "scope", value: attribute.Scope, quoted: true})
+	}
+	if attribute.Optional {
+		fields = append(fields, &attributeField{key: "optional", value: "true"})
+	}
+	if attribute.Internal {
+		fields = append(fields, &attributeField{key: "internal", value: "true"})
+	}
+	if attribute.Omitted != "" {
+		fields = append(fields, &attributeField{key: "omitted", value: attribute.Omitted, quoted: true})
+	}
+	return fields
+}
+
 func (mw *markdownWriter) Write(tree *Project) error {
-	return mw.writeImpl(tree, "")
+	return mw.writeImpl(tree, "", "", map[string]bool{})
 }
 
-func (mw *markdownWriter) writeImpl(tree *Project, indent string) error {
-	line := fmt.Sprintf("%s* %s: [%s]\n", indent, tree.Name(), joinLicenseNames(tree))
+func (mw *markdownWriter) writeImpl(tree *Project, indent, mark string, ancestors map[string]bool) error {
+	leave, ok := enter(tree, ancestors)
+	defer leave()
+	if !ok {
+		mark = mark + cyclicMark
+	}
+	line := fmt.Sprintf("%s* %s%s: [%s]\n", indent, tree.Name(), mark, joinLicenseNames(tree))
 	mw.Out.Write([]byte(line))
+	if !ok {
+		return nil
+	}
 	for _, dependency := range tree.Dependencies() {
 		if dependency != nil {
-			mw.writeImpl(dependency, indent+"    ")
+			mw.writeImpl(dependency, indent+"    ", tree.Attribute(dependency.Name()).mark(), ancestors)
 		}
 	}
+	for _, dependency := range tree.OmittedDependencies() {
+		mw.writeImpl(dependency, indent+"    ", tree.Attribute(dependency.Name()).mark(), ancestors)
+	}
+	for _, dependency := range tree.UnreachedDependencies() {
+		mw.writeImpl(dependency, indent+"    ", unreachedMark, ancestors)
+	}
+	for _, dependency := range tree.DevDependencies() {
+		if dependency != nil {
+			mw.writeImpl(dependency, indent+"    ", devMark, ancestors)
+		}
+	}
+	for _, module := range tree.Modules() {
+		mw.writeImpl(module, indent+"    ", moduleMark, ancestors)
+	}
 	return nil
 }
 
 func (cw *csvWriter) Write(tree *Project) error {
 	cw.Out.Write([]byte("project-name,license-name,parent-project-name\n"))
-	cw.writeImpl(tree, "")
+	cw.writeImpl(tree, "", "", map[string]bool{})
 	return nil
 }
 
-func (cw *csvWriter) writeImpl(tree *Project, parent string) {
-	line := fmt.Sprintf("%s,%s,%s\n", tree.Name(), joinLicenseNames(tree), parent)
+func (cw *csvWriter) writeImpl(tree *Project, parent, mark string, ancestors map[string]bool) {
+	leave, ok := enter(tree, ancestors)
+	defer leave()
+	if !ok {
+		mark = mark + cyclicMark
+	}
+	line := fmt.Sprintf("%s%s,%s,%s\n", tree.Name(), mark, joinLicenseNames(tree), parent)
 	cw.Out.Write([]byte(line))
+	if !ok {
+		return
+	}
 	for _, dep := range tree.Dependencies() {
 		if dep != nil {
-			cw.writeImpl(dep, tree.Name())
+			cw.writeImpl(dep, tree.Name(), tree.Attribute(dep.Name()).mark(), ancestors)
 		}
 	}
+	for _, dep := range tree.OmittedDependencies() {
+		cw.writeImpl(dep, tree.Name(), tree.Attribute(dep.Name()).mark(), ancestors)
+	}
+	for _, dep := range tree.UnreachedDependencies() {
+		cw.writeImpl(dep, tree.Name(), unreachedMark, ancestors)
+	}
+	for _, dep := range tree.DevDependencies() {
+		if dep != nil {
+			cw.writeImpl(dep, tree.Name(), devMark, ancestors)
+		}
+	}
+	for _, module := range tree.Modules() {
+		cw.writeImpl(module, tree.Name(), moduleMark, ancestors)
+	}
 }
 
 func (jw *jsonWriter) Write(tree *Project) error {
-	jw.Out.Write([]byte(jw.jsonString(tree)))
+	jw.Out.Write([]byte(jw.jsonString(tree, nil, map[string]bool{})))
 	return nil
 }
 
-func (jw *jsonWriter) dependency(deps Projects) string {
+func (jw *jsonWriter) dependency(key string, tree *Project, deps Projects, ancestors map[string]bool) string {
 	array := []string{}
 	for _, dep := range deps {
 		if dep != nil {
-			array = append(array, jw.jsonString(dep))
+			array = append(array, jw.jsonString(dep, tree.Attribute(dep.Name()), ancestors))
 		}
 	}
-	return fmt.Sprintf(`,"dependencies":[%s]`, strings.Join(array, ","))
+	return fmt.Sprintf(`,"%s":[%s]`, key, strings.Join(array, ","))
 }
 
-func (jw *jsonWriter) jsonString(tree *Project) string {
+func (jw *jsonWriter) attribute(attribute *DependencyAttribute) string {
+	result := ""
+	for _, field := range attribute.fields() {
+		if field.quoted {
+			result = result + fmt.Sprintf(`,"%s":"%s"`, field.key, field.value)
+		} else {
+			result = result + fmt.Sprintf(`,"%s":%s`, field.key, field.value)
+		}
+	}
+	return result
+}
+
+func (jw *jsonWriter) jsonString(tree *Project, attribute *DependencyAttribute, ancestors map[string]bool) string {
+	leave, ok := enter(tree, ancestors)
+	defer leave()
+	if !ok {
+		return fmt.Sprintf(`{"project-name":"%s","license-names":["%s"]%s,"cyclic":true}`, tree.Name(), joinLicenseNames(tree), jw.attribute(attribute))
+	}
 	dependentString := ""
 	deps := tree.Dependencies()
 	if len(deps) > 0 {
-		dependentString = jw.dependency(deps)
+		dependentString = jw.dependency("dependencies", tree, deps, ancestors)
+	}
+	omittedDeps := tree.OmittedDependencies()
+	if len(omittedDeps) > 0 {
+		dependentString = dependentString + jw.dependency("omitted-dependencies", tree, omittedDeps, ancestors)
+	}
+	unreachedDeps := tree.UnreachedDependencies()
+	if len(unreachedDeps) > 0 {
+		dependentString = dependentString + jw.dependency("unreached-dependencies", tree, unreachedDeps, ancestors)
 	}
-	return fmt.Sprintf(`{"project-name":"%s","license-names":["%s"]%s}`, tree.Name(), joinLicenseNames(tree), dependentString)
+	devDeps := tree.DevDependencies()
+	if len(devDeps) > 0 {
+		dependentString = dependentString + jw.dependency("dev-dependencies", tree, devDeps, ancestors)
+	}
+	modules := tree.Modules()
+	if len(modules) > 0 {
+		dependentString = dependentString + jw.dependency("modules", tree, modules, ancestors)
+	}
+	return fmt.Sprintf(`{"project-name":"%s","license-names":["%s"]%s%s}`, tree.Name(), joinLicenseNames(tree), jw.attribute(attribute), dependentString)
 }
 
 func joinLicenseNames(tree *Project) string {
@@ -95,30 +248,62 @@ func joinLicenseNames(tree *Project) string {
 
 func (yw *yamlWriter) Write(tree *Project) error {
 	yw.Out.Write([]byte("---\n"))
-	yw.Out.Write([]byte(yw.string(tree, []string{"", "", ""})))
+	yw.Out.Write([]byte(yw.string(tree, nil, []string{"", "", ""}, map[string]bool{})))
 	yw.Out.Write([]byte("\n"))
 	return nil
 }
 
-func (yw *yamlWriter) deps2string(tree *Project, indents []string) []string {
+func (yw *yamlWriter) deps2string(tree *Project, deps Projects, indents []string, ancestors map[string]bool) []string {
 	array := []string{}
-	for _, dep := range tree.Dependencies() {
+	for _, dep := range deps {
 		if dep != nil {
 			newIndents := []string{indents[0] + "  ", indents[1], indents[2]}
-			array = append(array, yw.string(dep, newIndents))
+			array = append(array, yw.string(dep, tree.Attribute(dep.Name()), newIndents, ancestors))
 		}
 	}
 	return array
 }
 
-func (yw *yamlWriter) string(tree *Project, indents []string) string {
+func (yw *yamlWriter) string(tree *Project, attribute *DependencyAttribute, indents []string, ancestors map[string]bool) string {
 	base := fmt.Sprintf(`%s%sproject-name:%s
 %s%slicense-names:[%s]`, indents[0], indents[1], tree.Name(), indents[0], indents[2], joinLicenseNames(tree))
-	array := yw.deps2string(tree, indents)
+	for _, field := range attribute.fields() {
+		base = fmt.Sprintf("%s\n%s%s%s:%s", base, indents[0], indents[2], field.key, field.value)
+	}
+	leave, ok := enter(tree, ancestors)
+	defer leave()
+	if !ok {
+		return fmt.Sprintf("%s\n%s%scyclic:true", base, indents[0], indents[2])
+	}
+	array := yw.deps2string(tree, tree.Dependencies(), indents, ancestors)
 	if len(array) > 0 {
 		base = fmt.Sprintf(`%s
 %s%sdependencies:
 %s`, base, indents[0], indents[2], strings.Join(array, "\n"))
+	}
+	omittedArray := yw.deps2string(tree, tree.OmittedDependencies(), indents, ancestors)
+	if len(omittedArray) > 0 {
+		base = fmt.Sprintf(`%s
+%s%somitted-dependencies:
+%s`, base, indents[0], indents[2], strings.Join(omittedArray, "\n"))
+	}
+	unreachedArray := yw.deps2string(tree, tree.UnreachedDependencies(), indents, ancestors)
+	if len(unreachedArray) > 0 {
+		base = fmt.Sprintf(`%s
+%s%sunreached-dependencies:
+%s`, base, indents[0], indents[2], strings.Join(unreachedArray, "\n"))
+	}
+	devArray := yw.deps2string(tree, tree.DevDependencies(), indents, ancestors)
+	if len(devArray) > 0 {
+		base = fmt.Sprintf(`%s
+%s%sdev-dependencies:
+%s`, base, indents[0], indents[2], strings.Join(devArray, "\n"))
+	}
+	moduleArray := yw.deps2string(tree, tree.Modules(), indents, ancestors)
+	if len(moduleArray) > 0 {
+		base = fmt.Sprintf(`%s
+%s%smodules:
+%s`, base, indents[0], indents[2], strings.Join(moduleArray, "\n"))
 	}
 	return base
 }
@@ -131,12 +316,12 @@ func (xw *xmlWriter) Write(tree *Project) error {
 	data := fmt.Sprintf(`<?xml version="1.0"?>
 <purplecat>
 %s
-</purplecat>`, xw.string(tree, "  "))
+</purplecat>`, xw.string(tree, nil, "  ", map[string]bool{}))
 	xw.Out.Write([]byte(data))
 	return nil
 }
 
-func (xw *xmlWriter) string(tree *Project, indent string) string {
+func (xw *xmlWriter) string(tree *Project, attribute *DependencyAttribute, indent string, ancestors map[string]bool) string {
 	xmlLicenses := []string{}
 	for _, license := range tree.Licenses() {
 		xmlLicenses = append(xmlLicenses, indent+"  <license-name>"+license.Name+"</license-name>")
@@ -145,19 +330,35 @@ func (xw *xmlWriter) string(tree *Project, indent string) string {
 %s<license-names>
 %s
 %s</license-names>`, indent, tree.Name(), indent, strings.Join(xmlLicenses, "\n"), indent)
+	for _, field := range attribute.fields() {
+		project = fmt.Sprintf("%s\n%s<%s>%s</%s>", project, indent, field.key, field.value, field.key)
+	}
+	leave, ok := enter(tree, ancestors)
+	defer leave()
+	if !ok {
+		return fmt.Sprintf("%s\n%s<cyclic>true</cyclic>", project, indent)
+	}
+	project = xw.dependencies(project, "dependencies", "dependency", tree, tree.Dependencies(), indent, ancestors)
+	project = xw.dependencies(project, "omitted-dependencies", "omitted-dependency", tree, tree.OmittedDependencies(), indent, ancestors)
+	project = xw.dependencies(project, "unreached-dependencies", "unreached-dependency", tree, tree.UnreachedDependencies(), indent, ancestors)
+	project = xw.dependencies(project, "dev-dependencies", "dev-dependency", tree, tree.DevDependencies(), indent, ancestors)
+	return xw.dependencies(project, "modules", "module", tree, tree.Modules(), indent, ancestors)
+}
+
+func (xw *xmlWriter) dependencies(project, tag, itemTag string, tree *Project, deps Projects, indent string, ancestors map[string]bool) string {
 	array := []string{}
-	for _, dep := range tree.Dependencies() {
+	for _, dep := range deps {
 		if dep != nil {
-			array = append(array, fmt.Sprintf(`%s  <dependency>
+			array = append(array, fmt.Sprintf(`%s  <%s>
 %s    
-%s  </dependency>`, indent, xw.string(dep, indent+"    "), indent))
+%s  </%s>`, indent, itemTag, xw.string(dep, tree.Attribute(dep.Name()), indent+"    ", ancestors), indent, itemTag))
 		}
 	}
 	if len(array) > 0 {
 		project = fmt.Sprintf(`%s
-%s<dependencies>
+%s<%s>
 %s
-%s</dependencies>`, project, indent, strings.Join(array, "\n"), indent)
+%s</%s>`, project, indent, tag, strings.Join(array, "\n"), indent, tag)
 	}
 	return project
 }
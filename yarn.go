@@ -0,0 +1,218 @@
+package purplecat
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/tamadalab/purplecat/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// yarnParser is the instance of Parser for parsing yarn.lock of Yarn (both of classic and berry).
+type yarnParser struct {
+	context *Context
+}
+
+// yarnEntry represents the package resolved in yarn.lock, which is shared by the descriptors (e.g., `lodash@^4.17.0`) in the key.
+type yarnEntry struct {
+	name                 string
+	Version              string            `yaml:"version"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// IsTarget returns true if the project located on the given path is yarn project.
+func (yp *yarnParser) IsTarget(path *Path, context *Context) bool {
+	if path.Base() == "yarn.lock" {
+		return path.Exists(context)
+	}
+	return path.Join("yarn.lock").Exists(context)
+}
+
+// Parse parses the given path as yarn.lock and returns the instance of Project.
+// The dependencies of the root project are read from package.json in the same directory.
+func (yp *yarnParser) Parse(path *Path) (*Project, error) {
+	if path.Base() != "yarn.lock" {
+		path = path.Join("yarn.lock")
+	}
+	if yp.context.Depth < 0 {
+		return nil, fmt.Errorf("over the parsing depth limit %d, current: %d", yp.context.Depth, 0)
+	}
+	logger.Infof("parseYarnLock(%s)", path.Path)
+	content, err := readText(path, yp.context)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseYarnLock(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path.Path, err.Error())
+	}
+	tree, root, err := newLockedNpmTree(path.Dir(), yp.context)
+	if err != nil {
+		return nil, err
+	}
+	resolver := &yarnResolver{entries: entries, packages: map[*yarnEntry]*npmPackage{}}
+	root.dependencies = resolver.dependencies(root.requires)
+	return tree.constructProject(root, 0)
+}
+
+// newLockedNpmTree creates the tree whose dependency graph is resolved from the lockfile of yarn or pnpm.
+// The returned root package is built from package.json in the given directory, and its dependencies are not resolved yet.
+func newLockedNpmTree(dir *Path, context *Context) (*npmTree, *npmPackage, error) {
+	manifest, err := readNpmManifest(dir.Join("package.json"), context)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree := newNpmTree(dir, context, func(location string) (*npmManifest, bool) {
+		return nil, false
+	})
+	root := newNpmPackage(manifest, true)
+	if root.name == "" {
+		root.name = dir.Base()
+	}
+	return tree, root, nil
+}
+
+type yarnResolver struct {
+	entries  map[string]*yarnEntry
+	packages map[*yarnEntry]*npmPackage
+}
+
+// find returns the entry of the given descriptor; the descriptors without the protocol are the ones of npm in yarn berry.
+func (resolver *yarnResolver) find(name, spec string) (*yarnEntry, bool) {
+	for _, descriptor := range []string{name + "@" + spec, name + "@npm:" + spec} {
+		if entry, ok := resolver.entries[descriptor]; ok {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+func (resolver *yarnResolver) dependencies(requires map[string]string) []*npmPackage {
+	dependencies := []*npmPackage{}
+	for _, name := range sortedNpmNames(requires) {
+		entry, ok := resolver.find(name, requires[name])
+		if !ok {
+			logger.Debugf("%s@%s: not found in yarn.lock", name, requires[name])
+			continue
+		}
+		dependencies = append(dependencies, resolver.packageOf(entry))
+	}
+	return dependencies
+}
+
+func (resolver *yarnResolver) packageOf(entry *yarnEntry) *npmPackage {
+	if pkg, ok := resolver.packages[entry]; ok {
+		return pkg
+	}
+	// yarn (classic, and berry with nodeLinker: node-modules) hoists the packages into node_modules in the root.
+	pkg := &npmPackage{name: entry.name, version: entry.Version, dir: "node_modules/" + entry.name}
+	resolver.packages[entry] = pkg
+	requires := map[string]string{}
+	for _, deps := range []map[string]string{entry.OptionalDependencies, entry.Dependencies} {
+		for name, spec := range deps {
+			requires[name] = spec
+		}
+	}
+	pkg.dependencies = resolver.dependencies(requires)
+	return pkg
+}
+
+// parseYarnLock parses yarn.lock, and returns the entries keyed by the descriptors.
+// The lockfile of yarn berry is YAML, and the one of yarn classic is the own format similar to YAML.
+func parseYarnLock(content string) (map[string]*yarnEntry, error) {
+	if strings.Contains(content, "\n__metadata:") || strings.HasPrefix(content, "__metadata:") {
+		return parseYarnBerryLock(content)
+	}
+	return parseYarnClassicLock(content)
+}
+
+func parseYarnBerryLock(content string) (map[string]*yarnEntry, error) {
+	document := map[string]*yarnEntry{}
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return nil, err
+	}
+	entries := map[string]*yarnEntry{}
+	for key, entry := range document {
+		if key == "__metadata" || entry == nil {
+			continue
+		}
+		registerYarnEntry(entries, key, entry)
+	}
+	return entries, nil
+}
+
+func registerYarnEntry(entries map[string]*yarnEntry, key string, entry *yarnEntry) {
+	for _, descriptor := range strings.Split(key, ",") {
+		descriptor = strings.Trim(strings.TrimSpace(descriptor), `"`)
+		if descriptor == "" {
+			continue
+		}
+		entry.name = yarnDescriptorName(descriptor)
+		entries[descriptor] = entry
+	}
+}
+
+// yarnDescriptorName returns the package name of the given descriptor, e.g., `@scope/name` of `@scope/name@^1.0.0`.
+func yarnDescriptorName(descriptor string) string {
+	index := strings.Index(descriptor[1:], "@")
+	if index < 0 {
+		return descriptor
+	}
+	return descriptor[:index+1]
+}
+
+// parseYarnClassicLock parses yarn.lock of yarn classic (`# yarn lockfile v1`).
+// The entries are separated by the keys without indentation, and their fields and dependencies are indented.
+func parseYarnClassicLock(content string) (map[string]*yarnEntry, error) {
+	entries := map[string]*yarnEntry{}
+	var entry *yarnEntry
+	var section map[string]string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case indent == 0:
+			entry = &yarnEntry{Dependencies: map[string]string{}, OptionalDependencies: map[string]string{}}
+			registerYarnEntry(entries, strings.TrimSuffix(trimmed, ":"), entry)
+			section = nil
+		case entry == nil:
+			return nil, fmt.Errorf("unexpected indentation: %s", trimmed)
+		case indent <= 2:
+			key, value := splitYarnClassicField(trimmed)
+			section = nil
+			switch key {
+			case "version":
+				entry.Version = value
+			case "dependencies":
+				section = entry.Dependencies
+			case "optionalDependencies":
+				section = entry.OptionalDependencies
+			}
+		case section != nil:
+			key, value := splitYarnClassicField(trimmed)
+			section[key] = value
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// splitYarnClassicField splits the field line (e.g., `"@scope/name" "^1.0.0"`, or `dependencies:`) into the key and the value.
+func splitYarnClassicField(line string) (string, string) {
+	line = strings.TrimSuffix(line, ":")
+	key, value := line, ""
+	if strings.HasPrefix(line, `"`) {
+		if index := strings.Index(line[1:], `"`); index >= 0 {
+			key, value = line[1:index+1], line[index+2:]
+		}
+	} else if index := strings.IndexAny(line, " :"); index >= 0 {
+		key, value = line[:index], line[index+1:]
+	}
+	return key, strings.Trim(strings.TrimSpace(value), `"`)
+}
@@ -0,0 +1,57 @@
+package purplecat
+
+import "testing"
+
+func TestParseYarn(t *testing.T) {
+	testdata := []struct {
+		path         string
+		wontName     string
+		wontLibaDeps []string
+	}{
+		{"testdata/yarnproject", "yarnproject4test@1.0.0", []string{"@scope/libb@2.1.3", "libc@0.1.5"}},
+		{"testdata/yarnberryproject/yarn.lock", "yarnberryproject4test@1.0.0", []string{"libc@0.1.5"}},
+	}
+	for _, td := range testdata {
+		parser := &yarnParser{context: NewContext(true, "json", 2)}
+		tree, err := parser.Parse(NewPath(td.path))
+		if err != nil {
+			t.Errorf("%s: parse failed: %s", td.path, err.Error())
+			continue
+		}
+		validateResolvedTree(t, tree, td.wontName, []string{"MIT"}, []string{"@scope/libb@2.1.3", "liba@1.2.0"})
+		if len(tree.Dependencies()) != 2 {
+			continue
+		}
+		validateResolvedTree(t, tree.Dependencies()[0], "@scope/libb@2.1.3", nil, []string{"libc@0.2.1"})
+		validateResolvedTree(t, tree.Dependencies()[1], "liba@1.2.0", nil, td.wontLibaDeps)
+	}
+}
+
+func TestParseYarnInstalledLicense(t *testing.T) {
+	parser := &yarnParser{context: NewContext(true, "json", 1)}
+	tree, err := parser.Parse(NewPath("testdata/yarnproject"))
+	if err != nil {
+		t.Errorf("testdata/yarnproject: parse failed: %s", err.Error())
+		return
+	}
+	validateResolvedTree(t, tree.Dependencies()[1], "liba@1.2.0", []string{"ISC"}, []string{"@scope/libb@2.1.3", "libc@0.1.5"})
+}
+
+func TestSplitYarnClassicField(t *testing.T) {
+	testdata := []struct {
+		line      string
+		wontKey   string
+		wontValue string
+	}{
+		{`version "1.2.0"`, "version", "1.2.0"},
+		{`dependencies:`, "dependencies", ""},
+		{`"@scope/libb" "~2.1.0"`, "@scope/libb", "~2.1.0"},
+		{`libc "^0.1.0 || ^0.2.0"`, "libc", "^0.1.0 || ^0.2.0"},
+	}
+	for _, td := range testdata {
+		key, value := splitYarnClassicField(td.line)
+		if key != td.wontKey || value != td.wontValue {
+			t.Errorf("splitYarnClassicField(%s) did not match, wont (%s, %s), got (%s, %s)", td.line, td.wontKey, td.wontValue, key, value)
+		}
+	}
+}